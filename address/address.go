@@ -0,0 +1,73 @@
+// Package address encodes Filecoin addresses for public keys.
+package address
+
+import (
+	"encoding/base32"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Network selects the prefix of an address' string form
+type Network byte
+
+const (
+	// Mainnet addresses start with "f"
+	Mainnet Network = 'f'
+
+	// Testnet addresses start with "t"
+	Testnet Network = 't'
+)
+
+// Protocol identifies how an address' payload was derived
+type Protocol byte
+
+const (
+	// SECP256K1 addresses hold the blake2b-160 hash of an uncompressed
+	// secp256k1 public key
+	SECP256K1 Protocol = 1
+
+	// BLS addresses hold a BLS public key
+	BLS Protocol = 3
+)
+
+// PayloadHashLength is the length of a SECP256K1 address payload
+const PayloadHashLength = 20
+
+// ChecksumHashLength is the length of the checksum of an address' string form
+const ChecksumHashLength = 4
+
+// encoding is the lowercase, unpadded base32 used by address strings
+var encoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Bytes returns the binary form of an address: the protocol followed by the
+// payload
+func Bytes(protocol Protocol, payload []byte) []byte {
+	return append([]byte{byte(protocol)}, payload...)
+}
+
+// String returns the string form of an address: the network prefix, the
+// protocol number and the base32 encoding of the payload followed by the
+// checksum of the binary form
+func String(network Network, protocol Protocol, payload []byte) string {
+	checksum := hash(Bytes(protocol, payload), ChecksumHashLength)
+
+	return string([]byte{byte(network), '0' + byte(protocol)}) + encoding.EncodeToString(append(append([]byte{}, payload...), checksum...))
+}
+
+// SECP256K1Payload returns the payload of the address of an uncompressed
+// secp256k1 public key
+func SECP256K1Payload(publicKey []byte) []byte {
+	return hash(publicKey, PayloadHashLength)
+}
+
+func hash(data []byte, size int) []byte {
+	hasher, err := blake2b.New(size, nil)
+	if err != nil {
+		// only fails for sizes outside 1..64 or long keys
+		panic(err)
+	}
+
+	hasher.Write(data) // nolint: errcheck
+
+	return hasher.Sum(nil)
+}
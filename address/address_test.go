@@ -0,0 +1,33 @@
+package address
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from go-address
+func TestSECP256K1Address(t *testing.T) {
+	publicKey := []byte{4, 148, 2, 250, 195, 126, 100, 50, 164, 22, 163, 160, 202, 84,
+		38, 181, 24, 90, 179, 178, 79, 97, 52, 239, 162, 92, 228, 135, 200,
+		45, 46, 78, 19, 191, 69, 37, 17, 224, 210, 36, 84, 33, 248, 97, 59,
+		193, 13, 114, 250, 33, 102, 102, 169, 108, 59, 193, 57, 32, 211,
+		255, 35, 63, 208, 188, 5}
+
+	payload := SECP256K1Payload(publicKey)
+	assert.Len(t, payload, PayloadHashLength)
+	assert.Equal(t, "t15ihq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq", String(Testnet, SECP256K1, payload))
+	assert.Equal(t, "f15ihq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq", String(Mainnet, SECP256K1, payload))
+	assert.Equal(t, append([]byte{1}, payload...), Bytes(SECP256K1, payload))
+}
+
+func TestBLSAddress(t *testing.T) {
+	publicKey := []byte{173, 88, 223, 105, 110, 45, 78, 145, 234, 134, 200, 129, 233, 56,
+		186, 78, 168, 27, 57, 94, 18, 121, 123, 132, 185, 207, 49, 75, 149, 70,
+		112, 94, 131, 156, 122, 153, 214, 6, 178, 71, 221, 180, 249, 172, 122,
+		52, 20, 221}
+
+	assert.Equal(t, "t3vvmn62lofvhjd2ugzca6sof2j2ubwok6cj4xxbfzz4yuxfkgobpihhd2thlanmsh3w2ptld2gqkn2jvlss4a", String(Testnet, BLS, publicKey))
+	assert.Equal(t, "f3vvmn62lofvhjd2ugzca6sof2j2ubwok6cj4xxbfzz4yuxfkgobpihhd2thlanmsh3w2ptld2gqkn2jvlss4a", String(Mainnet, BLS, publicKey))
+	assert.Equal(t, append([]byte{3}, publicKey...), Bytes(BLS, publicKey))
+}
@@ -0,0 +1,244 @@
+// Package bench times proofs and signature operations on the local machine,
+// so operators can qualify hardware from Go instead of running benchy.
+package bench
+
+import (
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// SealTimings is how long each step of sealing a sector took
+type SealTimings struct {
+	SectorSize uint64
+	AddPiece   time.Duration
+	PreCommit  time.Duration
+	Commit     time.Duration
+	VerifySeal time.Duration
+	ProofBytes int
+}
+
+// PoStTimings is how long each step of proving a sector took
+type PoStTimings struct {
+	GenerateCandidates time.Duration
+	GeneratePoSt       time.Duration
+	VerifyPoSt         time.Duration
+	ProofBytes         int
+}
+
+// BLSTimings is how long it took to aggregate and verify a batch of
+// signatures
+type BLSTimings struct {
+	Signatures int
+	Aggregate  time.Duration
+	Verify     time.Duration
+}
+
+// SealResult holds the timings of a sector's seal and of a PoSt over it
+type SealResult struct {
+	Seal SealTimings
+	PoSt PoStTimings
+}
+
+// SealAndPoSt seals a sector of random data in a scratch directory under
+// workDir, generates and verifies an election PoSt over it with
+// challengeCount challenges, and returns how long each step took. The sector
+// size must be one of ffi.SupportedSectorSizes, and its parameters must
+// already be fetched. The scratch directory is removed afterwards.
+func SealAndPoSt(workDir string, sectorSize uint64, challengeCount uint64) (SealResult, error) {
+	var info *ffi.SectorSizeInfo
+	for _, supported := range ffi.SupportedSectorSizes() {
+		if supported.SectorSize == sectorSize {
+			info = &supported
+			break
+		}
+	}
+	if info == nil {
+		return SealResult{}, errors.Errorf("unsupported sector size %d", sectorSize)
+	}
+
+	scratchDir, err := ioutil.TempDir(workDir, "bench")
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to create scratch directory")
+	}
+	defer os.RemoveAll(scratchDir) // nolint: errcheck
+
+	cacheDirPath := filepath.Join(scratchDir, "cache")
+	if err := os.Mkdir(cacheDirPath, 0755); err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to create cache directory")
+	}
+
+	var proverID, ticket, seed, randomness [32]byte
+	for _, b := range [][]byte{proverID[:], ticket[:], seed[:], randomness[:]} {
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return SealResult{}, errors.Wrap(err, "failed to generate randomness")
+		}
+	}
+	sectorID := uint64(1)
+
+	result := SealResult{Seal: SealTimings{SectorSize: sectorSize}}
+
+	// add a random piece filling the sector
+	pieceSize := info.MaxUserBytes
+	pieceFile, err := randomFile(scratchDir, pieceSize)
+	if err != nil {
+		return SealResult{}, err
+	}
+	defer pieceFile.Close() // nolint: errcheck
+
+	stagedSectorFile, err := os.Create(filepath.Join(scratchDir, "staged"))
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to create staged sector")
+	}
+	defer stagedSectorFile.Close() // nolint: errcheck
+
+	start := time.Now()
+	_, commP, err := ffi.WriteWithoutAlignment(pieceFile, pieceSize, stagedSectorFile)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to add piece")
+	}
+	result.Seal.AddPiece = time.Since(start)
+
+	pieces := []ffi.PublicPieceInfo{{Size: pieceSize, CommP: commP}}
+	sealedSectorPath := filepath.Join(scratchDir, "sealed")
+	if err := ioutil.WriteFile(sealedSectorPath, nil, 0644); err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to create sealed sector")
+	}
+
+	// seal it
+	start = time.Now()
+	output, err := ffi.SealPreCommit(sectorSize, info.PoRepProofPartitions, cacheDirPath, stagedSectorFile.Name(), sealedSectorPath, sectorID, proverID, ticket, pieces)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to pre-commit sector")
+	}
+	result.Seal.PreCommit = time.Since(start)
+
+	start = time.Now()
+	sealProof, err := ffi.SealCommit(sectorSize, info.PoRepProofPartitions, cacheDirPath, sectorID, proverID, ticket, seed, pieces, output)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to commit sector")
+	}
+	result.Seal.Commit = time.Since(start)
+	result.Seal.ProofBytes = len(sealProof)
+
+	start = time.Now()
+	valid, err := ffi.VerifySeal(sectorSize, output.CommR, output.CommD, proverID, ticket, seed, sectorID, sealProof)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to verify seal")
+	}
+	if !valid {
+		return SealResult{}, errors.New("seal proof is invalid")
+	}
+	result.Seal.VerifySeal = time.Since(start)
+
+	// prove it
+	if err := ffi.ClearCache(cacheDirPath); err != nil {
+		return SealResult{}, err
+	}
+
+	privateInfo := ffi.NewSortedPrivateSectorInfo(ffi.PrivateSectorInfo{
+		SectorID:         sectorID,
+		CommR:            output.CommR,
+		CacheDirPath:     cacheDirPath,
+		SealedSectorPath: sealedSectorPath,
+	})
+	publicInfo := ffi.NewSortedPublicSectorInfo(ffi.PublicSectorInfo{
+		SectorID: sectorID,
+		CommR:    output.CommR,
+	})
+
+	start = time.Now()
+	candidates, err := ffi.GenerateCandidates(sectorSize, proverID, randomness, challengeCount, privateInfo)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to generate candidates")
+	}
+	result.PoSt.GenerateCandidates = time.Since(start)
+
+	start = time.Now()
+	postProof, err := ffi.GeneratePoSt(sectorSize, proverID, privateInfo, randomness, candidates)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to generate PoSt")
+	}
+	result.PoSt.GeneratePoSt = time.Since(start)
+	result.PoSt.ProofBytes = len(postProof)
+
+	start = time.Now()
+	valid, err = ffi.VerifyPoSt(sectorSize, publicInfo, randomness, challengeCount, postProof, candidates, proverID)
+	if err != nil {
+		return SealResult{}, errors.Wrap(err, "failed to verify PoSt")
+	}
+	if !valid {
+		return SealResult{}, errors.New("PoSt is invalid")
+	}
+	result.PoSt.VerifyPoSt = time.Since(start)
+
+	return result, nil
+}
+
+// BLSVerify signs count random messages with as many keys, and times
+// aggregating the signatures and verifying the aggregate
+func BLSVerify(count int) (BLSTimings, error) {
+	if count < 1 {
+		return BLSTimings{}, errors.Errorf("need at least one signature, got %d", count)
+	}
+
+	signatures := make([]ffi.Signature, count)
+	digests := make([]ffi.Digest, count)
+	publicKeys := make([]ffi.PublicKey, count)
+	for i := 0; i < count; i++ {
+		message := make(ffi.Message, 32)
+		if _, err := io.ReadFull(rand.Reader, message); err != nil {
+			return BLSTimings{}, errors.Wrap(err, "failed to generate message")
+		}
+
+		privateKey := ffi.PrivateKeyGenerate()
+		signatures[i] = *ffi.PrivateKeySign(privateKey, message)
+		digests[i] = ffi.Hash(message)
+		publicKeys[i] = ffi.PrivateKeyPublicKey(privateKey)
+	}
+
+	timings := BLSTimings{Signatures: count}
+
+	start := time.Now()
+	aggregate := ffi.Aggregate(signatures)
+	if aggregate == nil {
+		return BLSTimings{}, errors.New("failed to aggregate signatures")
+	}
+	timings.Aggregate = time.Since(start)
+
+	start = time.Now()
+	if !ffi.Verify(aggregate, digests, publicKeys) {
+		return BLSTimings{}, errors.New("aggregate signature is invalid")
+	}
+	timings.Verify = time.Since(start)
+
+	return timings, nil
+}
+
+// randomFile writes size random bytes to a new file in dir, returning it
+// rewound
+func randomFile(dir string, size uint64) (*os.File, error) {
+	file, err := ioutil.TempFile(dir, "piece")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create piece")
+	}
+
+	if _, err := io.CopyN(file, rand.Reader, int64(size)); err != nil {
+		file.Close() // nolint: errcheck
+		return nil, errors.Wrap(err, "failed to write piece")
+	}
+
+	if _, err := file.Seek(0, 0); err != nil {
+		file.Close() // nolint: errcheck
+		return nil, errors.Wrap(err, "failed to rewind piece")
+	}
+
+	return file, nil
+}
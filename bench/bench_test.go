@@ -0,0 +1,41 @@
+package bench
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndPoSt(t *testing.T) {
+	workDir, err := ioutil.TempDir("", "bench")
+	require.NoError(t, err)
+	defer os.RemoveAll(workDir) // nolint: errcheck
+
+	result, err := SealAndPoSt(workDir, 1024, 2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1024), result.Seal.SectorSize)
+	assert.True(t, result.Seal.Commit > 0)
+	assert.True(t, result.Seal.ProofBytes > 0)
+	assert.True(t, result.PoSt.GeneratePoSt > 0)
+
+	// the scratch directory is cleaned up
+	entries, err := ioutil.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = SealAndPoSt(workDir, 1000, 2)
+	assert.Error(t, err)
+}
+
+func TestBLSVerify(t *testing.T) {
+	timings, err := BLSVerify(10)
+	require.NoError(t, err)
+	assert.Equal(t, 10, timings.Signatures)
+	assert.True(t, timings.Verify > 0)
+
+	_, err = BLSVerify(0)
+	assert.Error(t, err)
+}
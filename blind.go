@@ -0,0 +1,102 @@
+package ffi
+
+import (
+	"unsafe"
+)
+
+// #cgo LDFLAGS: ${SRCDIR}/libfilecoin.a
+// #cgo pkg-config: ${SRCDIR}/filecoin.pc
+// #include "./filecoin.h"
+import "C"
+
+// BlindedMessage is the digest of a message multiplied by a blinding factor.
+// A signer can sign it with BlindSign without learning the message.
+type BlindedMessage [DigestBytes]byte
+
+// BlindingFactor is the secret scalar a message was blinded with. It is needed
+// to unblind the signature and must not be shared with the signer.
+type BlindingFactor [PrivateKeyBytes]byte
+
+// BlindedSignature is a signature over a BlindedMessage
+type BlindedSignature [SignatureBytes]byte
+
+// BlindMessage blinds a message with a random blinding factor
+func BlindMessage(message Message) (BlindedMessage, BlindingFactor) {
+	// prep request
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	// call method
+	resPtr := (*C.BlindMessageResponse)(unsafe.Pointer(C.blind_message(cMessagePtr, cMessageLen)))
+	defer C.destroy_blind_message_response(resPtr)
+
+	// prep response
+	var blinded BlindedMessage
+	blindedSlice := C.GoBytes(unsafe.Pointer(&resPtr.blinded_message), DigestBytes) // nolint: staticcheck
+	copy(blinded[:], blindedSlice)
+
+	var factor BlindingFactor
+	factorSlice := C.GoBytes(unsafe.Pointer(&resPtr.blinding_factor), PrivateKeyBytes) // nolint: staticcheck
+	copy(factor[:], factorSlice)
+
+	return blinded, factor
+}
+
+// BlindSign signs a blinded message. Returns nil if the private key is
+// invalid, or the blinded message isn't a non-identity G2 point.
+//
+// The signer learns nothing about the message it signs, so BlindSign is a
+// signing oracle for any message, including the ones the other signing
+// functions refuse, such as proofs of possession. A key used with BlindSign
+// must be generated for blind signing alone and never used for anything else.
+func BlindSign(privateKey PrivateKey, blinded BlindedMessage) *BlindedSignature {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+
+	cBlinded := C.CBytes(blinded[:])
+	defer C.free(cBlinded)
+
+	// call method
+	resPtr := (*C.PrivateKeySignResponse)(unsafe.Pointer(C.blind_sign((*C.uchar)(cPrivateKey), (*C.uchar)(cBlinded))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_response(resPtr)
+
+	// prep response
+	var signature BlindedSignature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// UnblindSignature removes the blinding factor from a blinded signature,
+// giving a signature of the original message which verifies against the
+// signer's public key like any other. Returns nil if the blinded signature or
+// the blinding factor is invalid.
+func UnblindSignature(blindedSignature *BlindedSignature, factor BlindingFactor) *Signature {
+	// prep request
+	cBlindedSignature := C.CBytes(blindedSignature[:])
+	defer C.free(cBlindedSignature)
+
+	cFactor := C.CBytes(factor[:])
+	defer C.free(cFactor)
+
+	// call method
+	resPtr := (*C.PrivateKeySignResponse)(unsafe.Pointer(C.unblind_signature((*C.uchar)(cBlindedSignature), (*C.uchar)(cFactor))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
@@ -0,0 +1,41 @@
+package ffi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlindSigning(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+	message := Message("hello blind world")
+	digest := Hash(message)
+
+	blinded, factor := BlindMessage(message)
+	assert.NotEqual(t, Digest(blinded), digest)
+
+	// blinding is randomised
+	otherBlinded, otherFactor := BlindMessage(message)
+	assert.NotEqual(t, blinded, otherBlinded)
+	assert.NotEqual(t, factor, otherFactor)
+
+	blindedSignature := BlindSign(privateKey, blinded)
+	require.NotNil(t, blindedSignature)
+	assert.False(t, Verify((*Signature)(blindedSignature), []Digest{digest}, []PublicKey{publicKey}))
+
+	signature := UnblindSignature(blindedSignature, factor)
+	require.NotNil(t, signature)
+	assert.Equal(t, *PrivateKeySign(privateKey, message), *signature)
+	assert.True(t, Verify(signature, []Digest{digest}, []PublicKey{publicKey}))
+
+	// unblinding with the wrong factor doesn't give a valid signature
+	wrong := UnblindSignature(blindedSignature, otherFactor)
+	require.NotNil(t, wrong)
+	assert.False(t, Verify(wrong, []Digest{digest}, []PublicKey{publicKey}))
+
+	// the zero scalar has no inverse, and the identity isn't a message
+	assert.Nil(t, UnblindSignature(blindedSignature, BlindingFactor{}))
+	assert.Nil(t, BlindSign(privateKey, BlindedMessage{0xc0}))
+}
@@ -1,7 +1,15 @@
 package ffi
 
 import (
+	"crypto/subtle"
+	"fmt"
+	"runtime"
+	"sync"
 	"unsafe"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/filecoin-ffi/address"
 )
 
 // #cgo LDFLAGS: ${SRCDIR}/libfilecoin.a
@@ -33,9 +41,92 @@ type PublicKey [PublicKeyBytes]byte
 // Message is a byte slice
 type Message []byte
 
-// Digest is a compressed affine
+// Digest is a compressed affine. Digests returned by Hash are always valid G2
+// points; DigestFromBytes checks digests coming from anywhere else.
 type Digest [DigestBytes]byte
 
+// PrivateKeyGenSeed is used to generate a private key deterministically
+type PrivateKeyGenSeed [32]byte
+
+// Address returns the string form of the BLS (f3 or t3) address of the public
+// key on the given network
+func (publicKey PublicKey) Address(network address.Network) string {
+	return address.String(network, address.BLS, publicKey[:])
+}
+
+// SigningScheme selects how a message is turned into the bytes actually signed
+type SigningScheme int
+
+const (
+	// SchemeBasic signs messages as they are. Messages in an aggregate
+	// signature must be distinct.
+	SchemeBasic SigningScheme = C.SchemeBasic
+
+	// SchemeAugmented prefixes every message with the signer's public key,
+	// so messages in an aggregate signature needn't be distinct.
+	SchemeAugmented SigningScheme = C.SchemeAugmented
+)
+
+func (s SigningScheme) valid() bool {
+	return s == SchemeBasic || s == SchemeAugmented
+}
+
+// PopDomain prefixes the message signed by PopProve. The signing functions
+// refuse messages starting with it, so that an ordinary signature can't be
+// passed off as a proof of possession. It must match POP_DOMAIN in Rust.
+const PopDomain = "BLS_POP_BLS12381G2_FILECOIN_FFI_"
+
+// VRFOutputBytes is the length of a VRF output
+const VRFOutputBytes = 32
+
+// VRFProof is a compressed affine
+type VRFProof [SignatureBytes]byte
+
+// VRFOutput is the pseudorandom value derived from a VRF proof
+type VRFOutput [VRFOutputBytes]byte
+
+// SetBLSThreads limits the parallel BLS functions (Verify, Aggregate,
+// BatchVerify, PrivateKeySignMany, ...) to a dedicated pool of numThreads
+// threads instead of one thread per core. Passing 0 goes back to the default.
+// Calls already in progress finish on the pool they started on.
+func SetBLSThreads(numThreads int) error {
+	if numThreads < 0 {
+		return errors.Errorf("invalid number of threads %d", numThreads)
+	}
+
+	// call method
+	res := (C.int)(C.bls_set_num_threads(C.size_t(numThreads)))
+	if res <= 0 {
+		return errors.Errorf("failed to create a pool of %d threads", numThreads)
+	}
+
+	return nil
+}
+
+// ConstantTimeEqual reports whether two private keys are equal, in time which
+// doesn't depend on their contents
+func (privateKey PrivateKey) ConstantTimeEqual(other PrivateKey) bool {
+	return subtle.ConstantTimeCompare(privateKey[:], other[:]) == 1
+}
+
+// ConstantTimeEqual reports whether two public keys are equal, in time which
+// doesn't depend on their contents
+func (publicKey PublicKey) ConstantTimeEqual(other PublicKey) bool {
+	return subtle.ConstantTimeCompare(publicKey[:], other[:]) == 1
+}
+
+// ConstantTimeEqual reports whether two signatures are equal, in time which
+// doesn't depend on their contents
+func (signature Signature) ConstantTimeEqual(other Signature) bool {
+	return subtle.ConstantTimeCompare(signature[:], other[:]) == 1
+}
+
+// ConstantTimeEqual reports whether two digests are equal, in time which
+// doesn't depend on their contents
+func (digest Digest) ConstantTimeEqual(other Digest) bool {
+	return subtle.ConstantTimeCompare(digest[:], other[:]) == 1
+}
+
 // Hash computes the digest of a message
 func Hash(message Message) Digest {
 	// prep request
@@ -56,6 +147,94 @@ func Hash(message Message) Digest {
 	return digest
 }
 
+// Reasons VerifyWithReason gives for a signature failing to verify
+var (
+	ErrMalformedSignature = errors.New("signature is not a valid compressed G2 point")
+	ErrMalformedDigest    = errors.New("digest is not a valid compressed G2 point")
+	ErrMalformedPublicKey = errors.New("public key is not a valid compressed point of the G1 subgroup")
+	ErrLengthMismatch     = errors.New("number of digests and public keys differ")
+	ErrNoDigests          = errors.New("no digests to verify")
+	ErrDuplicateDigest    = errors.New("digests are not distinct")
+	ErrPairingMismatch    = errors.New("signature does not match digests and public keys")
+)
+
+// VerifyWithReason is like Verify, but returns nil if the signature is valid
+// and one of the Err* reasons above if it isn't
+func VerifyWithReason(signature *Signature, digests []Digest, publicKeys []PublicKey) error {
+	// prep data
+	flattenedDigests := make([]byte, DigestBytes*len(digests))
+	for idx, digest := range digests {
+		copy(flattenedDigests[(DigestBytes*idx):(DigestBytes*(1+idx))], digest[:])
+	}
+
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cFlattenedDigests := C.CBytes(flattenedDigests)
+	defer C.free(cFlattenedDigests)
+	cFlattenedDigestsPtr := (*C.uint8_t)(cFlattenedDigests)
+	cFlattenedDigestsLen := C.size_t(len(flattenedDigests))
+
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
+
+	// call method
+	status := C.verify_with_reason(cSignaturePtr, cFlattenedDigestsPtr, cFlattenedDigestsLen, cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen)
+
+	switch status {
+	case C.VerifyValid:
+		return nil
+	case C.VerifyMalformedSignature:
+		return ErrMalformedSignature
+	case C.VerifyMalformedDigest:
+		return ErrMalformedDigest
+	case C.VerifyMalformedPublicKey:
+		return ErrMalformedPublicKey
+	case C.VerifyLengthMismatch:
+		return ErrLengthMismatch
+	case C.VerifyNoDigests:
+		return ErrNoDigests
+	case C.VerifyDuplicateDigest:
+		return ErrDuplicateDigest
+	case C.VerifyPairingMismatch:
+		return ErrPairingMismatch
+	default:
+		return errors.Errorf("unknown verify status %d", status)
+	}
+}
+
+// ErrNilSignature is returned by VerifyChecked when passed a nil signature
+var ErrNilSignature = errors.New("signature is nil")
+
+// VerifyChecked is like Verify, but distinguishes invalid arguments from
+// invalid signatures: it returns an error if signature is nil, if there are no
+// digests, if a digest isn't a valid G2 point, if the number of digests and
+// public keys differ or if the digests aren't distinct. Otherwise the error is
+// nil and the bool reports whether the signature is valid.
+func VerifyChecked(signature *Signature, digests []Digest, publicKeys []PublicKey) (bool, error) {
+	if signature == nil {
+		return false, ErrNilSignature
+	}
+
+	switch err := VerifyWithReason(signature, digests, publicKeys); err {
+	case nil:
+		return true, nil
+	case ErrMalformedDigest, ErrLengthMismatch, ErrNoDigests, ErrDuplicateDigest:
+		return false, err
+	default:
+		return false, nil
+	}
+}
+
 // Verify verifies that a signature is the aggregated signature of digests - pubkeys
 func Verify(signature *Signature, digests []Digest, publicKeys []PublicKey) bool {
 	// prep data
@@ -90,91 +269,1109 @@ func Verify(signature *Signature, digests []Digest, publicKeys []PublicKey) bool
 	return res > 0
 }
 
-// Aggregate aggregates signatures together into a new signature
-func Aggregate(signatures []Signature) *Signature {
+// asyncVerifications tracks the VerifyAsync calls whose result hasn't been
+// delivered yet. A single goroutine waits on the Rust side for results and
+// hands each one to the channel of the call it belongs to.
+var asyncVerifications = struct {
+	sync.Mutex
+	start   sync.Once
+	nextID  uint64
+	pending map[uint64]chan bool
+}{pending: make(map[uint64]chan bool)}
+
+// VerifyAsync is like Verify, but returns immediately with a channel which
+// receives the result once the verification finishes on the Rust thread
+// pool. However many verifications are outstanding, only one goroutine is
+// blocked in Rust waiting for them.
+func VerifyAsync(signature *Signature, digests []Digest, publicKeys []PublicKey) <-chan bool {
+	asyncVerifications.start.Do(func() {
+		go deliverAsyncVerifications()
+	})
+
 	// prep data
-	flattenedSignatures := make([]byte, SignatureBytes*len(signatures))
-	for idx, sig := range signatures {
-		copy(flattenedSignatures[(SignatureBytes*idx):(SignatureBytes*(1+idx))], sig[:])
+	flattenedDigests := make([]byte, DigestBytes*len(digests))
+	for idx, digest := range digests {
+		copy(flattenedDigests[(DigestBytes*idx):(DigestBytes*(1+idx))], digest[:])
+	}
+
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
 	}
 
 	// prep request
-	cFlattenedSignatures := C.CBytes(flattenedSignatures)
-	defer C.free(cFlattenedSignatures)
-	cFlattenedSignaturesPtr := (*C.uint8_t)(cFlattenedSignatures)
-	cFlattenedSignaturesLen := C.size_t(len(flattenedSignatures))
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cFlattenedDigests := C.CBytes(flattenedDigests)
+	defer C.free(cFlattenedDigests)
+	cFlattenedDigestsPtr := (*C.uint8_t)(cFlattenedDigests)
+	cFlattenedDigestsLen := C.size_t(len(flattenedDigests))
+
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
+
+	result := make(chan bool, 1)
+
+	asyncVerifications.Lock()
+	id := asyncVerifications.nextID
+	asyncVerifications.nextID++
+	asyncVerifications.pending[id] = result
+	asyncVerifications.Unlock()
 
 	// call method
-	resPtr := (*C.AggregateResponse)(unsafe.Pointer(C.aggregate(cFlattenedSignaturesPtr, cFlattenedSignaturesLen)))
-	if resPtr == nil {
+	C.verify_async(C.uint64_t(id), cSignaturePtr, cFlattenedDigestsPtr, cFlattenedDigestsLen, cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen)
+
+	return result
+}
+
+func deliverAsyncVerifications() {
+	for {
+		// call method
+		completion := C.verify_async_next()
+
+		asyncVerifications.Lock()
+		result := asyncVerifications.pending[uint64(completion.id)]
+		delete(asyncVerifications.pending, uint64(completion.id))
+		asyncVerifications.Unlock()
+
+		result <- completion.valid > 0
+	}
+}
+
+// PublicKeyHandle is a public key kept decompressed on the Rust side, so that
+// verifying many signatures by the same key doesn't decompress it every time.
+// Close must be called to release the handle, after which it must not be
+// passed to any of the *WithHandles functions.
+type PublicKeyHandle struct {
+	ptr *C.PublicKeyHandle
+}
+
+// DeserializePublicKey decompresses a public key into a handle. Returns nil if
+// the public key is invalid.
+func DeserializePublicKey(publicKey PublicKey) *PublicKeyHandle {
+	// prep request
+	cPublicKey := C.CBytes(publicKey[:])
+	defer C.free(cPublicKey)
+
+	// call method
+	ptr := C.public_key_deserialize((*C.uchar)(cPublicKey))
+	if ptr == nil {
 		return nil
 	}
-	defer C.destroy_aggregate_response(resPtr)
 
-	// prep response
-	var signature Signature
-	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
-	copy(signature[:], signatureSlice)
+	return &PublicKeyHandle{ptr: ptr}
+}
 
-	return &signature
+// Close releases the memory held by the handle
+func (h *PublicKeyHandle) Close() error {
+	if h.ptr != nil {
+		C.destroy_public_key_handle(h.ptr)
+		h.ptr = nil
+	}
+
+	return nil
 }
 
-// PrivateKeyGenerate generates a private key
-func PrivateKeyGenerate() PrivateKey {
+// VerifyWithHandles is like Verify, but takes public keys already
+// decompressed by DeserializePublicKey. Returns false if any of the handles is
+// nil or closed.
+func VerifyWithHandles(signature *Signature, digests []Digest, publicKeys []*PublicKeyHandle) bool {
+	if !openPublicKeyHandles(publicKeys) {
+		return false
+	}
+
+	// prep data
+	flattenedDigests := make([]byte, DigestBytes*len(digests))
+	for idx, digest := range digests {
+		copy(flattenedDigests[(DigestBytes*idx):(DigestBytes*(1+idx))], digest[:])
+	}
+
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cFlattenedDigests := C.CBytes(flattenedDigests)
+	defer C.free(cFlattenedDigests)
+	cFlattenedDigestsPtr := (*C.uint8_t)(cFlattenedDigests)
+	cFlattenedDigestsLen := C.size_t(len(flattenedDigests))
+
+	cPublicKeyHandlesPtr, cPublicKeyHandlesLen := cPublicKeyHandles(publicKeys)
+	defer C.free(unsafe.Pointer(cPublicKeyHandlesPtr))
+
 	// call method
-	resPtr := (*C.PrivateKeyGenerateResponse)(unsafe.Pointer(C.private_key_generate()))
-	defer C.destroy_private_key_generate_response(resPtr)
+	res := (C.int)(C.verify_with_public_key_handles(cSignaturePtr, cFlattenedDigestsPtr, cFlattenedDigestsLen, cPublicKeyHandlesPtr, cPublicKeyHandlesLen))
 
-	// prep response
-	var privateKey PrivateKey
-	privateKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.private_key), PrivateKeyBytes) // nolint: staticcheck
-	copy(privateKey[:], privateKeySlice)
+	// the handles' finalizers must not run while Rust reads them
+	runtime.KeepAlive(publicKeys)
 
-	return privateKey
+	return res > 0
 }
 
-// PrivateKeySign signs a message
-func PrivateKeySign(privateKey PrivateKey, message Message) *Signature {
+// VerifyInPlace is like Verify, but passes its arguments to Rust without
+// copying or flattening them: a slice of digests or public keys already is a
+// flat byte array.
+func VerifyInPlace(signature *Signature, digests []Digest, publicKeys []PublicKey) bool {
+	if len(digests) == 0 || len(digests) != len(publicKeys) {
+		return false
+	}
+
+	// call method
+	res := (C.int)(C.verify((*C.uchar)(unsafe.Pointer(&signature[0])), (*C.uint8_t)(unsafe.Pointer(&digests[0][0])), C.size_t(DigestBytes*len(digests)), (*C.uint8_t)(unsafe.Pointer(&publicKeys[0][0])), C.size_t(PublicKeyBytes*len(publicKeys))))
+
+	return res > 0
+}
+
+// AggregateVerify verifies that a signature is the aggregated signature of
+// messages - pubkeys. Messages are hashed on the Rust side and must be distinct.
+// It is equivalent to calling Hash on every message and passing the digests to
+// Verify, but hashes within a single FFI call.
+func AggregateVerify(signature *Signature, messages []Message, publicKeys []PublicKey) bool {
+	// prep data
+	var flattenedMessages []byte
+	for _, message := range messages {
+		flattenedMessages = append(flattenedMessages, message...)
+	}
+
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
 	// prep request
-	cPrivateKey := C.CBytes(privateKey[:])
-	defer C.free(cPrivateKey)
-	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
 
-	cMessage := C.CBytes(message)
-	defer C.free(cMessage)
-	cMessagePtr := (*C.uchar)(cMessage)
-	cMessageLen := C.size_t(len(message))
+	cFlattenedMessages := C.CBytes(flattenedMessages)
+	defer C.free(cFlattenedMessages)
+	cFlattenedMessagesPtr := (*C.uint8_t)(cFlattenedMessages)
+	cFlattenedMessagesLen := C.size_t(len(flattenedMessages))
+
+	cMessageSizesPtr, cMessageSizesLen := cMessageSizes(messages)
+	defer C.free(unsafe.Pointer(cMessageSizesPtr))
+
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
 
 	// call method
-	resPtr := (*C.PrivateKeySignResponse)(unsafe.Pointer(C.private_key_sign(cPrivateKeyPtr, cMessagePtr, cMessageLen)))
-	if resPtr == nil {
-		return nil
+	res := (C.int)(C.aggregate_verify(cSignaturePtr, cFlattenedMessagesPtr, cFlattenedMessagesLen, cMessageSizesPtr, cMessageSizesLen, cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen))
+
+	return res > 0
+}
+
+// AggregateVerifyWithScheme is like AggregateVerify, but for messages signed
+// under the given scheme. Under SchemeAugmented the messages may repeat.
+// Returns false for an unknown scheme.
+func AggregateVerifyWithScheme(signature *Signature, messages []Message, publicKeys []PublicKey, scheme SigningScheme) bool {
+	if !scheme.valid() {
+		return false
 	}
-	defer C.destroy_private_key_sign_response(resPtr)
 
-	// prep response
-	var signature Signature
-	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
-	copy(signature[:], signatureSlice)
+	// prep data
+	var flattenedMessages []byte
+	for _, message := range messages {
+		flattenedMessages = append(flattenedMessages, message...)
+	}
 
-	return &signature
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cFlattenedMessages := C.CBytes(flattenedMessages)
+	defer C.free(cFlattenedMessages)
+	cFlattenedMessagesPtr := (*C.uint8_t)(cFlattenedMessages)
+	cFlattenedMessagesLen := C.size_t(len(flattenedMessages))
+
+	cMessageSizesPtr, cMessageSizesLen := cMessageSizes(messages)
+	defer C.free(unsafe.Pointer(cMessageSizesPtr))
+
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
+
+	// call method
+	res := (C.int)(C.aggregate_verify_with_scheme(cSignaturePtr, cFlattenedMessagesPtr, cFlattenedMessagesLen, cMessageSizesPtr, cMessageSizesLen, cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen, C.uint32_t(scheme)))
+
+	return res > 0
 }
 
-// PrivateKeyPublicKey gets the public key for a private key
-func PrivateKeyPublicKey(privateKey PrivateKey) PublicKey {
+// FastAggregateVerify verifies that a signature is the aggregated signature of
+// a single message signed by every pubkey. The pubkeys are aggregated on the
+// Rust side so only one pairing check is performed. Callers must guard against
+// rogue-key attacks, e.g. by checking PopVerify for every pubkey.
+func FastAggregateVerify(signature *Signature, message Message, publicKeys []PublicKey) bool {
+	// prep data
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
 	// prep request
-	cPrivateKey := C.CBytes(privateKey[:])
-	defer C.free(cPrivateKey)
-	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
 
 	// call method
-	resPtr := (*C.PrivateKeyPublicKeyResponse)(unsafe.Pointer(C.private_key_public_key(cPrivateKeyPtr))) // nolint: staticcheck
-	defer C.destroy_private_key_public_key_response(resPtr)
+	res := (C.int)(C.fast_aggregate_verify(cSignaturePtr, cMessagePtr, cMessageLen, cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen))
 
-	// prep response
-	var publicKey PublicKey
-	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
-	copy(publicKey[:], publicKeySlice)
+	return res > 0
+}
+
+// FastAggregateVerifyWithHandles is like FastAggregateVerify, but takes public
+// keys already decompressed by DeserializePublicKey. Returns false if any of
+// the handles is nil or closed.
+func FastAggregateVerifyWithHandles(signature *Signature, message Message, publicKeys []*PublicKeyHandle) bool {
+	if !openPublicKeyHandles(publicKeys) {
+		return false
+	}
+
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	cPublicKeyHandlesPtr, cPublicKeyHandlesLen := cPublicKeyHandles(publicKeys)
+	defer C.free(unsafe.Pointer(cPublicKeyHandlesPtr))
+
+	// call method
+	res := (C.int)(C.fast_aggregate_verify_with_public_key_handles(cSignaturePtr, cMessagePtr, cMessageLen, cPublicKeyHandlesPtr, cPublicKeyHandlesLen))
+
+	// the handles' finalizers must not run while Rust reads them
+	runtime.KeepAlive(publicKeys)
+
+	return res > 0
+}
+
+// BatchVerify verifies many independent signatures of digests - pubkeys in a
+// single FFI call and reports whether each signature is valid. The signatures
+// are checked together using randomized batching on the Rust side and are
+// only re-checked individually when the batch fails. Returns nil if the slices
+// differ in length.
+func BatchVerify(signatures []Signature, digests []Digest, publicKeys []PublicKey) []bool {
+	// prep data
+	flattenedSignatures := make([]byte, SignatureBytes*len(signatures))
+	for idx, sig := range signatures {
+		copy(flattenedSignatures[(SignatureBytes*idx):(SignatureBytes*(1+idx))], sig[:])
+	}
+
+	flattenedDigests := make([]byte, DigestBytes*len(digests))
+	for idx, digest := range digests {
+		copy(flattenedDigests[(DigestBytes*idx):(DigestBytes*(1+idx))], digest[:])
+	}
+
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
+	// prep request
+	cFlattenedSignatures := C.CBytes(flattenedSignatures)
+	defer C.free(cFlattenedSignatures)
+	cFlattenedSignaturesPtr := (*C.uint8_t)(cFlattenedSignatures)
+	cFlattenedSignaturesLen := C.size_t(len(flattenedSignatures))
+
+	cFlattenedDigests := C.CBytes(flattenedDigests)
+	defer C.free(cFlattenedDigests)
+	cFlattenedDigestsPtr := (*C.uint8_t)(cFlattenedDigests)
+	cFlattenedDigestsLen := C.size_t(len(flattenedDigests))
+
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
+
+	// call method
+	resPtr := (*C.BatchVerifyResponse)(unsafe.Pointer(C.batch_verify(cFlattenedSignaturesPtr, cFlattenedSignaturesLen, cFlattenedDigestsPtr, cFlattenedDigestsLen, cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_batch_verify_response(resPtr)
+
+	// prep response
+	results := make([]bool, resPtr.results_len)
+	if resPtr.results_len == 0 {
+		return results
+	}
+
+	cResults := (*[1 << 30]C.bool)(unsafe.Pointer(resPtr.results_ptr))[:resPtr.results_len:resPtr.results_len]
+	for i := range results {
+		results[i] = bool(cResults[i])
+	}
+
+	return results
+}
+
+// Aggregate aggregates signatures together into a new signature
+func Aggregate(signatures []Signature) *Signature {
+	// prep data
+	flattenedSignatures := make([]byte, SignatureBytes*len(signatures))
+	for idx, sig := range signatures {
+		copy(flattenedSignatures[(SignatureBytes*idx):(SignatureBytes*(1+idx))], sig[:])
+	}
+
+	// prep request
+	cFlattenedSignatures := C.CBytes(flattenedSignatures)
+	defer C.free(cFlattenedSignatures)
+	cFlattenedSignaturesPtr := (*C.uint8_t)(cFlattenedSignatures)
+	cFlattenedSignaturesLen := C.size_t(len(flattenedSignatures))
+
+	// call method
+	resPtr := (*C.AggregateResponse)(unsafe.Pointer(C.aggregate(cFlattenedSignaturesPtr, cFlattenedSignaturesLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_aggregate_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// ErrNoSignatures is returned by AggregateChecked when passed no signatures
+var ErrNoSignatures = errors.New("no signatures to aggregate")
+
+// InvalidSignaturesError is returned by AggregateChecked, listing the indices
+// of the signatures it refused to aggregate
+type InvalidSignaturesError struct {
+	// Invalid signatures fail SignatureValidate
+	Invalid []int
+
+	// Duplicate signatures repeat an earlier signature
+	Duplicate []int
+}
+
+func (e *InvalidSignaturesError) Error() string {
+	return fmt.Sprintf("cannot aggregate signatures: invalid at %v, duplicate at %v", e.Invalid, e.Duplicate)
+}
+
+// AggregateChecked is like Aggregate, but first checks every signature with
+// SignatureValidate and for repeats. If any fail, no aggregate is computed and
+// the error is an *InvalidSignaturesError listing them, rather than an
+// aggregate which can never verify.
+func AggregateChecked(signatures []Signature) (*Signature, error) {
+	if len(signatures) == 0 {
+		return nil, ErrNoSignatures
+	}
+
+	var invalid, duplicate []int
+	seen := make(map[Signature]struct{}, len(signatures))
+	for idx := range signatures {
+		if _, ok := seen[signatures[idx]]; ok {
+			duplicate = append(duplicate, idx)
+			continue
+		}
+		seen[signatures[idx]] = struct{}{}
+
+		if !SignatureValidate(&signatures[idx]) {
+			invalid = append(invalid, idx)
+		}
+	}
+
+	if len(invalid) > 0 || len(duplicate) > 0 {
+		return nil, &InvalidSignaturesError{Invalid: invalid, Duplicate: duplicate}
+	}
+
+	aggregate := Aggregate(signatures)
+	if aggregate == nil {
+		return nil, errors.New("failed to aggregate signatures")
+	}
+
+	return aggregate, nil
+}
+
+// Aggregator folds signatures into an aggregate one at a time, as they
+// arrive, rather than all at once in Aggregate. It is safe for concurrent use.
+// Close must be called to release the aggregator.
+type Aggregator struct {
+	lk  sync.Mutex
+	ptr *C.Aggregator
+}
+
+// NewAggregator creates an Aggregator holding no signatures
+func NewAggregator() *Aggregator {
+	return &Aggregator{ptr: C.aggregator_new()}
+}
+
+// Add folds signature into the aggregate. It returns false and leaves the
+// aggregate unchanged if signature is nil or invalid, or if the aggregator is
+// closed.
+func (a *Aggregator) Add(signature *Signature) bool {
+	if signature == nil {
+		return false
+	}
+
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	if a.ptr == nil {
+		return false
+	}
+
+	// call method
+	return C.aggregator_add(a.ptr, (*C.uchar)(cSignature)) == 1
+}
+
+// Finish returns the aggregate of the signatures added so far, or nil if the
+// aggregator is closed. More signatures may still be added afterwards.
+func (a *Aggregator) Finish() *Signature {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	if a.ptr == nil {
+		return nil
+	}
+
+	// call method
+	resPtr := (*C.AggregateResponse)(unsafe.Pointer(C.aggregator_finish(a.ptr)))
+	defer C.destroy_aggregate_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// Close releases the memory held by the aggregator
+func (a *Aggregator) Close() error {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	if a.ptr != nil {
+		C.destroy_aggregator(a.ptr)
+		a.ptr = nil
+	}
+
+	return nil
+}
+
+// AggregatePublicKeys aggregates public keys together into a new public key.
+// The result can be reused to verify many signatures of the same signer set.
+func AggregatePublicKeys(publicKeys []PublicKey) *PublicKey {
+	// prep data
+	flattenedPublicKeys := make([]byte, PublicKeyBytes*len(publicKeys))
+	for idx, publicKey := range publicKeys {
+		copy(flattenedPublicKeys[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
+	// prep request
+	cFlattenedPublicKeys := C.CBytes(flattenedPublicKeys)
+	defer C.free(cFlattenedPublicKeys)
+	cFlattenedPublicKeysPtr := (*C.uint8_t)(cFlattenedPublicKeys)
+	cFlattenedPublicKeysLen := C.size_t(len(flattenedPublicKeys))
+
+	// call method
+	resPtr := (*C.AggregatePublicKeysResponse)(unsafe.Pointer(C.aggregate_public_keys(cFlattenedPublicKeysPtr, cFlattenedPublicKeysLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_aggregate_public_keys_response(resPtr)
+
+	// prep response
+	var publicKey PublicKey
+	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
+	copy(publicKey[:], publicKeySlice)
+
+	return &publicKey
+}
+
+// PrivateKeyGenerate generates a private key
+func PrivateKeyGenerate() PrivateKey {
+	// call method
+	resPtr := (*C.PrivateKeyGenerateResponse)(unsafe.Pointer(C.private_key_generate()))
+	defer C.destroy_private_key_generate_response(resPtr)
+
+	// prep response
+	var privateKey PrivateKey
+	privateKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.private_key), PrivateKeyBytes) // nolint: staticcheck
+	copy(privateKey[:], privateKeySlice)
+
+	return privateKey
+}
+
+// PrivateKeyGenerateWithSeed generates a private key deterministically from a
+// seed. The seed must be kept as secret as the private key itself.
+func PrivateKeyGenerateWithSeed(seed PrivateKeyGenSeed) PrivateKey {
+	// prep request
+	cSeed := C.CBytes(seed[:])
+	defer C.free(cSeed)
+	cSeedPtr := (*C.uchar)(cSeed)
+
+	// call method
+	resPtr := (*C.PrivateKeyGenerateResponse)(unsafe.Pointer(C.private_key_generate_with_seed(cSeedPtr)))
+	defer C.destroy_private_key_generate_response(resPtr)
+
+	// prep response
+	var privateKey PrivateKey
+	privateKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.private_key), PrivateKeyBytes) // nolint: staticcheck
+	copy(privateKey[:], privateKeySlice)
+
+	return privateKey
+}
+
+// Destroy overwrites the private key with zeros. Copies of the key made before
+// calling Destroy, e.g. by passing it by value, are not affected.
+func (privateKey *PrivateKey) Destroy() {
+	for i := range privateKey {
+		privateKey[i] = 0
+	}
+}
+
+// LockedPrivateKey is a handle to a private key generated and kept in a page
+// of Rust memory which is locked into RAM, so that the stored key never
+// reaches the Go heap or swap. Short-lived copies made on the Rust stack while
+// signing are wiped afterwards where possible, but aren't locked. Destroy must
+// be called to wipe and release the key.
+type LockedPrivateKey struct {
+	ptr *C.LockedPrivateKey
+}
+
+// PrivateKeyGenerateLocked generates a private key in locked memory. It returns
+// nil if the memory could not be locked, e.g. because RLIMIT_MEMLOCK is
+// exhausted.
+func PrivateKeyGenerateLocked() *LockedPrivateKey {
+	// call method
+	ptr := C.private_key_generate_locked()
+	if ptr == nil {
+		return nil
+	}
+
+	return &LockedPrivateKey{ptr: ptr}
+}
+
+// Sign signs a message with the locked private key. It returns nil if the
+// message starts with PopDomain or the key has been destroyed.
+func (privateKey *LockedPrivateKey) Sign(message Message) *Signature {
+	if privateKey.ptr == nil {
+		return nil
+	}
+
+	// prep request
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	// call method
+	resPtr := (*C.PrivateKeySignResponse)(unsafe.Pointer(C.locked_private_key_sign(privateKey.ptr, cMessagePtr, cMessageLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// PublicKey gets the public key for the locked private key. It returns the
+// zero public key if the key has been destroyed.
+func (privateKey *LockedPrivateKey) PublicKey() PublicKey {
+	if privateKey.ptr == nil {
+		return PublicKey{}
+	}
+
+	// call method
+	resPtr := (*C.PrivateKeyPublicKeyResponse)(unsafe.Pointer(C.locked_private_key_public_key(privateKey.ptr)))
+	if resPtr == nil {
+		return PublicKey{}
+	}
+	defer C.destroy_private_key_public_key_response(resPtr)
+
+	// prep response
+	var publicKey PublicKey
+	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
+	copy(publicKey[:], publicKeySlice)
 
 	return publicKey
 }
+
+// Destroy wipes the private key and releases its memory. Afterwards Sign
+// returns nil and PublicKey the zero public key.
+func (privateKey *LockedPrivateKey) Destroy() {
+	if privateKey.ptr != nil {
+		C.destroy_locked_private_key(privateKey.ptr)
+		privateKey.ptr = nil
+	}
+}
+
+// PrivateKeySign signs a message. It returns nil if the private key is invalid
+// or the message starts with PopDomain.
+func PrivateKeySign(privateKey PrivateKey, message Message) *Signature {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	// call method
+	resPtr := (*C.PrivateKeySignResponse)(unsafe.Pointer(C.private_key_sign(cPrivateKeyPtr, cMessagePtr, cMessageLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// PrivateKeySignInto is like PrivateKeySign, but writes the signature to
+// signature instead of allocating one, and passes its arguments to Rust
+// without copying them. Returns false if the private key is invalid or the
+// message starts with PopDomain.
+func PrivateKeySignInto(privateKey *PrivateKey, message Message, signature *Signature) bool {
+	// call method
+	res := (C.int)(C.private_key_sign_into((*C.uchar)(unsafe.Pointer(&privateKey[0])), messagePtr(message), C.size_t(len(message)), (*C.uchar)(unsafe.Pointer(&signature[0]))))
+
+	return res > 0
+}
+
+// PrivateKeySignWithScheme signs a message under the given scheme. It returns
+// nil if the private key or the scheme is invalid, or if the bytes signed
+// under the scheme start with PopDomain.
+func PrivateKeySignWithScheme(privateKey PrivateKey, message Message, scheme SigningScheme) *Signature {
+	if !scheme.valid() {
+		return nil
+	}
+
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	// call method
+	resPtr := (*C.PrivateKeySignResponse)(unsafe.Pointer(C.private_key_sign_with_scheme(cPrivateKeyPtr, cMessagePtr, cMessageLen, C.uint32_t(scheme))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// PrivateKeySignMany signs many messages with a private key in a single FFI
+// call, in parallel, and returns the signatures in the same order. It returns
+// nil if the private key is invalid or any message starts with PopDomain.
+func PrivateKeySignMany(privateKey PrivateKey, messages []Message) []Signature {
+	// prep data
+	var flattenedMessages []byte
+	for _, message := range messages {
+		flattenedMessages = append(flattenedMessages, message...)
+	}
+
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	cFlattenedMessages := C.CBytes(flattenedMessages)
+	defer C.free(cFlattenedMessages)
+	cFlattenedMessagesPtr := (*C.uint8_t)(cFlattenedMessages)
+	cFlattenedMessagesLen := C.size_t(len(flattenedMessages))
+
+	cMessageSizesPtr, cMessageSizesLen := cMessageSizes(messages)
+	defer C.free(unsafe.Pointer(cMessageSizesPtr))
+
+	// call method
+	resPtr := (*C.PrivateKeySignManyResponse)(unsafe.Pointer(C.private_key_sign_many(cPrivateKeyPtr, cFlattenedMessagesPtr, cFlattenedMessagesLen, cMessageSizesPtr, cMessageSizesLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_many_response(resPtr)
+
+	// prep response
+	return goSignatures(resPtr.flattened_signatures_ptr, resPtr.flattened_signatures_len)
+}
+
+// MultiSign signs each message with the private key at the same index in a
+// single FFI call, in parallel, and returns the signatures in the same order.
+// It returns nil if any private key is invalid, if any message starts with
+// PopDomain or if the number of private keys and messages differ.
+func MultiSign(privateKeys []PrivateKey, messages []Message) []Signature {
+	// prep data
+	flattenedPrivateKeys := make([]byte, PrivateKeyBytes*len(privateKeys))
+	for idx, privateKey := range privateKeys {
+		copy(flattenedPrivateKeys[(PrivateKeyBytes*idx):(PrivateKeyBytes*(1+idx))], privateKey[:])
+	}
+
+	var flattenedMessages []byte
+	for _, message := range messages {
+		flattenedMessages = append(flattenedMessages, message...)
+	}
+
+	// prep request
+	cFlattenedPrivateKeys := C.CBytes(flattenedPrivateKeys)
+	defer C.free(cFlattenedPrivateKeys)
+	cFlattenedPrivateKeysPtr := (*C.uint8_t)(cFlattenedPrivateKeys)
+	cFlattenedPrivateKeysLen := C.size_t(len(flattenedPrivateKeys))
+
+	cFlattenedMessages := C.CBytes(flattenedMessages)
+	defer C.free(cFlattenedMessages)
+	cFlattenedMessagesPtr := (*C.uint8_t)(cFlattenedMessages)
+	cFlattenedMessagesLen := C.size_t(len(flattenedMessages))
+
+	cMessageSizesPtr, cMessageSizesLen := cMessageSizes(messages)
+	defer C.free(unsafe.Pointer(cMessageSizesPtr))
+
+	// call method
+	resPtr := (*C.PrivateKeySignManyResponse)(unsafe.Pointer(C.private_key_sign_multi(cFlattenedPrivateKeysPtr, cFlattenedPrivateKeysLen, cFlattenedMessagesPtr, cFlattenedMessagesLen, cMessageSizesPtr, cMessageSizesLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_private_key_sign_many_response(resPtr)
+
+	// prep response
+	return goSignatures(resPtr.flattened_signatures_ptr, resPtr.flattened_signatures_len)
+}
+
+// PrivateKeyPublicKey gets the public key for a private key
+func PrivateKeyPublicKey(privateKey PrivateKey) PublicKey {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	// call method
+	resPtr := (*C.PrivateKeyPublicKeyResponse)(unsafe.Pointer(C.private_key_public_key(cPrivateKeyPtr))) // nolint: staticcheck
+	defer C.destroy_private_key_public_key_response(resPtr)
+
+	// prep response
+	var publicKey PublicKey
+	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
+	copy(publicKey[:], publicKeySlice)
+
+	return publicKey
+}
+
+// PrivateKeyValidate returns true if the private key is a canonical, non-zero
+// scalar
+func PrivateKeyValidate(privateKey PrivateKey) bool {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	// call method
+	res := (C.int)(C.private_key_validate(cPrivateKeyPtr))
+
+	return res > 0
+}
+
+// ErrInvalidPrivateKey is returned by PrivateKeyFromBytes for bytes which
+// aren't a canonical, non-zero scalar
+var ErrInvalidPrivateKey = errors.New("private key is not a canonical, non-zero scalar")
+
+// PrivateKeyFromBytes copies a private key out of b, checking that it's
+// exactly PrivateKeyBytes long and passes PrivateKeyValidate
+func PrivateKeyFromBytes(b []byte) (PrivateKey, error) {
+	var privateKey PrivateKey
+	if len(b) != PrivateKeyBytes {
+		return privateKey, errors.Errorf("private key must be %d bytes, got %d", PrivateKeyBytes, len(b))
+	}
+
+	copy(privateKey[:], b)
+	if !PrivateKeyValidate(privateKey) {
+		return PrivateKey{}, ErrInvalidPrivateKey
+	}
+
+	return privateKey, nil
+}
+
+// PublicKeyValidate returns true if the public key decompresses to a point of
+// the G1 prime-order subgroup which isn't the identity
+func PublicKeyValidate(publicKey PublicKey) bool {
+	// prep request
+	cPublicKey := C.CBytes(publicKey[:])
+	defer C.free(cPublicKey)
+	cPublicKeyPtr := (*C.uchar)(cPublicKey)
+
+	// call method
+	res := (C.int)(C.public_key_validate(cPublicKeyPtr))
+
+	return res > 0
+}
+
+// SignatureValidate returns true if the signature decompresses to a point of
+// the G2 prime-order subgroup which isn't the identity
+func SignatureValidate(signature *Signature) bool {
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	// call method
+	res := (C.int)(C.signature_validate(cSignaturePtr))
+
+	return res > 0
+}
+
+// DigestValidate returns true if the digest decompresses to a point of the G2
+// prime-order subgroup which isn't the identity
+func DigestValidate(digest Digest) bool {
+	// prep request
+	cDigest := C.CBytes(digest[:])
+	defer C.free(cDigest)
+	cDigestPtr := (*C.uchar)(cDigest)
+
+	// call method
+	res := (C.int)(C.digest_validate(cDigestPtr))
+
+	return res > 0
+}
+
+// DigestFromBytes copies a digest out of b, checking that it's exactly
+// DigestBytes long and passes DigestValidate. Use it for digests which weren't
+// produced by Hash, e.g. ones read off the wire.
+func DigestFromBytes(b []byte) (Digest, error) {
+	var digest Digest
+	if len(b) != DigestBytes {
+		return digest, errors.Errorf("digest must be %d bytes, got %d", DigestBytes, len(b))
+	}
+
+	copy(digest[:], b)
+	if !DigestValidate(digest) {
+		return Digest{}, ErrMalformedDigest
+	}
+
+	return digest, nil
+}
+
+// PopProve generates a proof of possession of a private key for its public
+// key: the signature of PopDomain followed by the public key. The signing
+// functions refuse messages starting with PopDomain, so they can't be used to
+// forge a proof for a key which wasn't generated honestly.
+func PopProve(privateKey PrivateKey) *Signature {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	// call method
+	resPtr := (*C.PopProveResponse)(unsafe.Pointer(C.pop_prove(cPrivateKeyPtr)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_pop_prove_response(resPtr)
+
+	// prep response
+	var proof Signature
+	proofSlice := C.GoBytes(unsafe.Pointer(&resPtr.proof), SignatureBytes) // nolint: staticcheck
+	copy(proof[:], proofSlice)
+
+	return &proof
+}
+
+// PopVerify verifies a proof of possession for a public key
+func PopVerify(publicKey PublicKey, proof *Signature) bool {
+	// prep request
+	cPublicKey := C.CBytes(publicKey[:])
+	defer C.free(cPublicKey)
+	cPublicKeyPtr := (*C.uchar)(cPublicKey)
+
+	cProof := C.CBytes(proof[:])
+	defer C.free(cProof)
+	cProofPtr := (*C.uchar)(cProof)
+
+	// call method
+	res := (C.int)(C.pop_verify(cPublicKeyPtr, cProofPtr))
+
+	return res > 0
+}
+
+func goSignatures(flattenedSignaturesPtr *C.uint8_t, flattenedSignaturesLen C.size_t) []Signature {
+	signatures := make([]Signature, int(flattenedSignaturesLen)/SignatureBytes)
+	if len(signatures) == 0 {
+		return signatures
+	}
+
+	flattenedSignatures := C.GoBytes(unsafe.Pointer(flattenedSignaturesPtr), C.int(flattenedSignaturesLen)) // nolint: staticcheck
+	for idx := range signatures {
+		copy(signatures[idx][:], flattenedSignatures[(SignatureBytes*idx):(SignatureBytes*(1+idx))])
+	}
+
+	return signatures
+}
+
+func cMessageSizes(messages []Message) (*C.size_t, C.size_t) {
+	srcCSizeT := C.size_t(len(messages))
+
+	// allocate array in C heap
+	cMessageSizes := C.malloc(srcCSizeT * C.sizeof_size_t)
+
+	// create a Go slice backed by the C-array
+	pp := (*[1 << 30]C.size_t)(cMessageSizes)
+	for i, message := range messages {
+		pp[i] = C.size_t(len(message))
+	}
+
+	return (*C.size_t)(cMessageSizes), srcCSizeT
+}
+
+// messagePtr returns a pointer to the start of message which is valid to pass
+// to Rust even when message is empty
+func messagePtr(message Message) *C.uchar {
+	if len(message) == 0 {
+		return (*C.uchar)(unsafe.Pointer(&emptyMessage))
+	}
+
+	return (*C.uchar)(unsafe.Pointer(&message[0]))
+}
+
+var emptyMessage byte
+
+func openPublicKeyHandles(handles []*PublicKeyHandle) bool {
+	for _, handle := range handles {
+		if handle == nil || handle.ptr == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cPublicKeyHandles(handles []*PublicKeyHandle) (**C.PublicKeyHandle, C.size_t) {
+	srcCSizeT := C.size_t(len(handles))
+
+	// allocate array in C heap
+	cHandles := C.malloc(srcCSizeT * C.size_t(unsafe.Sizeof((*C.PublicKeyHandle)(nil))))
+
+	// create a Go slice backed by the C-array
+	pp := (*[1 << 30]*C.PublicKeyHandle)(cHandles)
+	for i, handle := range handles {
+		pp[i] = handle.ptr
+	}
+
+	return (**C.PublicKeyHandle)(cHandles), srcCSizeT
+}
+
+// ComputeVRF computes the VRF proof of an input, or returns nil if the private
+// key is invalid or the input starts with PopDomain. As in Filecoin, the proof
+// is the plain signature of the input, so proofs interoperate with Filecoin's
+// ticket and election VRFs.
+func ComputeVRF(privateKey PrivateKey, input Message) *VRFProof {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	cInput := C.CBytes(input)
+	defer C.free(cInput)
+	cInputPtr := (*C.uchar)(cInput)
+	cInputLen := C.size_t(len(input))
+
+	// call method
+	resPtr := (*C.VrfComputeResponse)(unsafe.Pointer(C.vrf_compute(cPrivateKeyPtr, cInputPtr, cInputLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_vrf_compute_response(resPtr)
+
+	// prep response
+	var proof VRFProof
+	proofSlice := C.GoBytes(unsafe.Pointer(&resPtr.proof), SignatureBytes) // nolint: staticcheck
+	copy(proof[:], proofSlice)
+
+	return &proof
+}
+
+// VerifyVRF verifies the VRF proof of an input and returns its output
+func VerifyVRF(publicKey PublicKey, input Message, proof *VRFProof) (VRFOutput, bool) {
+	// prep request
+	cPublicKey := C.CBytes(publicKey[:])
+	defer C.free(cPublicKey)
+	cPublicKeyPtr := (*C.uchar)(cPublicKey)
+
+	cProof := C.CBytes(proof[:])
+	defer C.free(cProof)
+	cProofPtr := (*C.uchar)(cProof)
+
+	cInput := C.CBytes(input)
+	defer C.free(cInput)
+	cInputPtr := (*C.uchar)(cInput)
+	cInputLen := C.size_t(len(input))
+
+	// call method
+	res := (C.int)(C.vrf_verify(cPublicKeyPtr, cProofPtr, cInputPtr, cInputLen))
+	if res <= 0 {
+		return VRFOutput{}, false
+	}
+
+	return VRFProofToHash(proof), true
+}
+
+// VRFProofToHash derives the output of a VRF proof without verifying it
+func VRFProofToHash(proof *VRFProof) VRFOutput {
+	// prep request
+	cProof := C.CBytes(proof[:])
+	defer C.free(cProof)
+	cProofPtr := (*C.uchar)(cProof)
+
+	// call method
+	resPtr := (*C.VrfProofToHashResponse)(unsafe.Pointer(C.vrf_proof_to_hash(cProofPtr)))
+	defer C.destroy_vrf_proof_to_hash_response(resPtr)
+
+	// prep response
+	var output VRFOutput
+	outputSlice := C.GoBytes(unsafe.Pointer(&resPtr.output), VRFOutputBytes) // nolint: staticcheck
+	copy(output[:], outputSlice)
+
+	return output
+}
@@ -2,10 +2,15 @@ package ffi
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/filecoin-ffi/address"
 )
 
 func TestBLSSigningAndVerification(t *testing.T) {
@@ -44,6 +49,628 @@ func TestBLSSigningAndVerification(t *testing.T) {
 	assert.False(t, Verify(fooSignature, []Digest{barDigest}, []PublicKey{fooPublicKey}))
 }
 
+func TestBLSPrivateKeyGenerateWithSeed(t *testing.T) {
+	fooSeed := PrivateKeyGenSeed{1, 2, 3}
+	barSeed := PrivateKeyGenSeed{3, 2, 1}
+
+	// assert the same seed always produces the same key
+	fooPrivateKey := PrivateKeyGenerateWithSeed(fooSeed)
+	assert.Equal(t, fooPrivateKey, PrivateKeyGenerateWithSeed(fooSeed))
+
+	// assert different seeds produce different keys
+	assert.NotEqual(t, fooPrivateKey, PrivateKeyGenerateWithSeed(barSeed))
+
+	// assert the seeded key can sign and verify
+	message := Message("hello foo")
+	signature := PrivateKeySign(fooPrivateKey, message)
+	assert.True(t, Verify(signature, []Digest{Hash(message)}, []PublicKey{PrivateKeyPublicKey(fooPrivateKey)}))
+}
+
+func TestBLSAggregator(t *testing.T) {
+	signatures := make([]Signature, 5)
+	for i := range signatures {
+		privateKey := PrivateKeyGenerate()
+		signatures[i] = *PrivateKeySign(privateKey, Message{byte(i)})
+	}
+
+	aggregator := NewAggregator()
+	defer aggregator.Close()
+
+	for i := range signatures {
+		assert.True(t, aggregator.Add(&signatures[i]))
+		assert.Equal(t, Aggregate(signatures[:i+1]), aggregator.Finish())
+	}
+
+	garbage := Signature{}
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	assert.False(t, aggregator.Add(&garbage))
+	assert.False(t, aggregator.Add(nil))
+	assert.Equal(t, Aggregate(signatures), aggregator.Finish())
+
+	// assert a closed aggregator is safe to use, and aggregates nothing
+	require.NoError(t, aggregator.Close())
+	assert.False(t, aggregator.Add(&signatures[0]))
+	assert.Nil(t, aggregator.Finish())
+}
+
+func TestBLSAggregatorConcurrent(t *testing.T) {
+	signatures := make([]Signature, 16)
+	for i := range signatures {
+		privateKey := PrivateKeyGenerate()
+		signatures[i] = *PrivateKeySign(privateKey, Message{byte(i)})
+	}
+
+	aggregator := NewAggregator()
+	defer aggregator.Close()
+
+	var wg sync.WaitGroup
+	for i := range signatures {
+		wg.Add(1)
+		go func(signature *Signature) {
+			defer wg.Done()
+			assert.True(t, aggregator.Add(signature))
+			aggregator.Finish()
+		}(&signatures[i])
+	}
+	wg.Wait()
+
+	assert.Equal(t, Aggregate(signatures), aggregator.Finish())
+
+	// closing while signatures are still being added must not free the
+	// aggregator under them
+	for i := range signatures {
+		wg.Add(1)
+		go func(signature *Signature) {
+			defer wg.Done()
+			aggregator.Add(signature)
+		}(&signatures[i])
+	}
+	require.NoError(t, aggregator.Close())
+	wg.Wait()
+
+	assert.False(t, aggregator.Add(&signatures[0]))
+}
+
+func TestBLSPublicKeyHandles(t *testing.T) {
+	message := Message("hello handle world")
+
+	var digests []Digest
+	var signatures []Signature
+	var messageSignatures []Signature
+	var handles []*PublicKeyHandle
+	for i := 0; i < 3; i++ {
+		privateKey := PrivateKeyGenerate()
+
+		handle := DeserializePublicKey(PrivateKeyPublicKey(privateKey))
+		require.NotNil(t, handle)
+		defer handle.Close()
+		handles = append(handles, handle)
+
+		ownMessage := Message{byte(i)}
+		digests = append(digests, Hash(ownMessage))
+		signatures = append(signatures, *PrivateKeySign(privateKey, ownMessage))
+		messageSignatures = append(messageSignatures, *PrivateKeySign(privateKey, message))
+	}
+
+	assert.True(t, VerifyWithHandles(Aggregate(signatures), digests, handles))
+	assert.False(t, VerifyWithHandles(Aggregate(signatures), digests, handles[1:]))
+	assert.True(t, FastAggregateVerifyWithHandles(Aggregate(messageSignatures), message, handles))
+	assert.False(t, FastAggregateVerifyWithHandles(Aggregate(messageSignatures), message, handles[1:]))
+
+	// nil and closed handles are refused rather than passed to Rust
+	withNil := []*PublicKeyHandle{handles[0], nil, handles[2]}
+	assert.False(t, VerifyWithHandles(Aggregate(signatures), digests, withNil))
+	assert.False(t, FastAggregateVerifyWithHandles(Aggregate(messageSignatures), message, withNil))
+
+	require.NoError(t, handles[1].Close())
+	assert.False(t, VerifyWithHandles(Aggregate(signatures), digests, handles))
+	assert.False(t, FastAggregateVerifyWithHandles(Aggregate(messageSignatures), message, handles))
+
+	var garbage PublicKey
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	assert.Nil(t, DeserializePublicKey(garbage))
+}
+
+func TestBLSAugmentedScheme(t *testing.T) {
+	message := Message("hello augmented world")
+
+	fooPrivateKey := PrivateKeyGenerate()
+	barPrivateKey := PrivateKeyGenerate()
+	publicKeys := []PublicKey{PrivateKeyPublicKey(fooPrivateKey), PrivateKeyPublicKey(barPrivateKey)}
+	messages := []Message{message, message}
+
+	basic := Aggregate([]Signature{
+		*PrivateKeySignWithScheme(fooPrivateKey, message, SchemeBasic),
+		*PrivateKeySignWithScheme(barPrivateKey, message, SchemeBasic),
+	})
+	augmented := Aggregate([]Signature{
+		*PrivateKeySignWithScheme(fooPrivateKey, message, SchemeAugmented),
+		*PrivateKeySignWithScheme(barPrivateKey, message, SchemeAugmented),
+	})
+
+	assert.Equal(t, PrivateKeySign(fooPrivateKey, message), PrivateKeySignWithScheme(fooPrivateKey, message, SchemeBasic))
+	assert.True(t, AggregateVerifyWithScheme(augmented, messages, publicKeys, SchemeAugmented))
+	assert.False(t, AggregateVerifyWithScheme(augmented, messages, publicKeys, SchemeBasic))
+	assert.False(t, AggregateVerifyWithScheme(basic, messages, publicKeys, SchemeAugmented))
+
+	// unknown schemes are refused
+	for _, scheme := range []SigningScheme{-1, 2} {
+		assert.Nil(t, PrivateKeySignWithScheme(fooPrivateKey, message, scheme))
+		assert.False(t, AggregateVerifyWithScheme(augmented, messages, publicKeys, scheme))
+	}
+}
+
+func TestBLSPrivateKeyFromBytes(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+
+	imported, err := PrivateKeyFromBytes(privateKey[:])
+	assert.NoError(t, err)
+	assert.Equal(t, privateKey, imported)
+
+	_, err = PrivateKeyFromBytes(privateKey[1:])
+	assert.Error(t, err)
+
+	_, err = PrivateKeyFromBytes(make([]byte, PrivateKeyBytes))
+	assert.Equal(t, ErrInvalidPrivateKey, err)
+
+	_, err = PrivateKeyFromBytes([]byte(strings.Repeat("\xff", PrivateKeyBytes)))
+	assert.Equal(t, ErrInvalidPrivateKey, err)
+}
+
+func TestBLSVerifyAsync(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+
+	var results []<-chan bool
+	for i := 0; i < 20; i++ {
+		message := Message{byte(i)}
+		signature := PrivateKeySign(privateKey, message)
+
+		// sign the wrong message every third time
+		digest := Hash(message)
+		if i%3 == 0 {
+			digest = Hash(Message{byte(i + 1)})
+		}
+
+		results = append(results, VerifyAsync(signature, []Digest{digest}, []PublicKey{publicKey}))
+	}
+
+	for i, result := range results {
+		select {
+		case valid := <-result:
+			assert.Equal(t, i%3 != 0, valid)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("verification %d never finished", i)
+		}
+	}
+}
+
+func TestBLSAddress(t *testing.T) {
+	publicKey := PrivateKeyPublicKey(PrivateKeyGenerate())
+
+	mainnet := publicKey.Address(address.Mainnet)
+	assert.True(t, strings.HasPrefix(mainnet, "f3"))
+	assert.Equal(t, "t"+mainnet[1:], publicKey.Address(address.Testnet))
+}
+
+func TestBLSAggregateChecked(t *testing.T) {
+	signatures := make([]Signature, 3)
+	for i := range signatures {
+		signatures[i] = *PrivateKeySign(PrivateKeyGenerate(), Message{byte(i)})
+	}
+
+	aggregate, err := AggregateChecked(signatures)
+	require.NoError(t, err)
+	assert.Equal(t, Aggregate(signatures), aggregate)
+
+	_, err = AggregateChecked(nil)
+	assert.Equal(t, ErrNoSignatures, err)
+
+	// the compressed identity is a valid point, but not a valid signature
+	identity := Signature{0xc0}
+
+	var garbage Signature
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+
+	_, err = AggregateChecked([]Signature{signatures[0], garbage, signatures[1], signatures[0], identity})
+	require.IsType(t, &InvalidSignaturesError{}, err)
+	assert.Equal(t, []int{1, 4}, err.(*InvalidSignaturesError).Invalid)
+	assert.Equal(t, []int{3}, err.(*InvalidSignaturesError).Duplicate)
+}
+
+func TestBLSInPlace(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+
+	for _, message := range []Message{Message("hello in place world"), {}} {
+		var signature Signature
+		require.True(t, PrivateKeySignInto(&privateKey, message, &signature))
+		assert.Equal(t, PrivateKeySign(privateKey, message), &signature)
+
+		assert.True(t, VerifyInPlace(&signature, []Digest{Hash(message)}, []PublicKey{publicKey}))
+		assert.False(t, VerifyInPlace(&signature, []Digest{Hash(Message("other"))}, []PublicKey{publicKey}))
+		assert.False(t, VerifyInPlace(&signature, nil, nil))
+	}
+
+	var garbage PrivateKey
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	var untouched Signature
+	assert.False(t, PrivateKeySignInto(&garbage, Message("hello"), &untouched))
+	assert.Equal(t, Signature{}, untouched)
+}
+
+func TestBLSPrivateKeyDestroy(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	assert.NotEqual(t, PrivateKey{}, privateKey)
+
+	privateKey.Destroy()
+	assert.Equal(t, PrivateKey{}, privateKey)
+}
+
+func TestBLSLockedPrivateKey(t *testing.T) {
+	privateKey := PrivateKeyGenerateLocked()
+	if privateKey == nil {
+		t.Skip("unable to lock memory")
+	}
+	defer privateKey.Destroy()
+
+	message := Message("hello locked world")
+	signature := privateKey.Sign(message)
+
+	assert.True(t, Verify(signature, []Digest{Hash(message)}, []PublicKey{privateKey.PublicKey()}))
+
+	// assert a destroyed key is safe to use, and signs nothing
+	privateKey.Destroy()
+	assert.Nil(t, privateKey.Sign(message))
+	assert.Equal(t, PublicKey{}, privateKey.PublicKey())
+}
+
+func TestBLSConstantTimeEqual(t *testing.T) {
+	fooPrivateKey := PrivateKeyGenerate()
+	barPrivateKey := PrivateKeyGenerate()
+	assert.True(t, fooPrivateKey.ConstantTimeEqual(fooPrivateKey))
+	assert.False(t, fooPrivateKey.ConstantTimeEqual(barPrivateKey))
+
+	fooPublicKey := PrivateKeyPublicKey(fooPrivateKey)
+	barPublicKey := PrivateKeyPublicKey(barPrivateKey)
+	assert.True(t, fooPublicKey.ConstantTimeEqual(fooPublicKey))
+	assert.False(t, fooPublicKey.ConstantTimeEqual(barPublicKey))
+
+	message := Message("hello foo")
+	fooSignature := PrivateKeySign(fooPrivateKey, message)
+	barSignature := PrivateKeySign(barPrivateKey, message)
+	assert.True(t, fooSignature.ConstantTimeEqual(*fooSignature))
+	assert.False(t, fooSignature.ConstantTimeEqual(*barSignature))
+
+	fooDigest := Hash(message)
+	barDigest := Hash(Message("hello bar"))
+	assert.True(t, fooDigest.ConstantTimeEqual(fooDigest))
+	assert.False(t, fooDigest.ConstantTimeEqual(barDigest))
+}
+
+func TestBLSPrivateKeySignMany(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	messages := []Message{Message("hello"), Message(""), Message("many worlds")}
+
+	signatures := PrivateKeySignMany(privateKey, messages)
+	assert.Equal(t, len(messages), len(signatures))
+	for idx, message := range messages {
+		assert.Equal(t, *PrivateKeySign(privateKey, message), signatures[idx])
+	}
+
+	assert.Equal(t, 0, len(PrivateKeySignMany(privateKey, nil)))
+
+	// a scalar larger than the group order isn't a valid private key
+	var invalidPrivateKey PrivateKey
+	for idx := range invalidPrivateKey {
+		invalidPrivateKey[idx] = 0xff
+	}
+	assert.Nil(t, PrivateKeySignMany(invalidPrivateKey, messages))
+}
+
+func TestBLSMultiSign(t *testing.T) {
+	privateKeys := []PrivateKey{PrivateKeyGenerate(), PrivateKeyGenerate(), PrivateKeyGenerate()}
+	messages := []Message{Message("hello"), Message(""), Message("multi worlds")}
+
+	signatures := MultiSign(privateKeys, messages)
+	assert.Equal(t, len(messages), len(signatures))
+	for idx, message := range messages {
+		assert.Equal(t, *PrivateKeySign(privateKeys[idx], message), signatures[idx])
+	}
+
+	assert.Nil(t, MultiSign(privateKeys[:2], messages))
+
+	// a scalar larger than the group order isn't a valid private key
+	var invalidPrivateKey PrivateKey
+	for idx := range invalidPrivateKey {
+		invalidPrivateKey[idx] = 0xff
+	}
+	assert.Nil(t, MultiSign([]PrivateKey{privateKeys[0], invalidPrivateKey, privateKeys[2]}, messages))
+}
+
+func TestBLSSetThreads(t *testing.T) {
+	assert.Error(t, SetBLSThreads(-1))
+
+	assert.NoError(t, SetBLSThreads(2))
+	defer func() {
+		assert.NoError(t, SetBLSThreads(0))
+	}()
+
+	privateKey := PrivateKeyGenerate()
+	message := Message("hello pool")
+	signature := PrivateKeySign(privateKey, message)
+
+	assert.True(t, Verify(signature, []Digest{Hash(message)}, []PublicKey{PrivateKeyPublicKey(privateKey)}))
+}
+
+func TestBLSVerifyWithReason(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+	message := Message("hello foo")
+	digest := Hash(message)
+	signature := PrivateKeySign(privateKey, message)
+
+	assert.NoError(t, VerifyWithReason(signature, []Digest{digest}, []PublicKey{publicKey}))
+
+	assert.Equal(t, ErrMalformedSignature, VerifyWithReason(&Signature{}, []Digest{digest}, []PublicKey{publicKey}))
+	assert.Equal(t, ErrMalformedDigest, VerifyWithReason(signature, []Digest{{}}, []PublicKey{publicKey}))
+	assert.Equal(t, ErrMalformedPublicKey, VerifyWithReason(signature, []Digest{digest}, []PublicKey{{}}))
+	assert.Equal(t, ErrLengthMismatch, VerifyWithReason(signature, []Digest{digest}, []PublicKey{publicKey, publicKey}))
+	assert.Equal(t, ErrNoDigests, VerifyWithReason(signature, nil, nil))
+	assert.Equal(t, ErrDuplicateDigest, VerifyWithReason(signature, []Digest{digest, digest}, []PublicKey{publicKey, publicKey}))
+	assert.Equal(t, ErrPairingMismatch, VerifyWithReason(signature, []Digest{Hash(Message("hello bar"))}, []PublicKey{publicKey}))
+}
+
+func TestBLSVerifyChecked(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+	message := Message("hello foo")
+	digest := Hash(message)
+	signature := PrivateKeySign(privateKey, message)
+
+	valid, err := VerifyChecked(signature, []Digest{digest}, []PublicKey{publicKey})
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// assert cryptographic failures aren't errors
+	valid, err = VerifyChecked(signature, []Digest{Hash(Message("hello bar"))}, []PublicKey{publicKey})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = VerifyChecked(&Signature{}, []Digest{digest}, []PublicKey{publicKey})
+	assert.NoError(t, err)
+	assert.False(t, valid)
+
+	// assert argument problems are
+	_, err = VerifyChecked(nil, []Digest{digest}, []PublicKey{publicKey})
+	assert.Equal(t, ErrNilSignature, err)
+
+	_, err = VerifyChecked(signature, []Digest{digest}, nil)
+	assert.Equal(t, ErrLengthMismatch, err)
+
+	_, err = VerifyChecked(signature, nil, nil)
+	assert.Equal(t, ErrNoDigests, err)
+
+	_, err = VerifyChecked(signature, []Digest{digest, digest}, []PublicKey{publicKey, publicKey})
+	assert.Equal(t, ErrDuplicateDigest, err)
+
+	_, err = VerifyChecked(signature, []Digest{{}}, []PublicKey{publicKey})
+	assert.Equal(t, ErrMalformedDigest, err)
+}
+
+func TestBLSDigestFromBytes(t *testing.T) {
+	digest := Hash(Message("hello foo"))
+
+	imported, err := DigestFromBytes(digest[:])
+	assert.NoError(t, err)
+	assert.Equal(t, digest, imported)
+
+	_, err = DigestFromBytes(digest[1:])
+	assert.Error(t, err)
+
+	_, err = DigestFromBytes([]byte(strings.Repeat("\xff", DigestBytes)))
+	assert.Equal(t, ErrMalformedDigest, err)
+}
+
+func TestBLSAggregateVerify(t *testing.T) {
+	fooPrivateKey := PrivateKeyGenerate()
+	barPrivateKey := PrivateKeyGenerate()
+
+	fooPublicKey := PrivateKeyPublicKey(fooPrivateKey)
+	barPublicKey := PrivateKeyPublicKey(barPrivateKey)
+
+	fooMessage := Message("hello foo")
+	barMessage := Message("hello bar!")
+
+	fooSignature := PrivateKeySign(fooPrivateKey, fooMessage)
+	barSignature := PrivateKeySign(barPrivateKey, barMessage)
+
+	aggregateSign := Aggregate([]Signature{*fooSignature, *barSignature})
+
+	// assert the aggregate verifies against the raw messages
+	assert.True(t, AggregateVerify(aggregateSign, []Message{fooMessage, barMessage}, []PublicKey{fooPublicKey, barPublicKey}))
+
+	// assert it agrees with the digest-based verification
+	assert.True(t, Verify(aggregateSign, []Digest{Hash(fooMessage), Hash(barMessage)}, []PublicKey{fooPublicKey, barPublicKey}))
+
+	// assert messages and keys must be paired correctly
+	assert.False(t, AggregateVerify(aggregateSign, []Message{barMessage, fooMessage}, []PublicKey{fooPublicKey, barPublicKey}))
+
+	// assert the number of messages and keys must match
+	assert.False(t, AggregateVerify(aggregateSign, []Message{fooMessage}, []PublicKey{fooPublicKey, barPublicKey}))
+
+	// assert messages must be distinct
+	assert.False(t, AggregateVerify(fooSignature, []Message{fooMessage, fooMessage}, []PublicKey{fooPublicKey, fooPublicKey}))
+
+	// assert AggregateVerify agrees with Hash + Verify
+	assert.True(t, Verify(fooSignature, []Digest{Hash(fooMessage)}, []PublicKey{fooPublicKey}))
+	assert.True(t, AggregateVerify(fooSignature, []Message{fooMessage}, []PublicKey{fooPublicKey}))
+	assert.False(t, AggregateVerify(fooSignature, []Message{barMessage}, []PublicKey{fooPublicKey}))
+}
+
+func TestBLSFastAggregateVerify(t *testing.T) {
+	message := Message("hello committee")
+
+	var signatures []Signature
+	var publicKeys []PublicKey
+	for i := 0; i < 3; i++ {
+		privateKey := PrivateKeyGenerate()
+		signatures = append(signatures, *PrivateKeySign(privateKey, message))
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(privateKey))
+	}
+
+	aggregateSign := Aggregate(signatures)
+
+	// assert the aggregate verifies against the single message
+	assert.True(t, FastAggregateVerify(aggregateSign, message, publicKeys))
+
+	// assert a different message fails
+	assert.False(t, FastAggregateVerify(aggregateSign, Message("hello world"), publicKeys))
+
+	// assert a missing signer fails
+	assert.False(t, FastAggregateVerify(aggregateSign, message, publicKeys[1:]))
+
+	// assert no signers fails
+	assert.False(t, FastAggregateVerify(aggregateSign, message, nil))
+}
+
+func TestBLSAggregatePublicKeys(t *testing.T) {
+	message := Message("hello committee")
+	digest := Hash(message)
+
+	var signatures []Signature
+	var publicKeys []PublicKey
+	for i := 0; i < 3; i++ {
+		privateKey := PrivateKeyGenerate()
+		signatures = append(signatures, *PrivateKeySign(privateKey, message))
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(privateKey))
+	}
+
+	aggregateSign := Aggregate(signatures)
+	aggregatePublicKey := AggregatePublicKeys(publicKeys)
+
+	// assert the aggregated key verifies the aggregated signature
+	assert.True(t, Verify(aggregateSign, []Digest{digest}, []PublicKey{*aggregatePublicKey}))
+
+	// assert an aggregate over a subset does not
+	assert.False(t, Verify(aggregateSign, []Digest{digest}, []PublicKey{*AggregatePublicKeys(publicKeys[1:])}))
+
+	// assert garbage is rejected
+	assert.Nil(t, AggregatePublicKeys([]PublicKey{{}}))
+}
+
+func TestBLSBatchVerify(t *testing.T) {
+	var signatures []Signature
+	var digests []Digest
+	var publicKeys []PublicKey
+	for i := 0; i < 5; i++ {
+		privateKey := PrivateKeyGenerate()
+		message := Message(fmt.Sprintf("hello gossip %d", i))
+		signatures = append(signatures, *PrivateKeySign(privateKey, message))
+		digests = append(digests, Hash(message))
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(privateKey))
+	}
+
+	// assert every signature is valid
+	assert.Equal(t, []bool{true, true, true, true, true}, BatchVerify(signatures, digests, publicKeys))
+
+	// assert a bad signature is identified by index
+	signatures[3] = signatures[0]
+	assert.Equal(t, []bool{true, true, true, false, true}, BatchVerify(signatures, digests, publicKeys))
+
+	// assert garbage is rejected rather than aborting the batch
+	signatures[1] = Signature{}
+	assert.Equal(t, []bool{true, false, true, false, true}, BatchVerify(signatures, digests, publicKeys))
+
+	// assert mismatched slices are rejected
+	assert.Nil(t, BatchVerify(signatures, digests[1:], publicKeys))
+}
+
+func TestBLSValidation(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	message := Message("hello foo")
+
+	// assert well-formed values are valid
+	assert.True(t, PrivateKeyValidate(privateKey))
+	assert.True(t, PublicKeyValidate(PrivateKeyPublicKey(privateKey)))
+	assert.True(t, SignatureValidate(PrivateKeySign(privateKey, message)))
+	assert.True(t, DigestValidate(Hash(message)))
+
+	// assert garbage is invalid
+	assert.False(t, PrivateKeyValidate(PrivateKey{}))
+	assert.False(t, PublicKeyValidate(PublicKey{}))
+	assert.False(t, SignatureValidate(&Signature{}))
+	assert.False(t, DigestValidate(Digest{}))
+
+	// assert the identity is invalid (compressed, infinity flags set)
+	identity := Signature{0xc0}
+	assert.False(t, SignatureValidate(&identity))
+	assert.False(t, DigestValidate(Digest(identity)))
+	assert.False(t, PublicKeyValidate(PublicKey{0xc0}))
+}
+
+func TestBLSProofOfPossession(t *testing.T) {
+	fooPrivateKey := PrivateKeyGenerate()
+	barPrivateKey := PrivateKeyGenerate()
+
+	fooPublicKey := PrivateKeyPublicKey(fooPrivateKey)
+	barPublicKey := PrivateKeyPublicKey(barPrivateKey)
+
+	fooProof := PopProve(fooPrivateKey)
+
+	// assert the proof verifies for its own key only
+	assert.True(t, PopVerify(fooPublicKey, fooProof))
+	assert.False(t, PopVerify(barPublicKey, fooProof))
+
+	// assert an ordinary signature over the public key is not a proof
+	assert.False(t, PopVerify(fooPublicKey, PrivateKeySign(fooPrivateKey, Message(fooPublicKey[:]))))
+
+	// assert the message of a proof can't be signed directly
+	popMessage := append(Message(PopDomain), fooPublicKey[:]...)
+	assert.Nil(t, PrivateKeySign(fooPrivateKey, popMessage))
+	assert.Nil(t, PrivateKeySignWithScheme(fooPrivateKey, popMessage, SchemeBasic))
+	assert.False(t, PrivateKeySignInto(&fooPrivateKey, popMessage, &Signature{}))
+	assert.Nil(t, PrivateKeySignMany(fooPrivateKey, []Message{popMessage}))
+	assert.Nil(t, MultiSign([]PrivateKey{fooPrivateKey}, []Message{popMessage}))
+	assert.Nil(t, ComputeVRF(fooPrivateKey, popMessage))
+
+	assert.Nil(t, PartialSign(SplitPrivateKey(fooPrivateKey, 1, 1)[0], popMessage))
+
+	if lockedPrivateKey := PrivateKeyGenerateLocked(); lockedPrivateKey != nil {
+		defer lockedPrivateKey.Destroy()
+		assert.Nil(t, lockedPrivateKey.Sign(popMessage))
+	}
+}
+
+func BenchmarkBLSFastAggregateVerify(b *testing.B) {
+	message := Message("this is a message that we will all be signing")
+
+	var signatures []Signature
+	var publicKeys []PublicKey
+	for i := 0; i < 100; i++ {
+		privateKey := PrivateKeyGenerate()
+		signatures = append(signatures, *PrivateKeySign(privateKey, message))
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(privateKey))
+	}
+
+	aggregateSign := Aggregate(signatures)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !FastAggregateVerify(aggregateSign, message, publicKeys) {
+			b.Fatal("failed to verify")
+		}
+	}
+}
+
 func BenchmarkBLSVerify(b *testing.B) {
 	priv := PrivateKeyGenerate()
 
@@ -72,6 +699,71 @@ func BenchmarkBLSVerifyBatch(b *testing.B) {
 	b.Run("4000", benchmarkBLSVerifyBatchSize(4000))
 }
 
+func BenchmarkBLSAggregateVerifyBatch(b *testing.B) {
+	b.Run("10", benchmarkBLSAggregateVerifyBatchSize(10))
+	b.Run("100", benchmarkBLSAggregateVerifyBatchSize(100))
+	b.Run("1000", benchmarkBLSAggregateVerifyBatchSize(1000))
+}
+
+func benchmarkBLSAggregateVerifyBatchSize(size int) func(b *testing.B) {
+	return func(b *testing.B) {
+		var msgs []Message
+		var sigs []Signature
+		var pubks []PublicKey
+		for i := 0; i < size; i++ {
+			msg := Message(fmt.Sprintf("cats cats cats cats %d %d %d dogs", i, i, i))
+			msgs = append(msgs, msg)
+			priv := PrivateKeyGenerate()
+			sigs = append(sigs, *PrivateKeySign(priv, msg))
+			pubks = append(pubks, PrivateKeyPublicKey(priv))
+		}
+
+		agsig := Aggregate(sigs)
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if !AggregateVerify(agsig, msgs, pubks) {
+				b.Fatal("failed to verify")
+			}
+		}
+	}
+}
+
+func BenchmarkBLSPrivateKeySignMany(b *testing.B) {
+	privateKey := PrivateKeyGenerate()
+	messages := make([]Message, 1000)
+	for idx := range messages {
+		messages[idx] = Message(fmt.Sprintf("message %d", idx))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PrivateKeySignMany(privateKey, messages)
+	}
+}
+
+func BenchmarkBLSBatchVerify(b *testing.B) {
+	var signatures []Signature
+	var digests []Digest
+	var publicKeys []PublicKey
+	for i := 0; i < 1000; i++ {
+		priv := PrivateKeyGenerate()
+		msg := Message(fmt.Sprintf("cats cats cats cats %d %d %d dogs", i, i, i))
+		signatures = append(signatures, *PrivateKeySign(priv, msg))
+		digests = append(digests, Hash(msg))
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(priv))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, valid := range BatchVerify(signatures, digests, publicKeys) {
+			if !valid {
+				b.Fatal("failed to verify")
+			}
+		}
+	}
+}
+
 func benchmarkBLSVerifyBatchSize(size int) func(b *testing.B) {
 	return func(b *testing.B) {
 		var digests []Digest
@@ -101,3 +793,51 @@ func benchmarkBLSVerifyBatchSize(size int) func(b *testing.B) {
 		}
 	}
 }
+
+func TestBLSVRF(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+	input := Message("hello vrf")
+
+	proof := ComputeVRF(privateKey, input)
+	assert.NotNil(t, proof)
+
+	// assert the proof verifies and yields its output
+	output, ok := VerifyVRF(publicKey, input, proof)
+	assert.True(t, ok)
+	assert.Equal(t, VRFProofToHash(proof), output)
+
+	// assert proofs are deterministic
+	assert.Equal(t, proof, ComputeVRF(privateKey, input))
+
+	// assert the proof doesn't verify for another input or key
+	_, ok = VerifyVRF(publicKey, Message("bye vrf"), proof)
+	assert.False(t, ok)
+	_, ok = VerifyVRF(PrivateKeyPublicKey(PrivateKeyGenerate()), input, proof)
+	assert.False(t, ok)
+
+	// assert the proof is the plain signature of the input
+	signature := VRFProof(*PrivateKeySign(privateKey, input))
+	assert.Equal(t, proof, &signature)
+}
+
+func BenchmarkBLSPrivateKeySign(b *testing.B) {
+	privateKey := PrivateKeyGenerate()
+	message := Message("hello benchmark world")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		PrivateKeySign(privateKey, message)
+	}
+}
+
+func BenchmarkBLSPrivateKeySignInto(b *testing.B) {
+	privateKey := PrivateKeyGenerate()
+	message := Message("hello benchmark world")
+	var signature Signature
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		PrivateKeySignInto(&privateKey, message, &signature)
+	}
+}
@@ -0,0 +1,187 @@
+package ffi
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// throttleChunkBytes is the most a throttled writer writes between waits
+const throttleChunkBytes = 64 << 10
+
+// UnsealRequest is a sector for BulkUnseal to recover, and the writer its
+// unsealed bytes go to. The sector is staged in a file in StagingDir, or next
+// to the sealed sector if StagingDir is empty, before being copied to Output.
+type UnsealRequest struct {
+	SectorSize           uint64
+	PoRepProofPartitions uint8
+	CacheDirPath         string
+	SealedSectorPath     string
+	StagingDir           string
+	SectorID             uint64
+	ProverID             [32]byte
+	Ticket               [32]byte
+	CommD                [CommitmentBytesLen]byte
+	Output               io.Writer
+}
+
+// stagingDir is the directory the request's sector is staged in
+func (r UnsealRequest) stagingDir() string {
+	if r.StagingDir != "" {
+		return r.StagingDir
+	}
+
+	return filepath.Dir(r.SealedSectorPath)
+}
+
+// BulkUnseal unseals whole sectors, for when their unsealed copies are lost.
+// It runs up to parallelism unseals at once. Values below 1 run one unseal per
+// staging directory at a time, so that each disk stages one sector at once no
+// matter how many CPUs there are. With bytesPerSecond above 0, writes to the
+// outputs are throttled to that rate in total, so recovery doesn't starve the
+// disks of other work. Returns the error of each request, nil for those which
+// succeeded.
+func BulkUnseal(ctx context.Context, requests []UnsealRequest, parallelism int, bytesPerSecond int64) []error {
+	var rate *byteRate
+	if bytesPerSecond > 0 {
+		rate = &byteRate{bytesPerSecond: bytesPerSecond}
+	}
+
+	errs := make([]error, len(requests))
+
+	var wg sync.WaitGroup
+	for _, queue := range unsealQueues(requests, parallelism) {
+		wg.Add(1)
+		go func(queue <-chan int) {
+			defer wg.Done()
+			for idx := range queue {
+				r := requests[idx]
+
+				var w io.Writer = r.Output
+				if rate != nil {
+					w = &throttledWriter{ctx: ctx, w: r.Output, rate: rate}
+				}
+
+				errs[idx] = unsealToWriter(w, r.stagingDir(), func(unsealOutputPath string) error {
+					return UnsealWithContext(ctx, r.SectorSize, r.PoRepProofPartitions, r.CacheDirPath, r.SealedSectorPath, unsealOutputPath, r.SectorID, r.ProverID, r.Ticket, r.CommD)
+				})
+			}
+		}(queue)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// unsealQueues splits the indices of requests into one queue per worker. With
+// parallelism above 0, that many workers share a single queue; otherwise every
+// staging directory gets a queue, and so a worker, of its own.
+func unsealQueues(requests []UnsealRequest, parallelism int) []chan int {
+	if parallelism > 0 {
+		queue := make(chan int, len(requests))
+		for idx := range requests {
+			queue <- idx
+		}
+		close(queue)
+
+		if parallelism > len(requests) {
+			parallelism = len(requests)
+		}
+
+		queues := make([]chan int, parallelism)
+		for i := range queues {
+			queues[i] = queue
+		}
+
+		return queues
+	}
+
+	var dirs []string
+	byDir := make(map[string][]int)
+	for idx, r := range requests {
+		dir := r.stagingDir()
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], idx)
+	}
+
+	queues := make([]chan int, len(dirs))
+	for i, dir := range dirs {
+		queues[i] = make(chan int, len(byDir[dir]))
+		for _, idx := range byDir[dir] {
+			queues[i] <- idx
+		}
+		close(queues[i])
+	}
+
+	return queues
+}
+
+// byteRate paces the bytes written by any number of writers to a total rate
+type byteRate struct {
+	bytesPerSecond int64
+
+	lock     sync.Mutex
+	start    time.Time
+	reserved int64
+}
+
+// wait blocks until n more bytes can be written without exceeding the rate
+func (r *byteRate) wait(ctx context.Context, n int) error {
+	r.lock.Lock()
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	r.reserved += int64(n)
+	due := r.start.Add(time.Duration(float64(r.reserved) / float64(r.bytesPerSecond) * float64(time.Second)))
+	r.lock.Unlock()
+
+	delay := time.Until(due)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttledWriter writes to w no faster than rate allows
+type throttledWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	rate *byteRate
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > throttleChunkBytes {
+			chunk = chunk[:throttleChunkBytes]
+		}
+
+		if err := t.rate.wait(t.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := t.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+
+		p = p[len(chunk):]
+	}
+
+	return written, nil
+}
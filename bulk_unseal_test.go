@@ -0,0 +1,65 @@
+package ffi
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottledWriter(t *testing.T) {
+	rate := &byteRate{bytesPerSecond: 1 << 20}
+
+	var out bytes.Buffer
+	w := &throttledWriter{ctx: context.Background(), w: &out, rate: rate}
+
+	// a quarter of a second's worth of bytes
+	data := bytes.Repeat([]byte{0xab}, 1<<18)
+
+	start := time.Now()
+	n, err := w.Write(data)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Equal(t, data, out.Bytes())
+	assert.True(t, time.Since(start) >= 200*time.Millisecond, time.Since(start))
+
+	// waits give up with their context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.ctx = ctx
+	_, err = w.Write(data)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestUnsealQueues(t *testing.T) {
+	requests := []UnsealRequest{
+		{SealedSectorPath: "/disk-a/sealed/s-t01-1"},
+		{SealedSectorPath: "/disk-b/sealed/s-t01-2"},
+		{SealedSectorPath: "/disk-a/sealed/s-t01-3"},
+		{SealedSectorPath: "/disk-a/sealed/s-t01-4", StagingDir: "/disk-c/staging"},
+	}
+
+	drain := func(queues []chan int) [][]int {
+		var drained [][]int
+		for _, queue := range queues {
+			var idxs []int
+			for idx := range queue {
+				idxs = append(idxs, idx)
+			}
+			drained = append(drained, idxs)
+		}
+		return drained
+	}
+
+	// by default, every staging directory gets a worker of its own
+	assert.Equal(t, [][]int{{0, 2}, {1}, {3}}, drain(unsealQueues(requests, 0)))
+
+	// otherwise the workers share a single queue, and are never more than
+	// the requests
+	queues := unsealQueues(requests, 8)
+	assert.Equal(t, len(requests), len(queues))
+	assert.Equal(t, [][]int{{0, 1, 2, 3}, nil, nil, nil}, drain(queues))
+}
@@ -0,0 +1,130 @@
+package ffi
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// AggregatePublicKeyCache remembers the aggregated public keys of recently
+// seen signer sets, e.g. committees signing one message after another, so
+// their signatures can be verified without aggregating the same public keys
+// over again. Aggregates are kept decompressed on the Rust side. A signer set
+// is identified by the hash of its sorted public keys, so the order they're
+// passed in doesn't matter.
+type AggregatePublicKeyCache struct {
+	lk      sync.Mutex
+	size    int
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List
+}
+
+type aggregatePublicKeyCacheEntry struct {
+	key    [sha256.Size]byte
+	handle *PublicKeyHandle
+}
+
+// NewAggregatePublicKeyCache creates a cache holding the aggregates of at most
+// size signer sets, evicting the least recently used
+func NewAggregatePublicKeyCache(size int) *AggregatePublicKeyCache {
+	return &AggregatePublicKeyCache{
+		size:    size,
+		entries: make(map[[sha256.Size]byte]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Get returns the aggregate of publicKeys, aggregating them if the signer set
+// isn't cached yet. Returns nil if publicKeys is empty or holds an invalid
+// public key. The handle is released once it's no longer cached or used, so
+// callers mustn't Close it.
+func (c *AggregatePublicKeyCache) Get(publicKeys []PublicKey) *PublicKeyHandle {
+	if len(publicKeys) == 0 {
+		return nil
+	}
+
+	key := signerSetKey(publicKeys)
+
+	c.lk.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		c.lk.Unlock()
+
+		return element.Value.(*aggregatePublicKeyCacheEntry).handle
+	}
+	c.lk.Unlock()
+
+	// aggregate without holding the lock; if another goroutine races us to
+	// the same signer set, both aggregates are equal and either may be kept
+	aggregate := AggregatePublicKeys(publicKeys)
+	if aggregate == nil {
+		return nil
+	}
+
+	handle := DeserializePublicKey(*aggregate)
+	if handle == nil {
+		return nil
+	}
+	runtime.SetFinalizer(handle, (*PublicKeyHandle).Close)
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*aggregatePublicKeyCacheEntry).handle
+	}
+
+	c.entries[key] = c.order.PushFront(&aggregatePublicKeyCacheEntry{key: key, handle: handle})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*aggregatePublicKeyCacheEntry).key)
+	}
+
+	return handle
+}
+
+// FastAggregateVerify is like the package level FastAggregateVerify, but
+// takes the aggregate of publicKeys from the cache. The same rogue-key
+// caveats apply.
+func (c *AggregatePublicKeyCache) FastAggregateVerify(signature *Signature, message Message, publicKeys []PublicKey) bool {
+	handle := c.Get(publicKeys)
+	if handle == nil {
+		return false
+	}
+
+	valid := FastAggregateVerifyWithHandles(signature, message, []*PublicKeyHandle{handle})
+	runtime.KeepAlive(handle)
+
+	return valid
+}
+
+// Len returns the number of cached signer sets
+func (c *AggregatePublicKeyCache) Len() int {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	return c.order.Len()
+}
+
+func signerSetKey(publicKeys []PublicKey) [sha256.Size]byte {
+	sorted := make([]PublicKey, len(publicKeys))
+	copy(sorted, publicKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i][:], sorted[j][:]) < 0
+	})
+
+	hasher := sha256.New()
+	for idx := range sorted {
+		hasher.Write(sorted[idx][:]) // nolint: errcheck
+	}
+
+	var key [sha256.Size]byte
+	copy(key[:], hasher.Sum(nil))
+
+	return key
+}
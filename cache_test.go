@@ -0,0 +1,97 @@
+package ffi
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregatePublicKeyCache(t *testing.T) {
+	cache := NewAggregatePublicKeyCache(2)
+	message := Message("hello committee")
+
+	var publicKeys []PublicKey
+	var signatures []Signature
+	for i := 0; i < 4; i++ {
+		privateKey := PrivateKeyGenerate()
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(privateKey))
+		signatures = append(signatures, *PrivateKeySign(privateKey, message))
+	}
+
+	committee := publicKeys[:3]
+	signature := Aggregate(signatures[:3])
+
+	assert.True(t, cache.FastAggregateVerify(signature, message, committee))
+	assert.Equal(t, 1, cache.Len())
+
+	// the same signer set in another order hits the cache
+	handle := cache.Get(committee)
+	require.NotNil(t, handle)
+	assert.Equal(t, handle, cache.Get([]PublicKey{committee[2], committee[0], committee[1]}))
+	assert.Equal(t, 1, cache.Len())
+
+	assert.False(t, cache.FastAggregateVerify(signature, message, publicKeys))
+	assert.False(t, cache.FastAggregateVerify(signature, Message("bye committee"), committee))
+	assert.Equal(t, 2, cache.Len())
+
+	// the least recently used set is evicted
+	cache.Get(publicKeys[1:])
+	assert.Equal(t, 2, cache.Len())
+	assert.NotEqual(t, handle, cache.Get(committee))
+
+	var garbage PublicKey
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	assert.Nil(t, cache.Get([]PublicKey{garbage}))
+	assert.Nil(t, cache.Get(nil))
+}
+
+func TestAggregatePublicKeyCacheEvictedWhileVerifying(t *testing.T) {
+	cache := NewAggregatePublicKeyCache(1)
+	message := Message("hello evicted committee")
+
+	var publicKeys []PublicKey
+	var signatures []Signature
+	for i := 0; i < 3; i++ {
+		privateKey := PrivateKeyGenerate()
+		publicKeys = append(publicKeys, PrivateKeyPublicKey(privateKey))
+		signatures = append(signatures, *PrivateKeySign(privateKey, message))
+	}
+
+	committee := publicKeys[:2]
+	signature := Aggregate(signatures[:2])
+
+	handle := cache.Get(committee)
+	require.NotNil(t, handle)
+
+	// keep evicting the committee and collecting garbage while its handle is
+	// verified against, so that a finalizer running too early would show up
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			cache.Get(publicKeys[i%2 : i%2+2])
+			runtime.GC()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, FastAggregateVerifyWithHandles(signature, message, []*PublicKeyHandle{handle}))
+		assert.True(t, cache.FastAggregateVerify(signature, message, committee))
+	}
+
+	close(done)
+	wg.Wait()
+}
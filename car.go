@@ -0,0 +1,196 @@
+package ffi
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// carV2Header is the header of a CARv2 file's pragma, which is a CARv1 header
+// announcing version 2
+var carV2Header = []byte{0xa1, 0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x02}
+
+// CARBlockLocation locates a block in a CAR file, and so in the piece made
+// from it. Offset is where the block's section starts, at its length varint,
+// as CARv2 indexes record it. DataOffset and DataSize locate the block's data.
+type CARBlockLocation struct {
+	Cid        []byte
+	Offset     uint64
+	DataOffset uint64
+	DataSize   uint64
+}
+
+// GeneratePieceCommitmentFromCAR produces a piece commitment for a CARv1 file
+// read from carReader, zero padded to pieceSize unpadded bytes, and returns
+// the location of every block in it, in the same pass. CIDs are returned in
+// their binary form.
+func GeneratePieceCommitmentFromCAR(carReader io.Reader, pieceSize uint64) ([CommitmentBytesLen]byte, []CARBlockLocation, error) {
+	pipeReader, pipeWriter := io.Pipe()
+
+	type indexResult struct {
+		blocks []CARBlockLocation
+		err    error
+	}
+
+	done := make(chan indexResult, 1)
+	go func() {
+		index := &carIndexer{
+			src:   bufio.NewReader(carReader),
+			dst:   bufio.NewWriter(pipeWriter),
+			limit: pieceSize,
+		}
+
+		blocks, err := index.run()
+		pipeWriter.CloseWithError(err) // nolint: errcheck
+		done <- indexResult{blocks, err}
+	}()
+
+	commP, err := GeneratePieceCommitmentFromReader(pipeReader, pieceSize)
+
+	// unblock the indexer if the hasher stopped reading early
+	pipeReader.Close() // nolint: errcheck
+
+	// errors indexing reach the hasher through the pipe, but the hasher
+	// stopping early only leaves the indexer with a closed pipe, so the
+	// hasher's error is the one that explains a failure
+	res := <-done
+	if err != nil {
+		return [CommitmentBytesLen]byte{}, nil, err
+	}
+	if res.err != nil {
+		return [CommitmentBytesLen]byte{}, nil, res.err
+	}
+
+	return commP, res.blocks, nil
+}
+
+// carIndexer parses a CAR from src, copying every byte it consumes to dst
+type carIndexer struct {
+	src    *bufio.Reader
+	dst    *bufio.Writer
+	offset uint64
+	limit  uint64
+}
+
+// run indexes the whole CAR and then pads what it copied with zeroes up to
+// the limit
+func (c *carIndexer) run() ([]CARBlockLocation, error) {
+	headerLen, err := binary.ReadUvarint(c)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CAR header length")
+	}
+
+	header, err := c.next(headerLen)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CAR header")
+	}
+
+	if bytes.Equal(header, carV2Header) {
+		return nil, errors.New("CARv2 files are not supported, pass their CARv1 payload")
+	}
+
+	var blocks []CARBlockLocation
+	for {
+		offset := c.offset
+
+		sectionLen, err := binary.ReadUvarint(c)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read length of section at offset %d", offset)
+		}
+
+		section, err := c.next(sectionLen)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read section at offset %d", offset)
+		}
+
+		cidLen, err := carCidLen(section)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid CID in section at offset %d", offset)
+		}
+
+		blocks = append(blocks, CARBlockLocation{
+			Cid:        append([]byte{}, section[:cidLen]...),
+			Offset:     offset,
+			DataOffset: c.offset - sectionLen + uint64(cidLen),
+			DataSize:   sectionLen - uint64(cidLen),
+		})
+	}
+
+	for ; c.offset < c.limit; c.offset++ {
+		if err := c.dst.WriteByte(0); err != nil {
+			return nil, err
+		}
+	}
+
+	return blocks, c.dst.Flush()
+}
+
+// ReadByte consumes a byte, so binary.ReadUvarint can read from the CAR
+func (c *carIndexer) ReadByte() (byte, error) {
+	b, err := c.src.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+
+	if c.offset++; c.offset > c.limit {
+		return 0, errors.Errorf("CAR is larger than the %d byte piece", c.limit)
+	}
+
+	return b, c.dst.WriteByte(b)
+}
+
+// next consumes the next n bytes
+func (c *carIndexer) next(n uint64) ([]byte, error) {
+	if n > c.limit-c.offset {
+		return nil, errors.Errorf("CAR is larger than the %d byte piece", c.limit)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(c.src, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	c.offset += n
+
+	_, err := c.dst.Write(buf)
+	return buf, err
+}
+
+// carCidLen returns the length of the binary CID a CAR section starts with
+func carCidLen(section []byte) (int, error) {
+	// CIDv0, a bare sha2-256 multihash
+	if len(section) >= 34 && section[0] == 0x12 && section[1] == 0x20 {
+		return 34, nil
+	}
+
+	reader := bytes.NewReader(section)
+
+	// version, codec, multihash function and digest length
+	var fields [4]uint64
+	for i := range fields {
+		field, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return 0, errors.Wrap(err, "truncated CID")
+		}
+		fields[i] = field
+	}
+
+	if fields[0] != 1 {
+		return 0, errors.Errorf("unknown CID version %d", fields[0])
+	}
+
+	prefixLen := len(section) - reader.Len()
+	if fields[3] > uint64(reader.Len()) {
+		return 0, errors.New("truncated CID")
+	}
+
+	return prefixLen + int(fields[3]), nil
+}
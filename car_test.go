@@ -0,0 +1,75 @@
+package ffi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePieceCommitmentFromCAR(t *testing.T) {
+	var car bytes.Buffer
+	writeSection := func(section []byte) {
+		var length [binary.MaxVarintLen64]byte
+		car.Write(length[:binary.PutUvarint(length[:], uint64(len(section)))])
+		car.Write(section)
+	}
+
+	// {"roots": [], "version": 1}
+	writeSection([]byte{0xa2, 0x65, 'r', 'o', 'o', 't', 's', 0x80, 0x67, 'v', 'e', 'r', 's', 'i', 'o', 'n', 0x01})
+
+	// a raw CIDv1 block and a CIDv0 block
+	blockA := []byte("hello piece")
+	digestA := sha256.Sum256(blockA)
+	cidA := append([]byte{0x01, 0x55, 0x12, 0x20}, digestA[:]...)
+	offsetA := uint64(car.Len())
+	writeSection(append(append([]byte{}, cidA...), blockA...))
+
+	blockB := bytes.Repeat([]byte{0xab}, 300)
+	digestB := sha256.Sum256(blockB)
+	cidB := append([]byte{0x12, 0x20}, digestB[:]...)
+	offsetB := uint64(car.Len())
+	writeSection(append(append([]byte{}, cidB...), blockB...))
+
+	carBytes := car.Bytes()
+
+	commP, blocks, err := GeneratePieceCommitmentFromCAR(bytes.NewReader(carBytes), 508)
+	require.NoError(t, err)
+
+	// the commitment is that of the zero padded CAR
+	padded := make([]byte, 508)
+	copy(padded, carBytes)
+	expected, err := GeneratePieceCommitmentFromReader(bytes.NewReader(padded), 508)
+	require.NoError(t, err)
+	assert.Equal(t, expected, commP)
+
+	require.Equal(t, 2, len(blocks))
+	assert.Equal(t, cidA, blocks[0].Cid)
+	assert.Equal(t, offsetA, blocks[0].Offset)
+	assert.Equal(t, cidB, blocks[1].Cid)
+	assert.Equal(t, offsetB, blocks[1].Offset)
+
+	for idx, block := range [][]byte{blockA, blockB} {
+		location := blocks[idx]
+		assert.Equal(t, block, carBytes[location.DataOffset:location.DataOffset+location.DataSize])
+	}
+
+	// the CAR must fit the piece
+	_, _, err = GeneratePieceCommitmentFromCAR(bytes.NewReader(carBytes), 127)
+	assert.Error(t, err)
+
+	// and be complete
+	_, _, err = GeneratePieceCommitmentFromCAR(bytes.NewReader(carBytes[:len(carBytes)-1]), 508)
+	assert.Error(t, err)
+
+	// CARv2 isn't supported
+	carV2 := append([]byte{0x0a}, carV2Header...)
+	_, _, err = GeneratePieceCommitmentFromCAR(bytes.NewReader(carV2), 508)
+	require.Error(t, err)
+
+	// and says so, rather than reporting what it did to the hasher
+	assert.Contains(t, err.Error(), "CARv2 files are not supported")
+}
@@ -0,0 +1,177 @@
+package ffi
+
+import (
+	"unsafe"
+)
+
+// #cgo LDFLAGS: ${SRCDIR}/libfilecoin.a
+// #cgo pkg-config: ${SRCDIR}/filecoin.pc
+// #include "./filecoin.h"
+import "C"
+
+// G1Bytes is the length of a compressed G1 point
+const G1Bytes = PublicKeyBytes
+
+// G2Bytes is the length of a compressed G2 point
+const G2Bytes = SignatureBytes
+
+// ScalarBytes is the length of a scalar
+const ScalarBytes = PrivateKeyBytes
+
+// G1Point is a compressed affine. Public keys are G1 points.
+type G1Point [G1Bytes]byte
+
+// G2Point is a compressed affine. Signatures and digests are G2 points.
+type G2Point [G2Bytes]byte
+
+// Scalar is an element of the scalar field, encoded like a private key
+type Scalar [ScalarBytes]byte
+
+// G1Generator returns the generator of G1
+func G1Generator() G1Point {
+	// call method
+	resPtr := (*C.G1Response)(unsafe.Pointer(C.g1_generator()))
+	defer C.destroy_g1_response(resPtr)
+
+	return goG1Point(resPtr)
+}
+
+// G2Generator returns the generator of G2
+func G2Generator() G2Point {
+	// call method
+	resPtr := (*C.G2Response)(unsafe.Pointer(C.g2_generator()))
+	defer C.destroy_g2_response(resPtr)
+
+	return goG2Point(resPtr)
+}
+
+// G1Add returns a + b. Returns nil if either point is invalid.
+func G1Add(a, b G1Point) *G1Point {
+	// prep request
+	cA := C.CBytes(a[:])
+	defer C.free(cA)
+
+	cB := C.CBytes(b[:])
+	defer C.free(cB)
+
+	// call method
+	resPtr := (*C.G1Response)(unsafe.Pointer(C.g1_add((*C.uchar)(cA), (*C.uchar)(cB))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_g1_response(resPtr)
+
+	point := goG1Point(resPtr)
+	return &point
+}
+
+// G1Mul returns scalar * point. Returns nil if the point or the scalar is
+// invalid.
+func G1Mul(point G1Point, scalar Scalar) *G1Point {
+	// prep request
+	cPoint := C.CBytes(point[:])
+	defer C.free(cPoint)
+
+	cScalar := C.CBytes(scalar[:])
+	defer C.free(cScalar)
+
+	// call method
+	resPtr := (*C.G1Response)(unsafe.Pointer(C.g1_mul((*C.uchar)(cPoint), (*C.uchar)(cScalar))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_g1_response(resPtr)
+
+	product := goG1Point(resPtr)
+	return &product
+}
+
+// G2Add returns a + b. Returns nil if either point is invalid.
+func G2Add(a, b G2Point) *G2Point {
+	// prep request
+	cA := C.CBytes(a[:])
+	defer C.free(cA)
+
+	cB := C.CBytes(b[:])
+	defer C.free(cB)
+
+	// call method
+	resPtr := (*C.G2Response)(unsafe.Pointer(C.g2_add((*C.uchar)(cA), (*C.uchar)(cB))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_g2_response(resPtr)
+
+	point := goG2Point(resPtr)
+	return &point
+}
+
+// G2Mul returns scalar * point. Returns nil if the point or the scalar is
+// invalid.
+func G2Mul(point G2Point, scalar Scalar) *G2Point {
+	// prep request
+	cPoint := C.CBytes(point[:])
+	defer C.free(cPoint)
+
+	cScalar := C.CBytes(scalar[:])
+	defer C.free(cScalar)
+
+	// call method
+	resPtr := (*C.G2Response)(unsafe.Pointer(C.g2_mul((*C.uchar)(cPoint), (*C.uchar)(cScalar))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_g2_response(resPtr)
+
+	product := goG2Point(resPtr)
+	return &product
+}
+
+// PairingCheck reports whether the product of the pairings e(g1s[i], g2s[i])
+// is the identity, using a single final exponentiation. Returns false if a
+// point is invalid, the slices differ in length or they're empty, since an
+// empty product checks nothing.
+func PairingCheck(g1s []G1Point, g2s []G2Point) bool {
+	// prep data
+	flattenedG1s := make([]byte, G1Bytes*len(g1s))
+	for idx, point := range g1s {
+		copy(flattenedG1s[(G1Bytes*idx):(G1Bytes*(1+idx))], point[:])
+	}
+
+	flattenedG2s := make([]byte, G2Bytes*len(g2s))
+	for idx, point := range g2s {
+		copy(flattenedG2s[(G2Bytes*idx):(G2Bytes*(1+idx))], point[:])
+	}
+
+	// prep request
+	cFlattenedG1s := C.CBytes(flattenedG1s)
+	defer C.free(cFlattenedG1s)
+	cFlattenedG1sPtr := (*C.uint8_t)(cFlattenedG1s)
+	cFlattenedG1sLen := C.size_t(len(flattenedG1s))
+
+	cFlattenedG2s := C.CBytes(flattenedG2s)
+	defer C.free(cFlattenedG2s)
+	cFlattenedG2sPtr := (*C.uint8_t)(cFlattenedG2s)
+	cFlattenedG2sLen := C.size_t(len(flattenedG2s))
+
+	// call method
+	res := (C.int)(C.pairing_check(cFlattenedG1sPtr, cFlattenedG1sLen, cFlattenedG2sPtr, cFlattenedG2sLen))
+
+	return res > 0
+}
+
+func goG1Point(resPtr *C.G1Response) G1Point {
+	var point G1Point
+	pointSlice := C.GoBytes(unsafe.Pointer(&resPtr.point), G1Bytes) // nolint: staticcheck
+	copy(point[:], pointSlice)
+
+	return point
+}
+
+func goG2Point(resPtr *C.G2Response) G2Point {
+	var point G2Point
+	pointSlice := C.GoBytes(unsafe.Pointer(&resPtr.point), G2Bytes) // nolint: staticcheck
+	copy(point[:], pointSlice)
+
+	return point
+}
@@ -0,0 +1,52 @@
+package ffi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minusOne is the scalar r - 1
+var minusOne = Scalar{
+	0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0xfe, 0x5b, 0xfe, 0xff, 0x02, 0xa4, 0xbd, 0x53,
+	0x05, 0xd8, 0xa1, 0x09, 0x08, 0xd8, 0x39, 0x33, 0x48, 0x7d, 0x9d, 0x29, 0x53, 0xa7, 0xed, 0x73,
+}
+
+func TestCurveArithmetic(t *testing.T) {
+	fooPrivateKey := PrivateKeyGenerate()
+	barPrivateKey := PrivateKeyGenerate()
+	fooPublicKey := PrivateKeyPublicKey(fooPrivateKey)
+	barPublicKey := PrivateKeyPublicKey(barPrivateKey)
+	message := Message("hello curve")
+	digest := Hash(message)
+
+	// public keys and signatures are multiples of the generator and digest
+	fooG1 := G1Mul(G1Generator(), Scalar(fooPrivateKey))
+	require.NotNil(t, fooG1)
+	assert.Equal(t, G1Point(fooPublicKey), *fooG1)
+
+	fooSignature := G2Mul(G2Point(digest), Scalar(fooPrivateKey))
+	require.NotNil(t, fooSignature)
+	assert.Equal(t, G2Point(*PrivateKeySign(fooPrivateKey, message)), *fooSignature)
+
+	// addition matches aggregation
+	barSignature := PrivateKeySign(barPrivateKey, message)
+	assert.Equal(t, G1Point(*AggregatePublicKeys([]PublicKey{fooPublicKey, barPublicKey})), *G1Add(G1Point(fooPublicKey), G1Point(barPublicKey)))
+	assert.Equal(t, G2Point(*Aggregate([]Signature{Signature(*fooSignature), *barSignature})), *G2Add(*fooSignature, G2Point(*barSignature)))
+
+	// e(-G1, signature) * e(publicKey, digest) == 1
+	minusG1 := G1Mul(G1Generator(), minusOne)
+	require.NotNil(t, minusG1)
+	assert.True(t, PairingCheck([]G1Point{*minusG1, G1Point(fooPublicKey)}, []G2Point{*fooSignature, G2Point(digest)}))
+	assert.False(t, PairingCheck([]G1Point{*minusG1, G1Point(barPublicKey)}, []G2Point{*fooSignature, G2Point(digest)}))
+	assert.False(t, PairingCheck([]G1Point{*minusG1}, []G2Point{*fooSignature, G2Point(digest)}))
+	assert.False(t, PairingCheck(nil, nil))
+
+	var garbage G2Point
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	assert.Nil(t, G2Add(garbage, G2Generator()))
+	assert.Nil(t, G2Mul(garbage, minusOne))
+}
@@ -0,0 +1,240 @@
+package ffi
+
+import (
+	"sort"
+	"unsafe"
+
+	"github.com/pkg/errors"
+)
+
+// #cgo LDFLAGS: ${SRCDIR}/libfilecoin.a
+// #cgo pkg-config: ${SRCDIR}/filecoin.pc
+// #include "./filecoin.h"
+import "C"
+
+// DKGCommitment is a dealer's broadcast commitment to the coefficients of its
+// secret polynomial
+type DKGCommitment struct {
+	Dealer      uint64
+	Commitments []PublicKey
+}
+
+// DKGShare is the evaluation of a dealer's polynomial sent privately from the
+// dealer to a single recipient
+type DKGShare struct {
+	Dealer    uint64
+	Recipient uint64
+	Share     PrivateKey
+}
+
+// ErrDKGInvalidShare is returned when a share doesn't match its dealer's
+// commitment. Recipients should publish a complaint against the dealer.
+var ErrDKGInvalidShare = errors.New("share does not match dealer's commitment")
+
+// DKGParticipant runs one participant's side of a Joint-Feldman distributed
+// key generation among participants indexed 1 to n. Every participant deals
+// a random polynomial; once each has received a valid share from every
+// dealer it holds a share of a group private key which never exists in full.
+// Shares are usable with PartialSign and CombinePartialSignatures.
+//
+// Messages must be transported by the caller: the commitment is broadcast,
+// while each share must be sent to its recipient over a private channel.
+type DKGParticipant struct {
+	index           uint64
+	threshold       int
+	numParticipants int
+	commitment      DKGCommitment
+	shares          []DKGShare
+	commitments     map[uint64]DKGCommitment
+	received        map[uint64]PrivateKey
+}
+
+// NewDKGParticipant creates a participant and generates its dealing
+func NewDKGParticipant(index uint64, threshold int, numParticipants int) (*DKGParticipant, error) {
+	if threshold <= 0 || numParticipants < threshold {
+		return nil, errors.Errorf("invalid threshold %d of %d", threshold, numParticipants)
+	}
+	if index == 0 || index > uint64(numParticipants) {
+		return nil, errors.Errorf("index %d out of range 1 to %d", index, numParticipants)
+	}
+
+	// call method
+	resPtr := (*C.DkgDealingResponse)(unsafe.Pointer(C.dkg_generate_dealing(C.size_t(threshold), C.size_t(numParticipants))))
+	if resPtr == nil {
+		return nil, errors.New("failed to generate dealing")
+	}
+	defer C.destroy_dkg_dealing_response(resPtr)
+
+	// prep response
+	flattenedCommitments := C.GoBytes(unsafe.Pointer(resPtr.flattened_commitments_ptr), C.int(resPtr.flattened_commitments_len)) // nolint: staticcheck
+	flattenedShares := C.GoBytes(unsafe.Pointer(resPtr.flattened_shares_ptr), C.int(resPtr.flattened_shares_len))                // nolint: staticcheck
+
+	commitment := DKGCommitment{Dealer: index, Commitments: make([]PublicKey, threshold)}
+	for idx := range commitment.Commitments {
+		copy(commitment.Commitments[idx][:], flattenedCommitments[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))])
+	}
+
+	shares := make([]DKGShare, numParticipants)
+	for idx := range shares {
+		shares[idx] = DKGShare{Dealer: index, Recipient: uint64(idx + 1)}
+		copy(shares[idx].Share[:], flattenedShares[(PrivateKeyBytes*idx):(PrivateKeyBytes*(1+idx))])
+	}
+
+	return &DKGParticipant{
+		index:           index,
+		threshold:       threshold,
+		numParticipants: numParticipants,
+		commitment:      commitment,
+		shares:          shares,
+		commitments:     map[uint64]DKGCommitment{},
+		received:        map[uint64]PrivateKey{},
+	}, nil
+}
+
+// Commitment returns the commitment to broadcast to every participant,
+// including this one
+func (p *DKGParticipant) Commitment() DKGCommitment {
+	return p.commitment
+}
+
+// Shares returns the shares to send to every participant, including this one
+func (p *DKGParticipant) Shares() []DKGShare {
+	return p.shares
+}
+
+// ReceiveCommitment records a dealer's commitment. It must be received before
+// that dealer's share.
+func (p *DKGParticipant) ReceiveCommitment(commitment DKGCommitment) error {
+	if commitment.Dealer == 0 || commitment.Dealer > uint64(p.numParticipants) {
+		return errors.Errorf("dealer %d out of range 1 to %d", commitment.Dealer, p.numParticipants)
+	}
+	if len(commitment.Commitments) != p.threshold {
+		return errors.Errorf("dealer %d committed to %d coefficients, expected %d", commitment.Dealer, len(commitment.Commitments), p.threshold)
+	}
+	if _, ok := p.commitments[commitment.Dealer]; ok {
+		return errors.Errorf("duplicate commitment from dealer %d", commitment.Dealer)
+	}
+
+	p.commitments[commitment.Dealer] = commitment
+
+	return nil
+}
+
+// ReceiveShare verifies a dealer's share for this participant against the
+// dealer's commitment and records it. ErrDKGInvalidShare is returned if the
+// share doesn't match.
+func (p *DKGParticipant) ReceiveShare(share DKGShare) error {
+	if share.Recipient != p.index {
+		return errors.Errorf("share for participant %d received by participant %d", share.Recipient, p.index)
+	}
+
+	commitment, ok := p.commitments[share.Dealer]
+	if !ok {
+		return errors.Errorf("no commitment from dealer %d", share.Dealer)
+	}
+	if _, ok := p.received[share.Dealer]; ok {
+		return errors.Errorf("duplicate share from dealer %d", share.Dealer)
+	}
+
+	expected := DKGEvaluateCommitment(commitment, p.index)
+	if expected == nil || !expected.ConstantTimeEqual(PrivateKeyPublicKey(share.Share)) {
+		return ErrDKGInvalidShare
+	}
+
+	p.received[share.Dealer] = share.Share
+
+	return nil
+}
+
+// Finalize combines the shares received from every dealer into this
+// participant's share of the group private key, and returns it along with
+// the group public key
+func (p *DKGParticipant) Finalize() (PrivateKeyShare, PublicKey, error) {
+	if len(p.received) != p.numParticipants {
+		return PrivateKeyShare{}, PublicKey{}, errors.Errorf("received %d of %d shares", len(p.received), p.numParticipants)
+	}
+
+	// prep data
+	dealers := make([]uint64, 0, len(p.received))
+	for dealer := range p.received {
+		dealers = append(dealers, dealer)
+	}
+	sort.Slice(dealers, func(i, j int) bool { return dealers[i] < dealers[j] })
+
+	flattenedShares := make([]byte, PrivateKeyBytes*len(dealers))
+	commitments := make([]DKGCommitment, len(dealers))
+	for idx, dealer := range dealers {
+		share := p.received[dealer]
+		copy(flattenedShares[(PrivateKeyBytes*idx):(PrivateKeyBytes*(1+idx))], share[:])
+		commitments[idx] = p.commitments[dealer]
+	}
+
+	// prep request
+	cFlattenedShares := C.CBytes(flattenedShares)
+	defer C.free(cFlattenedShares)
+	cFlattenedSharesPtr := (*C.uint8_t)(cFlattenedShares)
+	cFlattenedSharesLen := C.size_t(len(flattenedShares))
+
+	// call method
+	resPtr := (*C.PrivateKeyGenerateResponse)(unsafe.Pointer(C.dkg_combine_shares(cFlattenedSharesPtr, cFlattenedSharesLen)))
+	if resPtr == nil {
+		return PrivateKeyShare{}, PublicKey{}, errors.New("failed to combine shares")
+	}
+	defer C.destroy_private_key_generate_response(resPtr)
+
+	// prep response
+	share := PrivateKeyShare{Index: p.index}
+	shareSlice := C.GoBytes(unsafe.Pointer(&resPtr.private_key), PrivateKeyBytes) // nolint: staticcheck
+	copy(share.PrivateKey[:], shareSlice)
+
+	groupPublicKey := DKGGroupPublicKey(commitments)
+	if groupPublicKey == nil {
+		return PrivateKeyShare{}, PublicKey{}, errors.New("failed to derive group public key")
+	}
+
+	return share, *groupPublicKey, nil
+}
+
+// DKGEvaluateCommitment returns the public key of the share a dealer must have
+// sent to the participant with the given index, or nil on malformed input
+func DKGEvaluateCommitment(commitment DKGCommitment, index uint64) *PublicKey {
+	// prep data
+	flattenedCommitments := make([]byte, PublicKeyBytes*len(commitment.Commitments))
+	for idx, publicKey := range commitment.Commitments {
+		copy(flattenedCommitments[(PublicKeyBytes*idx):(PublicKeyBytes*(1+idx))], publicKey[:])
+	}
+
+	// prep request
+	cFlattenedCommitments := C.CBytes(flattenedCommitments)
+	defer C.free(cFlattenedCommitments)
+	cFlattenedCommitmentsPtr := (*C.uint8_t)(cFlattenedCommitments)
+	cFlattenedCommitmentsLen := C.size_t(len(flattenedCommitments))
+
+	// call method
+	resPtr := (*C.AggregatePublicKeysResponse)(unsafe.Pointer(C.dkg_evaluate_commitments(cFlattenedCommitmentsPtr, cFlattenedCommitmentsLen, C.uint64_t(index))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_aggregate_public_keys_response(resPtr)
+
+	// prep response
+	var publicKey PublicKey
+	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
+	copy(publicKey[:], publicKeySlice)
+
+	return &publicKey
+}
+
+// DKGGroupPublicKey returns the group public key resulting from the
+// commitments of every dealer, or nil on malformed input
+func DKGGroupPublicKey(commitments []DKGCommitment) *PublicKey {
+	constantTerms := make([]PublicKey, 0, len(commitments))
+	for _, commitment := range commitments {
+		if len(commitment.Commitments) == 0 {
+			return nil
+		}
+		constantTerms = append(constantTerms, commitment.Commitments[0])
+	}
+
+	return AggregatePublicKeys(constantTerms)
+}
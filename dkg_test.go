@@ -0,0 +1,73 @@
+package ffi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDKG(t *testing.T) {
+	threshold, numParticipants := 2, 3
+
+	// assert invalid parameters are rejected
+	_, err := NewDKGParticipant(1, 0, numParticipants)
+	assert.Error(t, err)
+	_, err = NewDKGParticipant(4, threshold, numParticipants)
+	assert.Error(t, err)
+
+	participants := make([]*DKGParticipant, numParticipants)
+	for idx := range participants {
+		participants[idx], err = NewDKGParticipant(uint64(idx+1), threshold, numParticipants)
+		require.NoError(t, err)
+	}
+
+	// broadcast the commitments, then deliver the shares
+	for _, dealer := range participants {
+		for _, recipient := range participants {
+			require.NoError(t, recipient.ReceiveCommitment(dealer.Commitment()))
+		}
+	}
+	for _, dealer := range participants {
+		for _, share := range dealer.Shares() {
+			require.NoError(t, participants[share.Recipient-1].ReceiveShare(share))
+		}
+	}
+
+	shares := make([]PrivateKeyShare, numParticipants)
+	var groupPublicKey PublicKey
+	for idx, participant := range participants {
+		shares[idx], groupPublicKey, err = participant.Finalize()
+		require.NoError(t, err)
+	}
+
+	// assert a threshold of shares signs for the group public key
+	message := Message("hello dkg")
+	partials := []PartialSignature{*PartialSign(shares[2], message), *PartialSign(shares[0], message)}
+	signature := CombinePartialSignatures(partials)
+	assert.True(t, Verify(signature, []Digest{Hash(message)}, []PublicKey{groupPublicKey}))
+}
+
+func TestDKGInvalidShare(t *testing.T) {
+	dealer, err := NewDKGParticipant(1, 2, 2)
+	require.NoError(t, err)
+	recipient, err := NewDKGParticipant(2, 2, 2)
+	require.NoError(t, err)
+
+	// assert shares are rejected until the commitment arrives
+	share := dealer.Shares()[1]
+	assert.Error(t, recipient.ReceiveShare(share))
+
+	require.NoError(t, recipient.ReceiveCommitment(dealer.Commitment()))
+
+	// assert a tampered share is rejected
+	tampered := share
+	tampered.Share = dealer.Shares()[0].Share
+	assert.Equal(t, ErrDKGInvalidShare, recipient.ReceiveShare(tampered))
+
+	assert.NoError(t, recipient.ReceiveShare(share))
+
+	// assert finalizing needs a share from every dealer
+	_, _, err = recipient.Finalize()
+	assert.Error(t, err)
+}
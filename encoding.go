@@ -0,0 +1,182 @@
+package ffi
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Keys, signatures and digests are encoded as JSON base64 strings, like other
+// byte strings, and as CBOR byte strings. Decoding checks the length and that
+// the bytes are a valid key, signature or digest.
+
+// MarshalJSON implements json.Marshaler
+func (privateKey PrivateKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(privateKey[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (privateKey *PrivateKey) UnmarshalJSON(data []byte) error {
+	if err := unmarshalJSONBytes(data, privateKey[:], "private key"); err != nil {
+		return err
+	}
+
+	return checkValid(PrivateKeyValidate(*privateKey), "private key")
+}
+
+// MarshalCBOR encodes the private key as a CBOR byte string
+func (privateKey PrivateKey) MarshalCBOR(w io.Writer) error {
+	return marshalCBORBytes(w, privateKey[:])
+}
+
+// UnmarshalCBOR decodes a private key from a CBOR byte string
+func (privateKey *PrivateKey) UnmarshalCBOR(r io.Reader) error {
+	if err := unmarshalCBORBytes(r, privateKey[:], "private key"); err != nil {
+		return err
+	}
+
+	return checkValid(PrivateKeyValidate(*privateKey), "private key")
+}
+
+// MarshalJSON implements json.Marshaler
+func (publicKey PublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(publicKey[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (publicKey *PublicKey) UnmarshalJSON(data []byte) error {
+	if err := unmarshalJSONBytes(data, publicKey[:], "public key"); err != nil {
+		return err
+	}
+
+	return checkValid(PublicKeyValidate(*publicKey), "public key")
+}
+
+// MarshalCBOR encodes the public key as a CBOR byte string
+func (publicKey PublicKey) MarshalCBOR(w io.Writer) error {
+	return marshalCBORBytes(w, publicKey[:])
+}
+
+// UnmarshalCBOR decodes a public key from a CBOR byte string
+func (publicKey *PublicKey) UnmarshalCBOR(r io.Reader) error {
+	if err := unmarshalCBORBytes(r, publicKey[:], "public key"); err != nil {
+		return err
+	}
+
+	return checkValid(PublicKeyValidate(*publicKey), "public key")
+}
+
+// MarshalJSON implements json.Marshaler
+func (signature Signature) MarshalJSON() ([]byte, error) {
+	return json.Marshal(signature[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (signature *Signature) UnmarshalJSON(data []byte) error {
+	if err := unmarshalJSONBytes(data, signature[:], "signature"); err != nil {
+		return err
+	}
+
+	return checkValid(SignatureValidate(signature), "signature")
+}
+
+// MarshalCBOR encodes the signature as a CBOR byte string
+func (signature Signature) MarshalCBOR(w io.Writer) error {
+	return marshalCBORBytes(w, signature[:])
+}
+
+// UnmarshalCBOR decodes a signature from a CBOR byte string
+func (signature *Signature) UnmarshalCBOR(r io.Reader) error {
+	if err := unmarshalCBORBytes(r, signature[:], "signature"); err != nil {
+		return err
+	}
+
+	return checkValid(SignatureValidate(signature), "signature")
+}
+
+// MarshalJSON implements json.Marshaler
+func (digest Digest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(digest[:])
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (digest *Digest) UnmarshalJSON(data []byte) error {
+	if err := unmarshalJSONBytes(data, digest[:], "digest"); err != nil {
+		return err
+	}
+
+	return checkValid(DigestValidate(*digest), "digest")
+}
+
+// MarshalCBOR encodes the digest as a CBOR byte string
+func (digest Digest) MarshalCBOR(w io.Writer) error {
+	return marshalCBORBytes(w, digest[:])
+}
+
+// UnmarshalCBOR decodes a digest from a CBOR byte string
+func (digest *Digest) UnmarshalCBOR(r io.Reader) error {
+	if err := unmarshalCBORBytes(r, digest[:], "digest"); err != nil {
+		return err
+	}
+
+	return checkValid(DigestValidate(*digest), "digest")
+}
+
+func unmarshalJSONBytes(data []byte, dst []byte, name string) error {
+	var src []byte
+	if err := json.Unmarshal(data, &src); err != nil {
+		return errors.Wrapf(err, "failed to decode %s", name)
+	}
+
+	if len(src) != len(dst) {
+		return errors.Errorf("invalid %s length %d, expected %d", name, len(src), len(dst))
+	}
+
+	copy(dst, src)
+
+	return nil
+}
+
+// CBOR major type 2 (byte string) with a one byte length following, which
+// covers every type encoded here
+const cborByteStringUint8Len = 0x58
+
+func marshalCBORBytes(w io.Writer, src []byte) error {
+	if _, err := w.Write([]byte{cborByteStringUint8Len, byte(len(src))}); err != nil {
+		return err
+	}
+
+	_, err := w.Write(src)
+
+	return err
+}
+
+func unmarshalCBORBytes(r io.Reader, dst []byte, name string) error {
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return errors.Wrapf(err, "failed to read %s header", name)
+	}
+
+	if header[0] != cborByteStringUint8Len {
+		return errors.Errorf("expected CBOR byte string for %s, got header 0x%x", name, header[0])
+	}
+
+	if int(header[1]) != len(dst) {
+		return errors.Errorf("invalid %s length %d, expected %d", name, header[1], len(dst))
+	}
+
+	if _, err := io.ReadFull(r, dst); err != nil {
+		return errors.Wrapf(err, "failed to read %s", name)
+	}
+
+	return nil
+}
+
+func checkValid(valid bool, name string) error {
+	if !valid {
+		return errors.Errorf("invalid %s", name)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package ffi
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	type payload struct {
+		PrivateKey PrivateKey
+		PublicKey  PublicKey
+		Signature  *Signature
+		Digest     Digest
+	}
+
+	privateKey := PrivateKeyGenerate()
+	message := Message("hello json")
+	before := payload{
+		PrivateKey: privateKey,
+		PublicKey:  PrivateKeyPublicKey(privateKey),
+		Signature:  PrivateKeySign(privateKey, message),
+		Digest:     Hash(message),
+	}
+
+	encoded, err := json.Marshal(before)
+	require.NoError(t, err)
+
+	var after payload
+	require.NoError(t, json.Unmarshal(encoded, &after))
+	assert.Equal(t, before, after)
+}
+
+func TestJSONValidation(t *testing.T) {
+	var publicKey PublicKey
+
+	// assert bad lengths are rejected
+	encoded, err := json.Marshal(make([]byte, PublicKeyBytes-1))
+	require.NoError(t, err)
+	assert.Error(t, json.Unmarshal(encoded, &publicKey))
+
+	// assert bytes which aren't a public key are rejected
+	encoded, err = json.Marshal(PublicKey{})
+	require.NoError(t, err)
+	assert.Error(t, json.Unmarshal(encoded, &publicKey))
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+	message := Message("hello cbor")
+	signature := PrivateKeySign(privateKey, message)
+	digest := Hash(message)
+
+	var buf bytes.Buffer
+	require.NoError(t, privateKey.MarshalCBOR(&buf))
+	require.NoError(t, publicKey.MarshalCBOR(&buf))
+	require.NoError(t, signature.MarshalCBOR(&buf))
+	require.NoError(t, digest.MarshalCBOR(&buf))
+
+	// byte string header, one byte length, then the bytes
+	assert.Equal(t, []byte{0x58, PrivateKeyBytes}, buf.Bytes()[:2])
+
+	var privateKeyAfter PrivateKey
+	var publicKeyAfter PublicKey
+	var signatureAfter Signature
+	var digestAfter Digest
+	require.NoError(t, privateKeyAfter.UnmarshalCBOR(&buf))
+	require.NoError(t, publicKeyAfter.UnmarshalCBOR(&buf))
+	require.NoError(t, signatureAfter.UnmarshalCBOR(&buf))
+	require.NoError(t, digestAfter.UnmarshalCBOR(&buf))
+
+	assert.Equal(t, privateKey, privateKeyAfter)
+	assert.Equal(t, publicKey, publicKeyAfter)
+	assert.Equal(t, *signature, signatureAfter)
+	assert.Equal(t, digest, digestAfter)
+}
+
+func TestCBORValidation(t *testing.T) {
+	var signature Signature
+
+	// assert other CBOR types and lengths are rejected
+	assert.Error(t, signature.UnmarshalCBOR(bytes.NewReader([]byte{0x60})))
+	assert.Error(t, signature.UnmarshalCBOR(bytes.NewReader([]byte{0x58, SignatureBytes - 1})))
+
+	// assert truncated input is rejected
+	assert.Error(t, signature.UnmarshalCBOR(bytes.NewReader([]byte{0x58, SignatureBytes, 0})))
+
+	// assert bytes which aren't a signature are rejected
+	var buf bytes.Buffer
+	require.NoError(t, Signature{}.MarshalCBOR(&buf))
+	assert.Error(t, signature.UnmarshalCBOR(&buf))
+}
@@ -0,0 +1,215 @@
+// Package fr32 converts data to and from the Fr32 padded representation
+// sectors are sealed in. Every 254 bits of unpadded data are stored in a 32
+// byte field element whose top two bits are zero, so each 127 bytes of
+// unpadded data become 128 bytes of padded data.
+package fr32
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// UnpaddedChunkBytes is the length of the unpadded data filling a chunk
+const UnpaddedChunkBytes = 127
+
+// PaddedChunkBytes is the length of a padded chunk, four field elements
+const PaddedChunkBytes = 128
+
+// elementBytes is the length of a field element
+const elementBytes = 32
+
+// ErrPartialChunk is returned when the data being converted isn't a whole
+// number of chunks
+var ErrPartialChunk = errors.New("data is not a whole number of fr32 chunks")
+
+// Pad writes the padding of in to out. in must be a whole number of unpadded
+// chunks, and out exactly as long as their padding.
+func Pad(in, out []byte) {
+	for chunk := 0; chunk < len(in)/UnpaddedChunkBytes; chunk++ {
+		padChunk(in[chunk*UnpaddedChunkBytes:(chunk+1)*UnpaddedChunkBytes], out[chunk*PaddedChunkBytes:(chunk+1)*PaddedChunkBytes])
+	}
+}
+
+// Unpad writes the unpadded data of in to out. in must be a whole number of
+// padded chunks, and out exactly as long as the data they hold. The top two
+// bits of each field element are ignored.
+func Unpad(in, out []byte) {
+	for chunk := 0; chunk < len(in)/PaddedChunkBytes; chunk++ {
+		unpadChunk(in[chunk*PaddedChunkBytes:(chunk+1)*PaddedChunkBytes], out[chunk*UnpaddedChunkBytes:(chunk+1)*UnpaddedChunkBytes])
+	}
+}
+
+// padChunk pads a single chunk. Element k holds unpadded bits
+// [254k, 254k+254), which start shift bits into byte offset.
+func padChunk(in, out []byte) {
+	for k := 0; k < PaddedChunkBytes/elementBytes; k++ {
+		offset, shift := (254*k)/8, uint((254*k)%8)
+
+		element := out[k*elementBytes : (k+1)*elementBytes]
+		for i := range element {
+			b := in[offset+i] >> shift
+			if shift > 0 && offset+i+1 < len(in) {
+				b |= in[offset+i+1] << (8 - shift)
+			}
+			element[i] = b
+		}
+		element[elementBytes-1] &= 0x3f
+	}
+}
+
+// unpadChunk reverses padChunk
+func unpadChunk(in, out []byte) {
+	for i := range out {
+		out[i] = 0
+	}
+
+	for k := 0; k < PaddedChunkBytes/elementBytes; k++ {
+		offset, shift := (254*k)/8, uint((254*k)%8)
+
+		element := in[k*elementBytes : (k+1)*elementBytes]
+		for i, b := range element {
+			if i == elementBytes-1 {
+				b &= 0x3f
+			}
+
+			out[offset+i] |= b << shift
+			if shift > 0 && offset+i+1 < len(out) {
+				out[offset+i+1] |= b >> (8 - shift)
+			}
+		}
+	}
+}
+
+// converter turns chunks of one length into chunks of another
+type converter struct {
+	inBytes, outBytes int
+	convert           func(in, out []byte)
+}
+
+var (
+	padder   = converter{UnpaddedChunkBytes, PaddedChunkBytes, padChunk}
+	unpadder = converter{PaddedChunkBytes, UnpaddedChunkBytes, unpadChunk}
+)
+
+// reader converts the data read from r a chunk at a time
+type reader struct {
+	r         io.Reader
+	converter converter
+	in, out   []byte
+	pending   []byte
+	err       error
+}
+
+// NewPadReader returns a reader of the padding of the data read from r. If r
+// ends partway through a chunk, reading fails with ErrPartialChunk.
+func NewPadReader(r io.Reader) io.Reader {
+	return newReader(r, padder)
+}
+
+// NewUnpadReader returns a reader of the unpadded data held by the padded data
+// read from r. If r ends partway through a chunk, reading fails with
+// ErrPartialChunk.
+func NewUnpadReader(r io.Reader) io.Reader {
+	return newReader(r, unpadder)
+}
+
+func newReader(r io.Reader, c converter) *reader {
+	return &reader{
+		r:         r,
+		converter: c,
+		in:        make([]byte, c.inBytes),
+		out:       make([]byte, c.outBytes),
+	}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		_, err := io.ReadFull(r.r, r.in)
+		switch err {
+		case nil:
+		case io.EOF:
+			r.err = io.EOF
+			return 0, r.err
+		case io.ErrUnexpectedEOF:
+			r.err = ErrPartialChunk
+			return 0, r.err
+		default:
+			r.err = err
+			return 0, r.err
+		}
+
+		r.converter.convert(r.in, r.out)
+		r.pending = r.out
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+// writer converts the data written to it a chunk at a time and writes the
+// result to w
+type writer struct {
+	w         io.Writer
+	converter converter
+	in, out   []byte
+	buffered  int
+}
+
+// NewPadWriter returns a writer which writes the padding of the data written
+// to it to w. Close fails with ErrPartialChunk if the data written isn't a
+// whole number of chunks; it doesn't close w.
+func NewPadWriter(w io.Writer) io.WriteCloser {
+	return newWriter(w, padder)
+}
+
+// NewUnpadWriter returns a writer which writes the unpadded data held by the
+// padded data written to it to w. Close fails with ErrPartialChunk if the data
+// written isn't a whole number of chunks; it doesn't close w.
+func NewUnpadWriter(w io.Writer) io.WriteCloser {
+	return newWriter(w, unpadder)
+}
+
+func newWriter(w io.Writer, c converter) *writer {
+	return &writer{
+		w:         w,
+		converter: c,
+		in:        make([]byte, c.inBytes),
+		out:       make([]byte, c.outBytes),
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := copy(w.in[w.buffered:], p)
+		w.buffered += n
+		p = p[n:]
+
+		if w.buffered == len(w.in) {
+			w.converter.convert(w.in, w.out)
+			if _, err := w.w.Write(w.out); err != nil {
+				return written, err
+			}
+			w.buffered = 0
+		}
+
+		written += n
+	}
+
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	if w.buffered != 0 {
+		return ErrPartialChunk
+	}
+
+	return nil
+}
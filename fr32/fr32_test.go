@@ -0,0 +1,111 @@
+package fr32
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"io/ioutil"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// padBits is a bit-at-a-time reference padding
+func padBits(in []byte) []byte {
+	out := make([]byte, len(in)/UnpaddedChunkBytes*PaddedChunkBytes)
+
+	outBit := 0
+	for inBit := 0; inBit < len(in)*8; inBit++ {
+		if outBit%256 == 254 {
+			outBit += 2
+		}
+
+		if in[inBit/8]&(1<<uint(inBit%8)) != 0 {
+			out[outBit/8] |= 1 << uint(outBit%8)
+		}
+		outBit++
+	}
+
+	return out
+}
+
+func TestPad(t *testing.T) {
+	unpadded := make([]byte, 4*UnpaddedChunkBytes)
+	_, err := io.ReadFull(rand.Reader, unpadded)
+	require.NoError(t, err)
+
+	padded := make([]byte, 4*PaddedChunkBytes)
+	Pad(unpadded, padded)
+	assert.Equal(t, padBits(unpadded), padded)
+
+	// the top two bits of every element are clear
+	for i := elementBytes - 1; i < len(padded); i += elementBytes {
+		assert.Zero(t, padded[i]&0xc0)
+	}
+
+	// setting them doesn't change the data
+	for i := elementBytes - 1; i < len(padded); i += elementBytes {
+		padded[i] |= 0xc0
+	}
+
+	roundTripped := make([]byte, len(unpadded))
+	Unpad(padded, roundTripped)
+	assert.Equal(t, unpadded, roundTripped)
+}
+
+func TestPadKnownAnswer(t *testing.T) {
+	unpadded := bytes.Repeat([]byte{0xff}, UnpaddedChunkBytes)
+
+	padded := make([]byte, PaddedChunkBytes)
+	Pad(unpadded, padded)
+
+	element := append(bytes.Repeat([]byte{0xff}, elementBytes-1), 0x3f)
+	assert.Equal(t, bytes.Repeat(element, 4), padded)
+}
+
+func TestStreams(t *testing.T) {
+	unpadded := make([]byte, 10*UnpaddedChunkBytes)
+	_, err := io.ReadFull(rand.Reader, unpadded)
+	require.NoError(t, err)
+
+	padded := make([]byte, 10*PaddedChunkBytes)
+	Pad(unpadded, padded)
+
+	// readers, fed a byte at a time
+	read, err := ioutil.ReadAll(NewPadReader(iotest.OneByteReader(bytes.NewReader(unpadded))))
+	require.NoError(t, err)
+	assert.Equal(t, padded, read)
+
+	read, err = ioutil.ReadAll(NewUnpadReader(iotest.HalfReader(bytes.NewReader(padded))))
+	require.NoError(t, err)
+	assert.Equal(t, unpadded, read)
+
+	// writers, written in uneven pieces
+	var written bytes.Buffer
+	w := NewPadWriter(&written)
+	for _, piece := range [][]byte{unpadded[:1], unpadded[1:300], unpadded[300:]} {
+		n, err := w.Write(piece)
+		require.NoError(t, err)
+		assert.Equal(t, len(piece), n)
+	}
+	require.NoError(t, w.Close())
+	assert.Equal(t, padded, written.Bytes())
+
+	written.Reset()
+	w = NewUnpadWriter(&written)
+	_, err = w.Write(padded)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, unpadded, written.Bytes())
+
+	// partial chunks are rejected
+	_, err = ioutil.ReadAll(NewPadReader(bytes.NewReader(unpadded[:UnpaddedChunkBytes+1])))
+	assert.Equal(t, ErrPartialChunk, err)
+
+	w = NewUnpadWriter(ioutil.Discard)
+	_, err = w.Write(padded[:PaddedChunkBytes-1])
+	require.NoError(t, err)
+	assert.Equal(t, ErrPartialChunk, w.Close())
+}
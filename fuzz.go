@@ -0,0 +1,133 @@
+// +build gofuzz
+
+package ffi
+
+// The Fuzz* functions are go-fuzz entrypoints feeding arbitrary bytes through
+// the FFI. Inputs are always cut into correctly sized arrays first, so the
+// Rust side never reads past the end of a buffer; anything else it gets wrong
+// shows up as a panic or, for a Rust panic, an abort, both of which go-fuzz
+// reports as crashers. They return 1 when the input decoded to something
+// valid, so go-fuzz favours it, and 0 otherwise.
+
+// FuzzDeserialize decodes data as each of the point and scalar types
+func FuzzDeserialize(data []byte) int {
+	interesting := 0
+
+	if len(data) >= PrivateKeyBytes {
+		var privateKey PrivateKey
+		copy(privateKey[:], data)
+
+		if PrivateKeyValidate(privateKey) {
+			if PrivateKeySign(privateKey, data) == nil {
+				panic("valid private key failed to sign")
+			}
+			interesting = 1
+		}
+	}
+
+	if len(data) >= PublicKeyBytes {
+		var publicKey PublicKey
+		copy(publicKey[:], data)
+
+		handle := DeserializePublicKey(publicKey)
+		if handle != nil {
+			handle.Close() // nolint: errcheck
+			interesting = 1
+		}
+
+		if PublicKeyValidate(publicKey) && handle == nil {
+			panic("valid public key failed to deserialize")
+		}
+	}
+
+	if len(data) >= SignatureBytes {
+		var signature Signature
+		copy(signature[:], data)
+
+		if SignatureValidate(&signature) {
+			interesting = 1
+		}
+
+		var digest Digest
+		copy(digest[:], data)
+
+		if DigestValidate(digest) {
+			interesting = 1
+		}
+	}
+
+	return interesting
+}
+
+// FuzzVerify verifies a signature taken from the start of data against the
+// digest - pubkey pairs making up the rest of it
+func FuzzVerify(data []byte) int {
+	if len(data) < SignatureBytes {
+		return 0
+	}
+
+	var signature Signature
+	copy(signature[:], data)
+	data = data[SignatureBytes:]
+
+	var digests []Digest
+	var publicKeys []PublicKey
+	for len(data) >= DigestBytes+PublicKeyBytes {
+		var digest Digest
+		copy(digest[:], data)
+		digests = append(digests, digest)
+
+		var publicKey PublicKey
+		copy(publicKey[:], data[DigestBytes:])
+		publicKeys = append(publicKeys, publicKey)
+
+		data = data[DigestBytes+PublicKeyBytes:]
+	}
+
+	valid := Verify(&signature, digests, publicKeys)
+	if VerifyWithReason(&signature, digests, publicKeys) == nil && !valid {
+		panic("VerifyWithReason accepted a signature Verify rejected")
+	}
+
+	if valid {
+		return 1
+	}
+
+	return 0
+}
+
+// FuzzAggregate aggregates the signatures making up data both at once and one
+// at a time, and checks that the results agree
+func FuzzAggregate(data []byte) int {
+	var signatures []Signature
+	for len(data) >= SignatureBytes {
+		var signature Signature
+		copy(signature[:], data)
+		signatures = append(signatures, signature)
+
+		data = data[SignatureBytes:]
+	}
+
+	aggregator := NewAggregator()
+	defer aggregator.Close() // nolint: errcheck
+
+	allValid := true
+	for idx := range signatures {
+		allValid = aggregator.Add(&signatures[idx]) && allValid
+	}
+
+	aggregate := Aggregate(signatures)
+	if aggregate == nil {
+		return 0
+	}
+
+	if !allValid {
+		panic("Aggregate accepted a signature Aggregator rejected")
+	}
+
+	if *aggregate != *aggregator.Finish() {
+		panic("Aggregate and Aggregator disagree")
+	}
+
+	return 1
+}
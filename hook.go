@@ -0,0 +1,25 @@
+package ffi
+
+import "sync/atomic"
+
+// hook holds a value which is set rarely but read on every proof operation,
+// such as the current Metrics. Unlike a bare atomic.Value it accepts nil, which
+// unsets the hook.
+type hook struct {
+	value atomic.Value
+}
+
+type hookValue struct {
+	value interface{}
+}
+
+// Store replaces the hook's value
+func (h *hook) Store(value interface{}) {
+	h.value.Store(hookValue{value})
+}
+
+// Load returns the hook's value, or nil if it was never set
+func (h *hook) Load() interface{} {
+	stored, _ := h.value.Load().(hookValue)
+	return stored.value
+}
@@ -0,0 +1,119 @@
+package kat
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The LoadEth2 functions read a single test case of the hash-free suites of
+// the Ethereum BLS tests (github.com/ethereum/bls12-381-tests), in their JSON
+// form, and append it to some vectors. Each case is an object holding an input
+// and an output, with bytes hex-encoded and prefixed with 0x.
+
+// LoadEth2Aggregate appends a case of the aggregate suite to v
+func (v *Vectors) LoadEth2Aggregate(r io.Reader) error {
+	var c struct {
+		Input  []string `json:"input"`
+		Output *string  `json:"output"`
+	}
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return errors.Wrap(err, "failed to decode aggregate case")
+	}
+
+	vector := AggregateVector{Signatures: []HexBytes{}}
+	for _, input := range c.Input {
+		signature, err := decodeEth2Hex(input)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode signature")
+		}
+		vector.Signatures = append(vector.Signatures, signature)
+	}
+
+	// a null output means aggregation fails
+	if c.Output != nil {
+		aggregate, err := decodeEth2Hex(*c.Output)
+		if err != nil {
+			return errors.Wrap(err, "failed to decode aggregate")
+		}
+		vector.Aggregate = aggregate
+	}
+
+	v.Aggregate = append(v.Aggregate, vector)
+	return nil
+}
+
+// LoadEth2DeserializationG1 appends a case of the deserialization_G1 suite to
+// v. The suite deserializes the identity successfully, but PublicKeyValidate
+// rejects it, so the identity is expected to be invalid.
+func (v *Vectors) LoadEth2DeserializationG1(r io.Reader) error {
+	var c struct {
+		Input struct {
+			PublicKey string `json:"pubkey"`
+		} `json:"input"`
+		Output bool `json:"output"`
+	}
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return errors.Wrap(err, "failed to decode deserialization_G1 case")
+	}
+
+	publicKey, err := decodeEth2Hex(c.Input.PublicKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode public key")
+	}
+
+	v.PublicKeyValidate = append(v.PublicKeyValidate, ValidateVector{
+		Input: publicKey,
+		Valid: c.Output && !isIdentity(publicKey),
+	})
+	return nil
+}
+
+// LoadEth2DeserializationG2 appends a case of the deserialization_G2 suite to
+// v. The suite deserializes the identity successfully, but SignatureValidate
+// rejects it, so the identity is expected to be invalid.
+func (v *Vectors) LoadEth2DeserializationG2(r io.Reader) error {
+	var c struct {
+		Input struct {
+			Signature string `json:"signature"`
+		} `json:"input"`
+		Output bool `json:"output"`
+	}
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return errors.Wrap(err, "failed to decode deserialization_G2 case")
+	}
+
+	signature, err := decodeEth2Hex(c.Input.Signature)
+	if err != nil {
+		return errors.Wrap(err, "failed to decode signature")
+	}
+
+	v.SignatureValidate = append(v.SignatureValidate, ValidateVector{
+		Input: signature,
+		Valid: c.Output && !isIdentity(signature),
+	})
+	return nil
+}
+
+func decodeEth2Hex(encoded string) (HexBytes, error) {
+	return hex.DecodeString(strings.TrimPrefix(encoded, "0x"))
+}
+
+// isIdentity reports whether b is the compressed encoding of the identity:
+// the compression and infinity flags, then zeros
+func isIdentity(b []byte) bool {
+	if len(b) == 0 || b[0] != 0xc0 {
+		return false
+	}
+
+	for _, x := range b[1:] {
+		if x != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,275 @@
+// Package kat generates and runs known-answer test vectors for the BLS
+// bindings, so integrators can check that their build of libfilecoin.a
+// signs, hashes and aggregates exactly like everyone else's.
+//
+// Sign vectors are specific to Filecoin: the bindings hash messages to G2 the
+// way bls-signatures 0.3 does, which differs from the IETF hash-to-curve, so
+// IETF BLS vectors involving hashing can't pass against them. The suites
+// which don't hash, such as aggregation and point validation, can be loaded
+// from the Ethereum BLS tests with the LoadEth2 functions.
+package kat
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// HexBytes is a byte slice encoded in JSON as a hex string
+type HexBytes []byte
+
+// MarshalJSON implements json.Marshaler
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (b *HexBytes) UnmarshalJSON(data []byte) error {
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	decoded, err := hex.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	*b = decoded
+	return nil
+}
+
+// SignVector is the expected public key, digest and signature for signing a
+// message with a private key
+type SignVector struct {
+	PrivateKey HexBytes `json:"private_key"`
+	PublicKey  HexBytes `json:"public_key"`
+	Message    HexBytes `json:"message"`
+	Digest     HexBytes `json:"digest"`
+	Signature  HexBytes `json:"signature"`
+}
+
+// PublicKeyVector is the expected public key of a private key
+type PublicKeyVector struct {
+	PrivateKey HexBytes `json:"private_key"`
+	PublicKey  HexBytes `json:"public_key"`
+}
+
+// ValidateVector is whether a public key or signature is expected to pass
+// PublicKeyValidate or SignatureValidate. Inputs of the wrong length are
+// expected to be invalid.
+type ValidateVector struct {
+	Input HexBytes `json:"input"`
+	Valid bool     `json:"valid"`
+}
+
+// AggregateVector is the expected aggregate of some signatures. An empty
+// Aggregate means aggregation is expected to fail.
+type AggregateVector struct {
+	Signatures []HexBytes `json:"signatures"`
+	Aggregate  HexBytes   `json:"aggregate"`
+}
+
+// Vectors is a set of known-answer test vectors
+type Vectors struct {
+	Sign              []SignVector      `json:"sign"`
+	PublicKey         []PublicKeyVector `json:"public_key,omitempty"`
+	PublicKeyValidate []ValidateVector  `json:"public_key_validate,omitempty"`
+	SignatureValidate []ValidateVector  `json:"signature_validate,omitempty"`
+	Aggregate         []AggregateVector `json:"aggregate"`
+}
+
+// Generate derives count sign vectors deterministically from seed, along with
+// aggregate vectors over their signatures, using the bindings as the
+// reference. Only generate vectors with a build known to be correct.
+func Generate(seed ffi.PrivateKeyGenSeed, count int) *Vectors {
+	vectors := &Vectors{}
+
+	var signatures []HexBytes
+	for i := 0; i < count; i++ {
+		indexed := make([]byte, len(seed)+8)
+		copy(indexed, seed[:])
+		binary.BigEndian.PutUint64(indexed[len(seed):], uint64(i))
+
+		privateKey := ffi.PrivateKeyGenerateWithSeed(sha256.Sum256(indexed))
+		publicKey := ffi.PrivateKeyPublicKey(privateKey)
+
+		// include the empty message
+		message := ffi.Message{}
+		if i > 0 {
+			message = ffi.Message(fmt.Sprintf("filecoin-ffi known answer %d", i))
+		}
+		digest := ffi.Hash(message)
+		signature := ffi.PrivateKeySign(privateKey, message)
+
+		vectors.Sign = append(vectors.Sign, SignVector{
+			PrivateKey: privateKey[:],
+			PublicKey:  publicKey[:],
+			Message:    HexBytes(message),
+			Digest:     digest[:],
+			Signature:  signature[:],
+		})
+		signatures = append(signatures, signature[:])
+	}
+
+	for end := 1; end <= len(signatures); end *= 2 {
+		vectors.Aggregate = append(vectors.Aggregate, aggregateVector(signatures[:end]))
+	}
+	if len(signatures) > 1 {
+		vectors.Aggregate = append(vectors.Aggregate, aggregateVector(signatures))
+	}
+
+	return vectors
+}
+
+func aggregateVector(signatures []HexBytes) AggregateVector {
+	aggregate := ffi.Aggregate(toSignatures(signatures))
+
+	return AggregateVector{
+		Signatures: signatures,
+		Aggregate:  aggregate[:],
+	}
+}
+
+// Load reads vectors written by Write
+func Load(r io.Reader) (*Vectors, error) {
+	var vectors Vectors
+	if err := json.NewDecoder(r).Decode(&vectors); err != nil {
+		return nil, errors.Wrap(err, "failed to decode vectors")
+	}
+
+	return &vectors, nil
+}
+
+// Write encodes the vectors as JSON
+func (v *Vectors) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// Run checks the bindings against every vector, returning an error describing
+// the first mismatch
+func (v *Vectors) Run() error {
+	for idx, vector := range v.Sign {
+		var privateKey ffi.PrivateKey
+		var publicKey ffi.PublicKey
+		var digest ffi.Digest
+		var signature ffi.Signature
+		if err := copyExact(privateKey[:], vector.PrivateKey); err != nil {
+			return errors.Wrapf(err, "sign vector %d: private key", idx)
+		}
+		if err := copyExact(publicKey[:], vector.PublicKey); err != nil {
+			return errors.Wrapf(err, "sign vector %d: public key", idx)
+		}
+		if err := copyExact(digest[:], vector.Digest); err != nil {
+			return errors.Wrapf(err, "sign vector %d: digest", idx)
+		}
+		if err := copyExact(signature[:], vector.Signature); err != nil {
+			return errors.Wrapf(err, "sign vector %d: signature", idx)
+		}
+
+		if ffi.PrivateKeyPublicKey(privateKey) != publicKey {
+			return errors.Errorf("sign vector %d: public key mismatch", idx)
+		}
+		if ffi.Hash(ffi.Message(vector.Message)) != digest {
+			return errors.Errorf("sign vector %d: digest mismatch", idx)
+		}
+		if actual := ffi.PrivateKeySign(privateKey, ffi.Message(vector.Message)); actual == nil || *actual != signature {
+			return errors.Errorf("sign vector %d: signature mismatch", idx)
+		}
+		if !ffi.Verify(&signature, []ffi.Digest{digest}, []ffi.PublicKey{publicKey}) {
+			return errors.Errorf("sign vector %d: signature does not verify", idx)
+		}
+	}
+
+	for idx, vector := range v.PublicKey {
+		var privateKey ffi.PrivateKey
+		var publicKey ffi.PublicKey
+		if err := copyExact(privateKey[:], vector.PrivateKey); err != nil {
+			return errors.Wrapf(err, "public key vector %d: private key", idx)
+		}
+		if err := copyExact(publicKey[:], vector.PublicKey); err != nil {
+			return errors.Wrapf(err, "public key vector %d: public key", idx)
+		}
+
+		if ffi.PrivateKeyPublicKey(privateKey) != publicKey {
+			return errors.Errorf("public key vector %d: public key mismatch", idx)
+		}
+	}
+
+	for idx, vector := range v.PublicKeyValidate {
+		var publicKey ffi.PublicKey
+		valid := copyExact(publicKey[:], vector.Input) == nil && ffi.PublicKeyValidate(publicKey)
+		if valid != vector.Valid {
+			return errors.Errorf("public key validate vector %d: expected valid to be %t", idx, vector.Valid)
+		}
+	}
+
+	for idx, vector := range v.SignatureValidate {
+		var signature ffi.Signature
+		valid := copyExact(signature[:], vector.Input) == nil && ffi.SignatureValidate(&signature)
+		if valid != vector.Valid {
+			return errors.Errorf("signature validate vector %d: expected valid to be %t", idx, vector.Valid)
+		}
+	}
+
+	for idx, vector := range v.Aggregate {
+		for _, signature := range vector.Signatures {
+			if len(signature) != ffi.SignatureBytes {
+				return errors.Errorf("aggregate vector %d: signature must be %d bytes, got %d", idx, ffi.SignatureBytes, len(signature))
+			}
+		}
+
+		// Aggregate returns the identity for no signatures at all, which the
+		// IETF Aggregate (and AggregateChecked) reject, so count that as a
+		// failure
+		var actual *ffi.Signature
+		if len(vector.Signatures) > 0 {
+			actual = ffi.Aggregate(toSignatures(vector.Signatures))
+		}
+		if len(vector.Aggregate) == 0 {
+			if actual != nil {
+				return errors.Errorf("aggregate vector %d: expected aggregation to fail", idx)
+			}
+			continue
+		}
+
+		var expected ffi.Signature
+		if err := copyExact(expected[:], vector.Aggregate); err != nil {
+			return errors.Wrapf(err, "aggregate vector %d: aggregate", idx)
+		}
+
+		if actual == nil || *actual != expected {
+			return errors.Errorf("aggregate vector %d: aggregate mismatch", idx)
+		}
+	}
+
+	return nil
+}
+
+func toSignatures(raw []HexBytes) []ffi.Signature {
+	signatures := make([]ffi.Signature, len(raw))
+	for idx := range raw {
+		copy(signatures[idx][:], raw[idx])
+	}
+
+	return signatures
+}
+
+func copyExact(dst []byte, src []byte) error {
+	if len(src) != len(dst) {
+		return errors.Errorf("must be %d bytes, got %d", len(dst), len(src))
+	}
+
+	copy(dst, src)
+	return nil
+}
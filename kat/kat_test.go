@@ -0,0 +1,73 @@
+package kat
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+func TestGenerateLoadRun(t *testing.T) {
+	vectors := Generate(ffi.PrivateKeyGenSeed{42}, 5)
+	assert.Len(t, vectors.Sign, 5)
+	assert.Len(t, vectors.Aggregate, 4)
+
+	// generation is deterministic
+	assert.Equal(t, vectors, Generate(ffi.PrivateKeyGenSeed{42}, 5))
+
+	var buf bytes.Buffer
+	require.NoError(t, vectors.Write(&buf))
+
+	loaded, err := Load(&buf)
+	require.NoError(t, err)
+	assert.NoError(t, loaded.Run())
+
+	// tampering with any expected value is caught
+	loaded.Sign[3].Signature[10] ^= 1
+	assert.Error(t, loaded.Run())
+}
+
+func TestCommittedVectors(t *testing.T) {
+	// computed independently of the bindings, from the BLS12-381 generators
+	file, err := os.Open("testdata/vectors.json")
+	require.NoError(t, err)
+	defer file.Close() // nolint: errcheck
+
+	vectors, err := Load(file)
+	require.NoError(t, err)
+	assert.NotEmpty(t, vectors.PublicKey)
+	assert.NotEmpty(t, vectors.PublicKeyValidate)
+	assert.NotEmpty(t, vectors.SignatureValidate)
+	assert.NotEmpty(t, vectors.Aggregate)
+	assert.NoError(t, vectors.Run())
+}
+
+func TestLoadEth2(t *testing.T) {
+	var vectors Vectors
+
+	// the generator of G2, doubled
+	require.NoError(t, vectors.LoadEth2Aggregate(strings.NewReader(`{
+		"input": ["0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8", "0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"],
+		"output": "0xaa4edef9c1ed7f729f520e47730a124fd70662a904ba1074728114d1031e1572c6c886f6b57ec72a6178288c47c335771638533957d540a9d2370f17cc7ed5863bc0b995b8825e0ee1ea1e1e4d00dbae81f14b0bf3611b78c952aacab827a053"
+	}`)))
+	require.NoError(t, vectors.LoadEth2Aggregate(strings.NewReader(`{"input": [], "output": null}`)))
+
+	// the identity deserializes, but isn't valid
+	require.NoError(t, vectors.LoadEth2DeserializationG1(strings.NewReader(`{"input": {"pubkey": "0x97f1d3a73197d7942695638c4fa9ac0fc3688c4f9774b905a14e3a3f171bac586c55e83ff97a1aeffb3af00adb22c6bb"}, "output": true}`)))
+	require.NoError(t, vectors.LoadEth2DeserializationG1(strings.NewReader(`{"input": {"pubkey": "0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"}, "output": true}`)))
+	require.NoError(t, vectors.LoadEth2DeserializationG2(strings.NewReader(`{"input": {"signature": "0xc00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"}, "output": true}`)))
+	require.NoError(t, vectors.LoadEth2DeserializationG2(strings.NewReader(`{"input": {"signature": "0x93e02b6052719f607dacd3a088274f65596bd0d09920b61ab5da61bbdc7f5049334cf11213945d57e5ac7d055d042b7e024aa2b2f08f0a91260805272dc51051c6e47ad4fa403b02b4510b647ae3d1770bac0326a805bbefd48056c8c121bdb8"}, "output": true}`)))
+
+	assert.Equal(t, []ValidateVector{{Input: vectors.PublicKeyValidate[0].Input, Valid: true}, {Input: vectors.PublicKeyValidate[1].Input, Valid: false}}, vectors.PublicKeyValidate)
+	assert.False(t, vectors.SignatureValidate[0].Valid)
+	assert.Empty(t, vectors.Aggregate[1].Aggregate)
+	assert.NoError(t, vectors.Run())
+
+	// malformed cases are rejected
+	assert.Error(t, vectors.LoadEth2Aggregate(strings.NewReader(`{"input": ["0xzz"], "output": null}`)))
+}
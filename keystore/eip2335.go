@@ -0,0 +1,290 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// ErrInvalidPassword is returned when an EIP-2335 keystore's checksum doesn't
+// match the password
+var ErrInvalidPassword = errors.New("invalid password")
+
+// scrypt parameters recommended by EIP-2335
+const (
+	scryptN     = 1 << 18
+	scryptR     = 8
+	scryptP     = 1
+	scryptDKLen = 32
+)
+
+// EIP2335Keystore is the JSON layout of an EIP-2335 (version 4) keystore
+type EIP2335Keystore struct {
+	Crypto      EIP2335Crypto `json:"crypto"`
+	Description string        `json:"description"`
+	Pubkey      string        `json:"pubkey"`
+	Path        string        `json:"path"`
+	UUID        string        `json:"uuid"`
+	Version     int           `json:"version"`
+}
+
+// EIP2335Crypto holds the key derivation, checksum and cipher modules
+type EIP2335Crypto struct {
+	KDF      EIP2335Module `json:"kdf"`
+	Checksum EIP2335Module `json:"checksum"`
+	Cipher   EIP2335Module `json:"cipher"`
+}
+
+// EIP2335Module is one of the crypto modules of an EIP-2335 keystore
+type EIP2335Module struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+// EncryptEIP2335 encrypts a private key into an EIP-2335 keystore using scrypt
+// and AES-128-CTR.
+//
+// EIP-2335 stores the secret as a big-endian integer, whereas PrivateKey is
+// little-endian, so the bytes are reversed on the way in and out.
+func EncryptEIP2335(privateKey ffi.PrivateKey, password string) ([]byte, error) {
+	salt := make([]byte, 32)
+	iv := make([]byte, aes.BlockSize)
+	id := make([]byte, 16)
+	for _, buf := range [][]byte{salt, iv, id} {
+		if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+			return nil, err
+		}
+	}
+
+	derived, err := scrypt.Key(processPassword(password), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	cipherMessage, err := aes128CTR(derived[:16], iv, reversed(privateKey[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := ffi.PrivateKeyPublicKey(privateKey)
+
+	// random (version 4) UUID
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+
+	return json.Marshal(EIP2335Keystore{
+		Crypto: EIP2335Crypto{
+			KDF: EIP2335Module{
+				Function: "scrypt",
+				Params: map[string]interface{}{
+					"dklen": scryptDKLen,
+					"n":     scryptN,
+					"r":     scryptR,
+					"p":     scryptP,
+					"salt":  hex.EncodeToString(salt),
+				},
+			},
+			Checksum: EIP2335Module{
+				Function: "sha256",
+				Params:   map[string]interface{}{},
+				Message:  hex.EncodeToString(checksum(derived, cipherMessage)),
+			},
+			Cipher: EIP2335Module{
+				Function: "aes-128-ctr",
+				Params:   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				Message:  hex.EncodeToString(cipherMessage),
+			},
+		},
+		Pubkey:  hex.EncodeToString(publicKey[:]),
+		UUID:    fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:]),
+		Version: 4,
+	})
+}
+
+// DecryptEIP2335 decrypts the private key in an EIP-2335 keystore, which may
+// use either the scrypt or the pbkdf2 key derivation function
+func DecryptEIP2335(encoded []byte, password string) (ffi.PrivateKey, error) {
+	var keystore EIP2335Keystore
+	if err := json.Unmarshal(encoded, &keystore); err != nil {
+		return ffi.PrivateKey{}, errors.Wrap(err, "failed to decode keystore")
+	}
+
+	if keystore.Version != 4 {
+		return ffi.PrivateKey{}, errors.Errorf("unsupported keystore version %d", keystore.Version)
+	}
+
+	derived, err := deriveKey(keystore.Crypto.KDF, processPassword(password))
+	if err != nil {
+		return ffi.PrivateKey{}, err
+	}
+	if len(derived) < 32 {
+		return ffi.PrivateKey{}, errors.Errorf("derived key too short: %d bytes", len(derived))
+	}
+
+	cipherMessage, err := hex.DecodeString(keystore.Crypto.Cipher.Message)
+	if err != nil {
+		return ffi.PrivateKey{}, errors.Wrap(err, "failed to decode cipher message")
+	}
+
+	if keystore.Crypto.Checksum.Function != "sha256" {
+		return ffi.PrivateKey{}, errors.Errorf("unsupported checksum function %q", keystore.Crypto.Checksum.Function)
+	}
+	expected, err := hex.DecodeString(keystore.Crypto.Checksum.Message)
+	if err != nil {
+		return ffi.PrivateKey{}, errors.Wrap(err, "failed to decode checksum")
+	}
+	if subtle.ConstantTimeCompare(expected, checksum(derived, cipherMessage)) != 1 {
+		return ffi.PrivateKey{}, ErrInvalidPassword
+	}
+
+	if keystore.Crypto.Cipher.Function != "aes-128-ctr" {
+		return ffi.PrivateKey{}, errors.Errorf("unsupported cipher function %q", keystore.Crypto.Cipher.Function)
+	}
+	iv, err := hexParam(keystore.Crypto.Cipher.Params, "iv")
+	if err != nil {
+		return ffi.PrivateKey{}, err
+	}
+
+	secret, err := aes128CTR(derived[:16], iv, cipherMessage)
+	if err != nil {
+		return ffi.PrivateKey{}, err
+	}
+
+	privateKey, err := privateKeyFromBytes(reversed(secret))
+	if err != nil {
+		return ffi.PrivateKey{}, err
+	}
+
+	if keystore.Pubkey != "" {
+		publicKey := ffi.PrivateKeyPublicKey(privateKey)
+		if keystore.Pubkey != hex.EncodeToString(publicKey[:]) {
+			return ffi.PrivateKey{}, errors.New("decrypted key does not match keystore pubkey")
+		}
+	}
+
+	return privateKey, nil
+}
+
+func deriveKey(kdf EIP2335Module, password []byte) ([]byte, error) {
+	salt, err := hexParam(kdf.Params, "salt")
+	if err != nil {
+		return nil, err
+	}
+
+	dkLen, err := intParam(kdf.Params, "dklen")
+	if err != nil {
+		return nil, err
+	}
+
+	switch kdf.Function {
+	case "scrypt":
+		n, err := intParam(kdf.Params, "n")
+		if err != nil {
+			return nil, err
+		}
+		r, err := intParam(kdf.Params, "r")
+		if err != nil {
+			return nil, err
+		}
+		p, err := intParam(kdf.Params, "p")
+		if err != nil {
+			return nil, err
+		}
+
+		return scrypt.Key(password, salt, n, r, p, dkLen)
+	case "pbkdf2":
+		if prf, _ := kdf.Params["prf"].(string); prf != "hmac-sha256" {
+			return nil, errors.Errorf("unsupported pbkdf2 prf %q", prf)
+		}
+		c, err := intParam(kdf.Params, "c")
+		if err != nil {
+			return nil, err
+		}
+
+		return pbkdf2.Key(password, salt, c, dkLen, sha256.New), nil
+	default:
+		return nil, errors.Errorf("unsupported kdf function %q", kdf.Function)
+	}
+}
+
+// processPassword applies the NFKD normalization and control code stripping
+// EIP-2335 requires before key derivation.
+func processPassword(password string) []byte {
+	var processed bytes.Buffer
+	for _, r := range norm.NFKD.String(password) {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			continue
+		}
+		processed.WriteRune(r)
+	}
+
+	return processed.Bytes()
+}
+
+func checksum(derived []byte, cipherMessage []byte) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, derived[16:32]...), cipherMessage...))
+	return sum[:]
+}
+
+func aes128CTR(key []byte, iv []byte, src []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, errors.Errorf("invalid iv length %d", len(iv))
+	}
+
+	dst := make([]byte, len(src))
+	cipher.NewCTR(block, iv).XORKeyStream(dst, src)
+
+	return dst, nil
+}
+
+func hexParam(params map[string]interface{}, name string) ([]byte, error) {
+	value, ok := params[name].(string)
+	if !ok {
+		return nil, errors.Errorf("missing %s parameter", name)
+	}
+
+	decoded, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s parameter", name)
+	}
+
+	return decoded, nil
+}
+
+func intParam(params map[string]interface{}, name string) (int, error) {
+	// encoding/json decodes every number into a float64
+	value, ok := params[name].(float64)
+	if !ok || value <= 0 || value != float64(int(value)) {
+		return 0, errors.Errorf("missing or invalid %s parameter", name)
+	}
+
+	return int(value), nil
+}
+
+func reversed(src []byte) []byte {
+	dst := make([]byte, len(src))
+	for i := range src {
+		dst[len(src)-1-i] = src[i]
+	}
+
+	return dst
+}
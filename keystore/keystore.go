@@ -0,0 +1,78 @@
+// Package keystore imports and exports BLS private keys in the formats used
+// by Lotus wallets and by EIP-2335 encrypted keystores. Every imported key is
+// checked with ffi.PrivateKeyValidate, so a successfully imported key is
+// always a valid scalar.
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// KeyTypeBLS is the Lotus key type of BLS keys
+const KeyTypeBLS = "bls"
+
+// ErrInvalidKey is returned when imported key material isn't a valid BLS
+// private key
+var ErrInvalidKey = errors.New("invalid BLS private key")
+
+// KeyInfo is the JSON representation of a key in a Lotus keystore, and in
+// the output of `lotus wallet export` once hex decoded
+type KeyInfo struct {
+	Type       string
+	PrivateKey []byte
+}
+
+// ExportLotus encodes a private key the way `lotus wallet export` does: the
+// hex encoding of its KeyInfo JSON. Lotus doesn't encrypt exported keys.
+func ExportLotus(privateKey ffi.PrivateKey) (string, error) {
+	encoded, err := json.Marshal(KeyInfo{Type: KeyTypeBLS, PrivateKey: privateKey[:]})
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(encoded), nil
+}
+
+// ImportLotus decodes a private key exported by `lotus wallet export`
+func ImportLotus(exported string) (ffi.PrivateKey, error) {
+	encoded, err := hex.DecodeString(exported)
+	if err != nil {
+		return ffi.PrivateKey{}, errors.Wrap(err, "failed to decode hex")
+	}
+
+	var keyInfo KeyInfo
+	if err := json.Unmarshal(encoded, &keyInfo); err != nil {
+		return ffi.PrivateKey{}, errors.Wrap(err, "failed to decode key info")
+	}
+
+	return FromKeyInfo(keyInfo)
+}
+
+// FromKeyInfo extracts the private key from a Lotus KeyInfo
+func FromKeyInfo(keyInfo KeyInfo) (ffi.PrivateKey, error) {
+	if keyInfo.Type != KeyTypeBLS {
+		return ffi.PrivateKey{}, errors.Errorf("unsupported key type %q", keyInfo.Type)
+	}
+
+	return privateKeyFromBytes(keyInfo.PrivateKey)
+}
+
+func privateKeyFromBytes(raw []byte) (ffi.PrivateKey, error) {
+	var privateKey ffi.PrivateKey
+	if len(raw) != len(privateKey) {
+		return ffi.PrivateKey{}, errors.Wrapf(ErrInvalidKey, "length %d, expected %d", len(raw), len(privateKey))
+	}
+
+	copy(privateKey[:], raw)
+
+	if !ffi.PrivateKeyValidate(privateKey) {
+		return ffi.PrivateKey{}, ErrInvalidKey
+	}
+
+	return privateKey, nil
+}
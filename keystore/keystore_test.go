@@ -0,0 +1,134 @@
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+func TestLotusRoundTrip(t *testing.T) {
+	privateKey := ffi.PrivateKeyGenerate()
+
+	exported, err := ExportLotus(privateKey)
+	require.NoError(t, err)
+
+	imported, err := ImportLotus(exported)
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, imported)
+}
+
+func TestLotusInvalid(t *testing.T) {
+	exportKeyInfo := func(keyInfo KeyInfo) string {
+		encoded, err := json.Marshal(keyInfo)
+		require.NoError(t, err)
+		return hex.EncodeToString(encoded)
+	}
+
+	_, err := ImportLotus("not hex")
+	assert.Error(t, err)
+
+	_, err = ImportLotus(exportKeyInfo(KeyInfo{Type: "secp256k1", PrivateKey: make([]byte, 32)}))
+	assert.Error(t, err)
+
+	_, err = ImportLotus(exportKeyInfo(KeyInfo{Type: KeyTypeBLS, PrivateKey: make([]byte, 31)}))
+	assert.Error(t, err)
+
+	// the zero scalar is the right length but not a valid key
+	_, err = ImportLotus(exportKeyInfo(KeyInfo{Type: KeyTypeBLS, PrivateKey: make([]byte, 32)}))
+	assert.Equal(t, ErrInvalidKey, err)
+}
+
+func TestEIP2335RoundTrip(t *testing.T) {
+	privateKey := ffi.PrivateKeyGenerate()
+
+	encrypted, err := EncryptEIP2335(privateKey, "correct horse battery staple")
+	require.NoError(t, err)
+
+	decrypted, err := DecryptEIP2335(encrypted, "correct horse battery staple")
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, decrypted)
+
+	_, err = DecryptEIP2335(encrypted, "incorrect horse battery staple")
+	assert.Equal(t, ErrInvalidPassword, err)
+}
+
+// Test vectors from EIP-2335
+func TestEIP2335Vectors(t *testing.T) {
+	password := "\U0001d531\U0001d522\U0001d530\U0001d531\U0001d52d\U0001d51e\U0001d530\U0001d530\U0001d534\U0001d52c\U0001d52f\U0001d521\U0001f511"
+	secret, err := hex.DecodeString("000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f")
+	require.NoError(t, err)
+
+	var expected ffi.PrivateKey
+	copy(expected[:], reversed(secret))
+
+	for name, vector := range map[string]string{
+		"scrypt": `{
+			"crypto": {
+				"kdf": {"function": "scrypt", "params": {"dklen": 32, "n": 262144, "p": 1, "r": 8, "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"}, "message": ""},
+				"checksum": {"function": "sha256", "params": {}, "message": "d2217fe5f3e9a1e34581ef8a78f7c9928e436d36dacc5e846690a5581e8ea484"},
+				"cipher": {"function": "aes-128-ctr", "params": {"iv": "264daa3f303d7259501c93d997d84fe6"}, "message": "06ae90d55fe0a6e9c5c3bc5b170827b2e5cce3929ed3f116c2811e6366dfe20f"}
+			},
+			"path": "m/12381/60/3141592653/589793238",
+			"uuid": "1d85ae20-35c5-4611-98e8-aa14a633906f",
+			"version": 4
+		}`,
+		"pbkdf2": `{
+			"crypto": {
+				"kdf": {"function": "pbkdf2", "params": {"dklen": 32, "c": 262144, "prf": "hmac-sha256", "salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"}, "message": ""},
+				"checksum": {"function": "sha256", "params": {}, "message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"},
+				"cipher": {"function": "aes-128-ctr", "params": {"iv": "264daa3f303d7259501c93d997d84fe6"}, "message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"}
+			},
+			"path": "m/12381/60/0/0",
+			"uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+			"version": 4
+		}`,
+	} {
+		decrypted, err := DecryptEIP2335([]byte(vector), password)
+		require.NoError(t, err, name)
+		assert.Equal(t, expected, decrypted, name)
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	mnemonic, err := NewMnemonic()
+	require.NoError(t, err)
+	assert.Len(t, strings.Fields(mnemonic), 24)
+
+	privateKey, err := MnemonicToPrivateKey(mnemonic, "passphrase")
+	require.NoError(t, err)
+
+	again, err := MnemonicToPrivateKey(mnemonic, "passphrase")
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, again)
+
+	other, err := MnemonicToPrivateKey(mnemonic, "other passphrase")
+	require.NoError(t, err)
+	assert.NotEqual(t, privateKey, other)
+
+	_, err = MnemonicToPrivateKey("abandon abandon abandon", "")
+	assert.Equal(t, ErrInvalidMnemonic, err)
+
+	_, err = SeedToPrivateKey(make([]byte, 31))
+	assert.Error(t, err)
+}
+
+// Test vector 0 of EIP-2333, whose seed is the BIP-39 seed of this mnemonic
+func TestMnemonicVector(t *testing.T) {
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	secret, ok := new(big.Int).SetString("6083874454709270928345386274498605044986640685124978867557563392430687146096", 10)
+	require.True(t, ok)
+
+	var expected ffi.PrivateKey
+	copy(expected[:], reversed(secret.Bytes()))
+
+	privateKey, err := MnemonicToPrivateKey(mnemonic, "TREZOR")
+	require.NoError(t, err)
+	assert.Equal(t, expected, privateKey)
+}
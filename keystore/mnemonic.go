@@ -0,0 +1,90 @@
+package keystore
+
+import (
+	"crypto/sha256"
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/hkdf"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// ErrInvalidMnemonic is returned for a mnemonic with unknown words or a bad
+// checksum
+var ErrInvalidMnemonic = errors.New("invalid mnemonic")
+
+// mnemonicEntropyBits is the entropy of a generated mnemonic, giving 24 words
+const mnemonicEntropyBits = 256
+
+// blsCurveOrder is the order r of the BLS12-381 scalar field
+var blsCurveOrder, _ = new(big.Int).SetString("73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001", 16)
+
+// NewMnemonic generates a random 24-word BIP-39 mnemonic, from which
+// MnemonicToPrivateKey derives a private key.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to generate entropy")
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+// MnemonicToPrivateKey derives the private key backed up by a BIP-39 mnemonic
+// and passphrase. The mnemonic's BIP-39 seed is turned into a key as an
+// EIP-2333 master key, so other wallets derive the same key from the same
+// words.
+func MnemonicToPrivateKey(mnemonic string, passphrase string) (ffi.PrivateKey, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return ffi.PrivateKey{}, ErrInvalidMnemonic
+	}
+
+	return SeedToPrivateKey(seed)
+}
+
+// SeedToPrivateKey derives the EIP-2333 master private key of a seed of at
+// least 32 bytes.
+func SeedToPrivateKey(seed []byte) (ffi.PrivateKey, error) {
+	if len(seed) < 32 {
+		return ffi.PrivateKey{}, errors.Errorf("seed must be at least 32 bytes, got %d", len(seed))
+	}
+
+	secret, err := hkdfModR(seed)
+	if err != nil {
+		return ffi.PrivateKey{}, err
+	}
+
+	// private keys are little-endian, EIP-2333 secrets big-endian
+	return privateKeyFromBytes(reversed(secret))
+}
+
+// hkdfModR is EIP-2333's HKDF_mod_r with an empty key_info, returning the
+// secret as 32 big-endian bytes.
+func hkdfModR(ikm []byte) ([]byte, error) {
+	const okmLen = 48
+
+	salt := []byte("BLS-SIG-KEYGEN-SALT-")
+	secret := new(big.Int)
+	for secret.Sign() == 0 {
+		hashed := sha256.Sum256(salt)
+		salt = hashed[:]
+
+		okm := make([]byte, okmLen)
+		reader := hkdf.New(sha256.New, append(append([]byte{}, ikm...), 0), salt, []byte{0, okmLen})
+		if _, err := io.ReadFull(reader, okm); err != nil {
+			return nil, errors.Wrap(err, "failed to expand key material")
+		}
+
+		secret.Mod(new(big.Int).SetBytes(okm), blsCurveOrder)
+	}
+
+	raw := make([]byte, 32)
+	secretBytes := secret.Bytes()
+	copy(raw[len(raw)-len(secretBytes):], secretBytes)
+
+	return raw, nil
+}
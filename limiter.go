@@ -0,0 +1,208 @@
+package ffi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Resource is a class of machine resource proof operations compete for
+type Resource string
+
+// Resource classes. Units are up to the policy, e.g. cores for ResourceCPU
+// and bytes for ResourceMemory.
+const (
+	ResourceCPU    Resource = "cpu"
+	ResourceMemory Resource = "memory"
+	ResourceGPU    Resource = "gpu"
+)
+
+// LimiterPolicy says how much of each resource the machine has, and how much
+// each operation, named by an Op* constant, holds while it runs. Resources
+// without a capacity are unlimited, and operations without demands aren't
+// limited.
+type LimiterPolicy struct {
+	Capacity map[Resource]int64
+	Demands  map[string]map[Resource]int64
+}
+
+// LimiterStats is a snapshot of a Limiter's queue
+type LimiterStats struct {
+	Capacity map[Resource]int64
+	InUse    map[Resource]int64
+
+	// Waiting is the number of operations queued for resources
+	Waiting int
+}
+
+// Limiter queues operations until the resources they need are free, so that
+// concurrent calls don't oversubscribe the machine. Operations are granted
+// resources in the order they asked for them.
+type Limiter struct {
+	policy LimiterPolicy
+
+	lock    sync.Mutex
+	inUse   map[Resource]int64
+	waiters []*limiterWaiter
+}
+
+type limiterWaiter struct {
+	demand  map[Resource]int64
+	ready   chan struct{}
+	granted bool
+}
+
+// NewLimiter returns a Limiter enforcing policy. Returns an error if an
+// operation demands more of a resource than the machine has, as it could
+// never run.
+func NewLimiter(policy LimiterPolicy) (*Limiter, error) {
+	for operation, demand := range policy.Demands {
+		for resource, amount := range demand {
+			if amount < 0 {
+				return nil, errors.Errorf("%s demands a negative amount of %s", operation, resource)
+			}
+
+			capacity, limited := policy.Capacity[resource]
+			if limited && amount > capacity {
+				return nil, errors.Errorf("%s demands %d %s, more than the capacity of %d", operation, amount, resource, capacity)
+			}
+		}
+	}
+
+	return &Limiter{
+		policy: policy,
+		inUse:  make(map[Resource]int64),
+	}, nil
+}
+
+// Acquire waits until the resources operation needs are free and takes them,
+// returning the function giving them back. Returns ctx.Err() if ctx is done
+// first, having taken nothing.
+func (l *Limiter) Acquire(ctx context.Context, operation string) (release func(), err error) {
+	demand := l.policy.Demands[operation]
+	if len(demand) == 0 {
+		return func() {}, nil
+	}
+
+	release = func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		l.give(demand)
+	}
+
+	l.lock.Lock()
+	if len(l.waiters) == 0 && l.fits(demand) {
+		l.take(demand)
+		l.lock.Unlock()
+		return release, nil
+	}
+
+	waiter := &limiterWaiter{demand: demand, ready: make(chan struct{})}
+	l.waiters = append(l.waiters, waiter)
+	l.lock.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return release, nil
+	case <-ctx.Done():
+		l.lock.Lock()
+		defer l.lock.Unlock()
+
+		if waiter.granted {
+			l.give(demand)
+			return nil, ctx.Err()
+		}
+
+		for i, w := range l.waiters {
+			if w == waiter {
+				l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+				break
+			}
+		}
+
+		// whoever queued behind this operation may fit now
+		l.grant()
+		return nil, ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of the resources in use and the operations queued
+func (l *Limiter) Stats() LimiterStats {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	stats := LimiterStats{
+		Capacity: make(map[Resource]int64),
+		InUse:    make(map[Resource]int64),
+		Waiting:  len(l.waiters),
+	}
+	for resource, capacity := range l.policy.Capacity {
+		stats.Capacity[resource] = capacity
+	}
+	for resource, amount := range l.inUse {
+		stats.InUse[resource] = amount
+	}
+
+	return stats
+}
+
+// fits, take, give and grant must be called with the lock held
+
+func (l *Limiter) fits(demand map[Resource]int64) bool {
+	for resource, amount := range demand {
+		capacity, limited := l.policy.Capacity[resource]
+		if limited && l.inUse[resource]+amount > capacity {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (l *Limiter) take(demand map[Resource]int64) {
+	for resource, amount := range demand {
+		l.inUse[resource] += amount
+	}
+}
+
+func (l *Limiter) give(demand map[Resource]int64) {
+	for resource, amount := range demand {
+		l.inUse[resource] -= amount
+	}
+
+	l.grant()
+}
+
+// grant hands resources to queued operations, in order, for as long as the
+// first one in the queue fits
+func (l *Limiter) grant() {
+	for len(l.waiters) > 0 && l.fits(l.waiters[0].demand) {
+		waiter := l.waiters[0]
+		l.waiters = l.waiters[1:]
+
+		l.take(waiter.demand)
+		waiter.granted = true
+		close(waiter.ready)
+	}
+}
+
+var currentLimiter hook
+
+// SetLimiter makes every *WithContext operation wait for its resources from l
+// before starting. Resources are held until the Rust call returns, even if
+// the operation was abandoned, since it's still running. A nil l stops
+// limiting.
+func SetLimiter(l *Limiter) {
+	currentLimiter.Store(l)
+}
+
+func acquireResources(ctx context.Context, operation string) (func(), error) {
+	l, _ := currentLimiter.Load().(*Limiter)
+	if l == nil {
+		return func() {}, nil
+	}
+
+	return l.Acquire(ctx, operation)
+}
@@ -0,0 +1,66 @@
+package ffi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter, err := NewLimiter(LimiterPolicy{
+		Capacity: map[Resource]int64{ResourceCPU: 4, ResourceMemory: 100},
+		Demands: map[string]map[Resource]int64{
+			OpSealPreCommit: {ResourceCPU: 1, ResourceMemory: 60},
+			OpSealCommit:    {ResourceCPU: 4},
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	releasePreCommit, err := limiter.Acquire(ctx, OpSealPreCommit)
+	require.NoError(t, err)
+	assert.Equal(t, map[Resource]int64{ResourceCPU: 1, ResourceMemory: 60}, limiter.Stats().InUse)
+
+	// unlimited operations don't wait
+	releaseUnseal, err := limiter.Acquire(ctx, OpUnseal)
+	require.NoError(t, err)
+	releaseUnseal()
+
+	// a second pre-commit doesn't fit in memory, and queues
+	granted := make(chan func())
+	go func() {
+		release, err := limiter.Acquire(ctx, OpSealPreCommit)
+		assert.NoError(t, err)
+		granted <- release
+	}()
+
+	for limiter.Stats().Waiting != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// operations behind it wait their turn, until they give up
+	cancelled, cancel := context.WithCancel(ctx)
+	go cancel()
+	_, err = limiter.Acquire(cancelled, OpSealCommit)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, limiter.Stats().Waiting)
+
+	releasePreCommit()
+	release := <-granted
+	assert.Equal(t, 0, limiter.Stats().Waiting)
+	assert.Equal(t, int64(1), limiter.Stats().InUse[ResourceCPU])
+
+	release()
+	assert.Equal(t, int64(0), limiter.Stats().InUse[ResourceCPU])
+
+	// demands must fit the machine
+	_, err = NewLimiter(LimiterPolicy{
+		Capacity: map[Resource]int64{ResourceGPU: 1},
+		Demands:  map[string]map[Resource]int64{OpGeneratePoSt: {ResourceGPU: 2}},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package ffi
+
+import "time"
+
+// Operations reported to Metrics
+const (
+	OpSealPreCommit           = "seal_pre_commit"
+	OpSealCommit              = "seal_commit"
+	OpUnseal                  = "unseal"
+	OpUnsealRange             = "unseal_range"
+	OpVerifySeal              = "verify_seal"
+	OpVerifySealBatch         = "verify_seal_batch"
+	OpGeneratePieceCommitment = "generate_piece_commitment"
+	OpGenerateCandidates      = "generate_candidates"
+	OpGeneratePoSt            = "generate_post"
+	OpVerifyPoSt              = "verify_post"
+	OpVerifyPoStBatch         = "verify_post_batch"
+)
+
+// Metrics receives measurements of proof operations, named by the Op*
+// constants, so they can be exported to Prometheus or the like. Counts of
+// operations are those of the duration observations. Methods are called
+// concurrently and must not block.
+type Metrics interface {
+	// ObserveDuration records how long an operation took to return, whether
+	// it succeeded or not
+	ObserveDuration(operation string, duration time.Duration)
+
+	// ObserveProofSize records the length of a proof an operation produced
+	ObserveProofSize(operation string, size int)
+}
+
+var currentMetrics hook
+
+// SetMetrics makes m receive the measurements of every proof operation from
+// now on. A nil m stops them.
+func SetMetrics(m Metrics) {
+	currentMetrics.Store(m)
+}
+
+func getMetrics() Metrics {
+	m, _ := currentMetrics.Load().(Metrics)
+	return m
+}
+
+// observeDuration reports the time since start, and is meant to be deferred
+// as the operation begins
+func observeDuration(operation string, start time.Time) {
+	if m := getMetrics(); m != nil {
+		m.ObserveDuration(operation, time.Since(start))
+	}
+}
+
+func observeProofSize(operation string, proof []byte) {
+	if m := getMetrics(); m != nil {
+		m.ObserveProofSize(operation, len(proof))
+	}
+}
@@ -0,0 +1,63 @@
+package ffi
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingMetrics struct {
+	lock       sync.Mutex
+	durations  map[string]int
+	proofSizes map[string][]int
+}
+
+func (r *recordingMetrics) ObserveDuration(operation string, duration time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.durations[operation]++
+}
+
+func (r *recordingMetrics) ObserveProofSize(operation string, size int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.proofSizes[operation] = append(r.proofSizes[operation], size)
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &recordingMetrics{
+		durations:  make(map[string]int),
+		proofSizes: make(map[string][]int),
+	}
+
+	SetMetrics(metrics)
+	defer SetMetrics(nil)
+
+	_, err := VerifySealBatch([]SealVerifyInfo{})
+	assert.NoError(t, err)
+
+	_, err = GeneratePieceCommitmentFromReader(bytes.NewReader(make([]byte, 127)), 127)
+	assert.NoError(t, err)
+
+	// failures are measured too
+	_, err = GeneratePieceCommitmentFromReader(bytes.NewReader(make([]byte, 100)), 127)
+	assert.Error(t, err)
+
+	// a piece hashed in chunks is still one piece
+	_, err = GeneratePieceCommitmentParallel(bytes.NewReader(make([]byte, 1016)), 1016, 127, 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, metrics.durations[OpVerifySealBatch])
+	assert.Equal(t, 3, metrics.durations[OpGeneratePieceCommitment])
+	assert.Empty(t, metrics.proofSizes)
+
+	SetMetrics(nil)
+	_, err = VerifySealBatch([]SealVerifyInfo{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, metrics.durations[OpVerifySealBatch])
+}
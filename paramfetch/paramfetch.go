@@ -0,0 +1,273 @@
+// Package paramfetch downloads and verifies the Groth16 parameter files the
+// proofs library reads from its parameter cache.
+package paramfetch
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/blake2b"
+)
+
+// DefaultGateway is the IPFS gateway parameter files are downloaded from
+const DefaultGateway = "https://proofs.filecoin.io/ipfs/"
+
+// defaultCacheDir is where the proofs library looks for parameters when
+// FIL_PROOFS_PARAMETER_CACHE isn't set
+const defaultCacheDir = "/var/tmp/filecoin-proof-parameters/"
+
+// ErrDigestMismatch is returned for a parameter file whose digest doesn't
+// match its manifest entry
+var ErrDigestMismatch = errors.New("parameter file digest does not match manifest")
+
+// ParamInfo describes a parameter file, as listed in parameters.json
+type ParamInfo struct {
+	Cid        string `json:"cid"`
+	Digest     string `json:"digest"`
+	SectorSize uint64 `json:"sector_size"`
+}
+
+// Manifest maps parameter file names to their descriptions
+type Manifest map[string]ParamInfo
+
+// LoadManifest reads a manifest in the parameters.json format
+func LoadManifest(r io.Reader) (Manifest, error) {
+	var manifest Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, errors.Wrap(err, "failed to decode parameter manifest")
+	}
+
+	return manifest, nil
+}
+
+// Progress reports how much of a file has been downloaded. Total is -1 if the
+// gateway didn't say how large the file is.
+type Progress struct {
+	File       string
+	Downloaded int64
+	Total      int64
+}
+
+// Fetcher downloads parameter files into a cache directory
+type Fetcher struct {
+	// CacheDir is the directory files are stored in
+	CacheDir string
+
+	// Gateway is the URL prefix a file's CID is appended to
+	Gateway string
+
+	// Client makes the requests
+	Client *http.Client
+
+	// OnProgress, if set, is called as files are downloaded
+	OnProgress func(Progress)
+}
+
+// DefaultCacheDir returns the parameter cache the proofs library uses:
+// FIL_PROOFS_PARAMETER_CACHE if it's set, and /var/tmp otherwise
+func DefaultCacheDir() string {
+	if dir := os.Getenv("FIL_PROOFS_PARAMETER_CACHE"); dir != "" {
+		return dir
+	}
+
+	return defaultCacheDir
+}
+
+// NewFetcher creates a Fetcher storing files in cacheDir and downloading them
+// from DefaultGateway
+func NewFetcher(cacheDir string) *Fetcher {
+	return &Fetcher{
+		CacheDir: cacheDir,
+		Gateway:  DefaultGateway,
+		Client:   http.DefaultClient,
+	}
+}
+
+// Fetch makes sure the cache holds a verified copy of the files in manifest
+// needed for sectorSizes: every verifying key, and the parameters of the given
+// sector sizes only. With no sector sizes, every file is fetched. Files which
+// are already present and verify are left alone.
+func (f *Fetcher) Fetch(ctx context.Context, manifest Manifest, sectorSizes ...uint64) error {
+	return f.fetch(ctx, manifest, selectFiles(manifest, sectorSizes))
+}
+
+// FetchVerifyingKeys is Fetch for verifying keys only, of the given sector
+// sizes or, with none, of every size. Verifying seals and PoSts only reads
+// verifying keys, so a node which never proves doesn't need the much larger
+// parameter files.
+func (f *Fetcher) FetchVerifyingKeys(ctx context.Context, manifest Manifest, sectorSizes ...uint64) error {
+	wanted := map[uint64]bool{}
+	for _, sectorSize := range sectorSizes {
+		wanted[sectorSize] = true
+	}
+
+	var names []string
+	for _, name := range selectFiles(manifest, nil) {
+		if strings.HasSuffix(name, ".vk") && (len(wanted) == 0 || wanted[manifest[name].SectorSize]) {
+			names = append(names, name)
+		}
+	}
+
+	return f.fetch(ctx, manifest, names)
+}
+
+func (f *Fetcher) fetch(ctx context.Context, manifest Manifest, names []string) error {
+	if err := os.MkdirAll(f.CacheDir, 0755); err != nil {
+		return errors.Wrap(err, "failed to create parameter cache")
+	}
+
+	for _, name := range names {
+		info := manifest[name]
+
+		err := f.Verify(name, info)
+		if err == nil {
+			continue
+		}
+		if err != ErrDigestMismatch && !os.IsNotExist(errors.Cause(err)) {
+			return err
+		}
+
+		if err := f.download(ctx, name, info); err != nil {
+			return errors.Wrapf(err, "failed to fetch %s", name)
+		}
+	}
+
+	return nil
+}
+
+// FetchInBackground runs Fetch in a goroutine. The returned channel receives
+// its result once it's done.
+func (f *Fetcher) FetchInBackground(ctx context.Context, manifest Manifest, sectorSizes ...uint64) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- f.Fetch(ctx, manifest, sectorSizes...)
+	}()
+
+	return done
+}
+
+// Verify checks the cached copy of a file against its manifest entry. Returns
+// ErrDigestMismatch if the file is corrupt.
+func (f *Fetcher) Verify(name string, info ParamInfo) error {
+	file, err := os.Open(filepath.Join(f.CacheDir, name))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s", name)
+	}
+	defer file.Close() // nolint: errcheck
+
+	digest, err := fileDigest(file)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", name)
+	}
+
+	if digest != info.Digest {
+		return ErrDigestMismatch
+	}
+
+	return nil
+}
+
+// download fetches a file to a temporary name, and only moves it into place
+// once it verifies, so the cache never holds a partial file
+func (f *Fetcher) download(ctx context.Context, name string, info ParamInfo) error {
+	req, err := http.NewRequest(http.MethodGet, f.Gateway+info.Cid, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := f.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("gateway returned %s", resp.Status)
+	}
+
+	partPath := filepath.Join(f.CacheDir, name+".part")
+	part, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(partPath) // nolint: errcheck
+	defer part.Close()        // nolint: errcheck
+
+	body := io.Reader(resp.Body)
+	if f.OnProgress != nil {
+		body = &progressReader{
+			r:        resp.Body,
+			progress: Progress{File: name, Total: resp.ContentLength},
+			report:   f.OnProgress,
+		}
+	}
+
+	hasher, _ := blake2b.New512(nil)
+	if _, err := io.Copy(io.MultiWriter(part, hasher), body); err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(hasher.Sum(nil)[:16]) != info.Digest {
+		return ErrDigestMismatch
+	}
+
+	if err := part.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(partPath, filepath.Join(f.CacheDir, name))
+}
+
+// selectFiles lists the files of manifest needed for sectorSizes, sorted
+func selectFiles(manifest Manifest, sectorSizes []uint64) []string {
+	wanted := map[uint64]bool{}
+	for _, sectorSize := range sectorSizes {
+		wanted[sectorSize] = true
+	}
+
+	var names []string
+	for name, info := range manifest {
+		if len(wanted) > 0 && strings.HasSuffix(name, ".params") && !wanted[info.SectorSize] {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// fileDigest is the digest parameters.json lists for a file: the first 16
+// bytes of its blake2b-512 hash, hex encoded
+func fileDigest(r io.Reader) (string, error) {
+	hasher, _ := blake2b.New512(nil)
+	if _, err := io.Copy(hasher, r); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)[:16]), nil
+}
+
+type progressReader struct {
+	r        io.Reader
+	progress Progress
+	report   func(Progress)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.progress.Downloaded += int64(n)
+		p.report(p.progress)
+	}
+
+	return n, err
+}
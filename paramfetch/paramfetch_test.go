@@ -0,0 +1,130 @@
+package paramfetch
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetch(t *testing.T) {
+	files := map[string][]byte{
+		"v20-small.params": []byte("parameters for small sectors"),
+		"v20-small.vk":     []byte("verifying key for small sectors"),
+		"v20-large.params": []byte("parameters for large sectors"),
+		"v20-large.vk":     []byte("verifying key for large sectors"),
+	}
+
+	manifest := Manifest{}
+	byCid := map[string][]byte{}
+	for name, contents := range files {
+		digest, err := fileDigest(bytes.NewReader(contents))
+		require.NoError(t, err)
+
+		sectorSize := uint64(1024)
+		if strings.Contains(name, "large") {
+			sectorSize = 1 << 30
+		}
+
+		cid := "cid-" + name
+		manifest[name] = ParamInfo{Cid: cid, Digest: digest, SectorSize: sectorSize}
+		byCid[cid] = contents
+	}
+
+	var requests int32
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		contents, ok := byCid[strings.TrimPrefix(r.URL.Path, "/ipfs/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(contents) // nolint: errcheck
+	}))
+	defer gateway.Close()
+
+	cacheDir, err := ioutil.TempDir("", "paramfetch")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir) // nolint: errcheck
+
+	fetcher := NewFetcher(cacheDir)
+	fetcher.Gateway = gateway.URL + "/ipfs/"
+
+	var progress []Progress
+	fetcher.OnProgress = func(p Progress) {
+		progress = append(progress, p)
+	}
+
+	// only the small sector's parameters, but every verifying key
+	require.NoError(t, <-fetcher.FetchInBackground(context.Background(), manifest, 1024))
+	assert.EqualValues(t, 3, requests)
+	for name, contents := range files {
+		cached, err := ioutil.ReadFile(filepath.Join(cacheDir, name))
+		if name == "v20-large.params" {
+			assert.True(t, os.IsNotExist(err))
+			continue
+		}
+		require.NoError(t, err)
+		assert.Equal(t, contents, cached)
+		assert.NoError(t, fetcher.Verify(name, manifest[name]))
+	}
+	require.NotEmpty(t, progress)
+	last := progress[len(progress)-1]
+	assert.Equal(t, last.Total, last.Downloaded)
+
+	// verified files aren't fetched again, corrupt ones are
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "v20-small.vk"), []byte("corrupt"), 0644))
+	assert.Equal(t, ErrDigestMismatch, fetcher.Verify("v20-small.vk", manifest["v20-small.vk"]))
+	require.NoError(t, fetcher.Fetch(context.Background(), manifest, 1024))
+	assert.EqualValues(t, 4, requests)
+	assert.NoError(t, fetcher.Verify("v20-small.vk", manifest["v20-small.vk"]))
+
+	// a node which only verifies fetches verifying keys alone
+	vkCacheDir, err := ioutil.TempDir("", "paramfetch")
+	require.NoError(t, err)
+	defer os.RemoveAll(vkCacheDir) // nolint: errcheck
+
+	vkFetcher := NewFetcher(vkCacheDir)
+	vkFetcher.Gateway = fetcher.Gateway
+	require.NoError(t, vkFetcher.FetchVerifyingKeys(context.Background(), manifest, 1<<30))
+	assert.EqualValues(t, 5, requests)
+	cached, err := ioutil.ReadDir(vkCacheDir)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(cached))
+	assert.Equal(t, "v20-large.vk", cached[0].Name())
+
+	require.NoError(t, vkFetcher.FetchVerifyingKeys(context.Background(), manifest))
+	assert.EqualValues(t, 6, requests)
+
+	// a file the gateway serves wrongly is rejected and not cached
+	manifest["v20-bad.vk"] = ParamInfo{Cid: "cid-v20-small.vk", Digest: "00", SectorSize: 1024}
+	assert.Error(t, fetcher.Fetch(context.Background(), manifest))
+	_, err = os.Stat(filepath.Join(cacheDir, "v20-bad.vk"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(cacheDir, "v20-bad.vk.part"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadManifest(t *testing.T) {
+	manifest, err := LoadManifest(strings.NewReader(`{
+		"v20-stacked-proof-of-replication.vk": {
+			"cid": "QmExample",
+			"digest": "0123456789abcdef0123456789abcdef",
+			"sector_size": 1024
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, ParamInfo{Cid: "QmExample", Digest: "0123456789abcdef0123456789abcdef", SectorSize: 1024}, manifest["v20-stacked-proof-of-replication.vk"])
+
+	_, err = LoadManifest(strings.NewReader("not json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package piece
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrInclusionProofMismatch is returned when an inclusion proof doesn't lead
+// from a piece to the aggregate commitment
+var ErrInclusionProofMismatch = errors.New("inclusion proof does not match aggregate commitment")
+
+// Aggregate is a piece made of smaller pieces, each placed at the first offset
+// after the previous one which is a multiple of its own size, as deal
+// aggregators lay them out. The space around them is zeroes.
+type Aggregate struct {
+	size    uint64
+	level   int
+	pieces  []Info
+	offsets []uint64
+}
+
+// NewAggregate lays pieces out in order in an aggregate of the given padded
+// size. Returns an error if they don't fit.
+func NewAggregate(size uint64, pieces []Info) (*Aggregate, error) {
+	aggregateLevel, err := level(size)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([]uint64, len(pieces))
+	next := uint64(0)
+	for i, p := range pieces {
+		if _, err := level(p.Size); err != nil {
+			return nil, errors.Wrapf(err, "piece %d", i)
+		}
+
+		// align to the piece's own size
+		offsets[i] = (next + p.Size - 1) / p.Size * p.Size
+		next = offsets[i] + p.Size
+		if next > size {
+			return nil, errors.Errorf("pieces need %d bytes, more than the aggregate's %d", next, size)
+		}
+	}
+
+	return &Aggregate{
+		size:    size,
+		level:   aggregateLevel,
+		pieces:  append([]Info{}, pieces...),
+		offsets: offsets,
+	}, nil
+}
+
+// Size returns the aggregate's padded size
+func (a *Aggregate) Size() uint64 {
+	return a.size
+}
+
+// Offsets returns the padded offset of each piece in the aggregate
+func (a *Aggregate) Offsets() []uint64 {
+	return append([]uint64{}, a.offsets...)
+}
+
+// Commitment returns the aggregate's piece commitment
+func (a *Aggregate) Commitment() Commitment {
+	return a.node(a.level, 0)
+}
+
+// InclusionProof proves that the i-th piece is part of the aggregate
+func (a *Aggregate) InclusionProof(i int) (InclusionProof, error) {
+	if i < 0 || i >= len(a.pieces) {
+		return InclusionProof{}, errors.Errorf("no piece %d in an aggregate of %d", i, len(a.pieces))
+	}
+
+	pieceLevel, _ := level(a.pieces[i].Size)
+	index := a.offsets[i] / a.pieces[i].Size
+
+	proof := InclusionProof{Index: index}
+	for l := pieceLevel; l < a.level; l++ {
+		proof.Path = append(proof.Path, a.node(l, index^1))
+		index >>= 1
+	}
+
+	return proof, nil
+}
+
+// node returns the node at height l and position index in the aggregate's
+// tree. Pieces are aligned to their size, so a node either covers no piece,
+// is exactly one piece, or is split by its children.
+func (a *Aggregate) node(l int, index uint64) Commitment {
+	nodeSize := uint64(NodeBytes) << uint(l)
+	start, end := index*nodeSize, (index+1)*nodeSize
+
+	covered := false
+	for i, p := range a.pieces {
+		if a.offsets[i] == start && p.Size == nodeSize {
+			return p.Commitment
+		}
+		if a.offsets[i] < end && a.offsets[i]+p.Size > start {
+			covered = true
+		}
+	}
+
+	if !covered {
+		return zeroCommitments[l]
+	}
+
+	return hashNodes(a.node(l-1, 2*index), a.node(l-1, 2*index+1))
+}
+
+// InclusionProof is a merkle proof that a piece is a subtree of an aggregate.
+// Index is the piece's position among the subtrees of its size, and Path the
+// sibling of each node from the piece up to, but excluding, the root.
+type InclusionProof struct {
+	Index uint64
+	Path  []Commitment
+}
+
+// ComputeRoot returns the aggregate commitment the proof leads to from a piece
+func (p InclusionProof) ComputeRoot(piece Commitment) Commitment {
+	node, index := piece, p.Index
+	for _, sibling := range p.Path {
+		if index&1 == 0 {
+			node = hashNodes(node, sibling)
+		} else {
+			node = hashNodes(sibling, node)
+		}
+		index >>= 1
+	}
+
+	return node
+}
+
+// VerifyInclusion checks that proof shows the piece to be included in the
+// aggregate at the given padded offset
+func VerifyInclusion(aggregate Info, piece Info, offset uint64, proof InclusionProof) error {
+	aggregateLevel, err := level(aggregate.Size)
+	if err != nil {
+		return errors.Wrap(err, "aggregate")
+	}
+
+	pieceLevel, err := level(piece.Size)
+	if err != nil {
+		return errors.Wrap(err, "piece")
+	}
+
+	if pieceLevel > aggregateLevel || offset%piece.Size != 0 || offset+piece.Size > aggregate.Size {
+		return errors.Errorf("a %d byte piece can't be at offset %d of a %d byte aggregate", piece.Size, offset, aggregate.Size)
+	}
+
+	if len(proof.Path) != aggregateLevel-pieceLevel || proof.Index != offset/piece.Size {
+		return ErrInclusionProofMismatch
+	}
+
+	if proof.ComputeRoot(piece.Commitment) != aggregate.Commitment {
+		return ErrInclusionProofMismatch
+	}
+
+	return nil
+}
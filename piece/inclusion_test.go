@@ -0,0 +1,60 @@
+package piece
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitment(b byte) Commitment {
+	var c Commitment
+	for i := range c {
+		c[i] = b
+	}
+	c[NodeBytes-1] &= 0x3f
+
+	return c
+}
+
+func TestInclusionProofs(t *testing.T) {
+	pieces := []Info{
+		{Size: 256, Commitment: commitment(1)},
+		{Size: 128, Commitment: commitment(2)},
+		{Size: 512, Commitment: commitment(3)},
+	}
+
+	aggregate, err := NewAggregate(2048, pieces)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{0, 256, 512}, aggregate.Offsets())
+
+	// the layout, 128 byte node by node: 1 1 2 0 3 3 3 3 0 0 0 0 0 0 0 0
+	zero := zeroCommitments[2]
+	first := hashNodes(hashNodes(pieces[0].Commitment, hashNodes(pieces[1].Commitment, zero)), pieces[2].Commitment)
+	expected := hashNodes(first, zeroCommitments[5])
+	assert.Equal(t, expected, aggregate.Commitment())
+
+	aggregateInfo := Info{Size: aggregate.Size(), Commitment: aggregate.Commitment()}
+	for i, p := range pieces {
+		proof, err := aggregate.InclusionProof(i)
+		require.NoError(t, err)
+		assert.NoError(t, VerifyInclusion(aggregateInfo, p, aggregate.Offsets()[i], proof))
+
+		// the wrong piece, offset or path don't verify
+		assert.Error(t, VerifyInclusion(aggregateInfo, Info{Size: p.Size, Commitment: commitment(9)}, aggregate.Offsets()[i], proof))
+		assert.Error(t, VerifyInclusion(aggregateInfo, p, aggregate.Offsets()[i]+p.Size, proof))
+
+		proof.Path[0][0] ^= 1
+		assert.Equal(t, ErrInclusionProofMismatch, VerifyInclusion(aggregateInfo, p, aggregate.Offsets()[i], proof))
+	}
+
+	_, err = aggregate.InclusionProof(3)
+	assert.Error(t, err)
+
+	// pieces must fit, and be power of two sized
+	_, err = NewAggregate(512, pieces)
+	assert.Error(t, err)
+
+	_, err = NewAggregate(2048, []Info{{Size: 300}})
+	assert.Error(t, err)
+}
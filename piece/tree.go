@@ -0,0 +1,117 @@
+// Package piece works with piece commitments (CommP) in Go: the roots of the
+// binary merkle trees the proofs library builds over Fr32 padded piece data,
+// whose nodes are SHA-256 hashes truncated to 254 bits.
+package piece
+
+import (
+	"crypto/sha256"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// NodeBytes is the length of a tree node, and so of a commitment
+const NodeBytes = 32
+
+// MinPieceSize is the padded size of the smallest piece, one Fr32 chunk
+const MinPieceSize = 128
+
+// maxLevel is the height of the tallest tree supported, 64GiB of nodes
+const maxLevel = 31
+
+// Commitment is the root of a piece's tree
+type Commitment [NodeBytes]byte
+
+// Info identifies a piece by its padded size and commitment
+type Info struct {
+	Size       uint64
+	Commitment Commitment
+}
+
+// zeroCommitments[l] is the root of a tree of 2^l zero nodes
+var zeroCommitments [maxLevel + 1]Commitment
+
+func init() {
+	for l := 1; l <= maxLevel; l++ {
+		zeroCommitments[l] = hashNodes(zeroCommitments[l-1], zeroCommitments[l-1])
+	}
+}
+
+// ZeroPieceCommitment returns the commitment of a piece of zeroes of the
+// given padded size, a power of two of at least MinPieceSize bytes. The
+// commitments are precomputed, so this is a table lookup.
+func ZeroPieceCommitment(size uint64) (Commitment, error) {
+	l, err := level(size)
+	if err != nil {
+		return Commitment{}, err
+	}
+
+	return zeroCommitments[l], nil
+}
+
+// hashNodes is the parent of two nodes: their SHA-256 hash with the top two
+// bits cleared, so it's a valid field element
+func hashNodes(left, right Commitment) Commitment {
+	h := sha256.New()
+	h.Write(left[:])  // nolint: errcheck
+	h.Write(right[:]) // nolint: errcheck
+
+	var parent Commitment
+	h.Sum(parent[:0])
+	parent[NodeBytes-1] &= 0x3f
+
+	return parent
+}
+
+// level returns the height of the tree over size bytes of padded data, which
+// must be a power of two and at least MinPieceSize
+func level(size uint64) (int, error) {
+	if size < MinPieceSize || size&(size-1) != 0 {
+		return 0, errors.Errorf("padded piece size must be a power of two of at least %d bytes, got %d", MinPieceSize, size)
+	}
+
+	l := bits.TrailingZeros64(size / NodeBytes)
+	if l > maxLevel {
+		return 0, errors.Errorf("padded piece size must be at most %d bytes, got %d", uint64(NodeBytes)<<maxLevel, size)
+	}
+
+	return l, nil
+}
+
+// MergeCommitments returns the commitment of a piece of the given padded size
+// from the commitments of its consecutive chunks of chunkSize padded bytes,
+// as computed independently. Any space past the last chunk is zeroes.
+func MergeCommitments(chunkSize uint64, chunks []Commitment, size uint64) (Commitment, error) {
+	chunkLevel, err := level(chunkSize)
+	if err != nil {
+		return Commitment{}, errors.Wrap(err, "chunk")
+	}
+
+	pieceLevel, err := level(size)
+	if err != nil {
+		return Commitment{}, err
+	}
+
+	if chunkLevel > pieceLevel || uint64(len(chunks)) > size/chunkSize {
+		return Commitment{}, errors.Errorf("%d chunks of %d bytes don't fit a %d byte piece", len(chunks), chunkSize, size)
+	}
+
+	if len(chunks) == 0 {
+		return zeroCommitments[pieceLevel], nil
+	}
+
+	nodes := append([]Commitment{}, chunks...)
+	for l := chunkLevel; l < pieceLevel; l++ {
+		parents := make([]Commitment, (len(nodes)+1)/2)
+		for i := range parents {
+			right := zeroCommitments[l]
+			if 2*i+1 < len(nodes) {
+				right = nodes[2*i+1]
+			}
+			parents[i] = hashNodes(nodes[2*i], right)
+		}
+		nodes = parents
+	}
+
+	return nodes[0], nil
+}
@@ -0,0 +1,68 @@
+package piece
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroPieceCommitment(t *testing.T) {
+	commP, err := ZeroPieceCommitment(128)
+	require.NoError(t, err)
+	assert.Equal(t, "3731bb99ac689f66eef5973e4a94da188f4ddcae580724fc6f3fd60dfd488333", hex.EncodeToString(commP[:]))
+
+	commP, err = ZeroPieceCommitment(256)
+	require.NoError(t, err)
+	assert.Equal(t, "642a607ef886b004bf2c1978463ae1d4693ac0f410eb2d1b7a47fe205e5e750f", hex.EncodeToString(commP[:]))
+
+	// each size is the parent of two of the size below
+	for size := uint64(256); size <= 32<<30; size <<= 1 {
+		half, err := ZeroPieceCommitment(size / 2)
+		require.NoError(t, err)
+
+		commP, err := ZeroPieceCommitment(size)
+		require.NoError(t, err)
+		assert.Equal(t, hashNodes(half, half), commP)
+	}
+
+	for _, size := range []uint64{0, 64, 127, 384, 128 << 30} {
+		_, err := ZeroPieceCommitment(size)
+		assert.Error(t, err, size)
+	}
+}
+
+func TestMergeCommitments(t *testing.T) {
+	chunks := []Commitment{commitment(1), commitment(2), commitment(3)}
+
+	// merging lays the chunks out like an aggregate of them
+	var pieces []Info
+	for _, chunk := range chunks {
+		pieces = append(pieces, Info{Size: 256, Commitment: chunk})
+	}
+	aggregate, err := NewAggregate(2048, pieces)
+	require.NoError(t, err)
+
+	merged, err := MergeCommitments(256, chunks, 2048)
+	require.NoError(t, err)
+	assert.Equal(t, aggregate.Commitment(), merged)
+
+	// a single chunk is the piece
+	merged, err = MergeCommitments(256, chunks[:1], 256)
+	require.NoError(t, err)
+	assert.Equal(t, chunks[0], merged)
+
+	// no chunks is all zeroes
+	merged, err = MergeCommitments(256, nil, 2048)
+	require.NoError(t, err)
+	zero, err := ZeroPieceCommitment(2048)
+	require.NoError(t, err)
+	assert.Equal(t, zero, merged)
+
+	_, err = MergeCommitments(256, chunks, 512)
+	assert.Error(t, err)
+
+	_, err = MergeCommitments(4096, chunks, 2048)
+	assert.Error(t, err)
+}
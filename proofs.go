@@ -3,12 +3,20 @@ package ffi
 import (
 	"bytes"
 	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
 	"os"
 	"runtime"
 	"sort"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/filecoin-ffi/fr32"
+	"github.com/filecoin-project/filecoin-ffi/piece"
 )
 
 // #cgo LDFLAGS: ${SRCDIR}/libfilecoin.a
@@ -174,6 +182,8 @@ func VerifySeal(
 	sectorID uint64,
 	proof []byte,
 ) (bool, error) {
+	markProofsCalled()
+	defer observeDuration(OpVerifySeal, time.Now())
 
 	commDCBytes := C.CBytes(commD[:])
 	defer C.free(commDCBytes)
@@ -214,6 +224,79 @@ func VerifySeal(
 	return bool(resPtr.is_valid), nil
 }
 
+// SealVerifyInfo holds everything needed to verify one seal proof
+type SealVerifyInfo struct {
+	SectorSize uint64
+	CommR      [CommitmentBytesLen]byte
+	CommD      [CommitmentBytesLen]byte
+	ProverID   [32]byte
+	Ticket     [32]byte
+	Seed       [32]byte
+	SectorID   uint64
+	Proof      []byte
+}
+
+// VerifySealBatch verifies many seal proofs in one call, in parallel on the
+// Rust side. The i-th result reports whether the i-th proof is valid; unlike
+// VerifySeal, a proof which can't be checked at all (e.g. one of the wrong
+// length) is reported as invalid rather than as an error.
+func VerifySealBatch(seals []SealVerifyInfo) ([]bool, error) {
+	markProofsCalled()
+	defer observeDuration(OpVerifySealBatch, time.Now())
+
+	if len(seals) == 0 {
+		return []bool{}, nil
+	}
+
+	// prep data
+	var flattenedProofs []byte
+	for _, seal := range seals {
+		flattenedProofs = append(flattenedProofs, seal.Proof...)
+	}
+
+	// prep request
+	cFlattenedProofs := C.CBytes(flattenedProofs)
+	defer C.free(cFlattenedProofs)
+
+	cSealsLen := C.size_t(len(seals))
+	cSeals := C.malloc(cSealsLen * C.sizeof_FFISealVerifyInfo)
+	defer C.free(cSeals)
+
+	pp := (*[1 << 30]C.FFISealVerifyInfo)(cSeals)
+	offset := 0
+	for i, v := range seals {
+		pp[i] = C.FFISealVerifyInfo{
+			sector_size: C.uint64_t(v.SectorSize),
+			comm_r:      *(*[32]C.uint8_t)(unsafe.Pointer(&v.CommR)),
+			comm_d:      *(*[32]C.uint8_t)(unsafe.Pointer(&v.CommD)),
+			prover_id:   *(*[32]C.uint8_t)(unsafe.Pointer(&v.ProverID)),
+			ticket:      *(*[32]C.uint8_t)(unsafe.Pointer(&v.Ticket)),
+			seed:        *(*[32]C.uint8_t)(unsafe.Pointer(&v.Seed)),
+			sector_id:   C.uint64_t(v.SectorID),
+			proof_ptr:   (*C.uint8_t)(unsafe.Pointer(uintptr(cFlattenedProofs) + uintptr(offset))),
+			proof_len:   C.size_t(len(v.Proof)),
+		}
+		offset += len(v.Proof)
+	}
+
+	// call method
+	resPtr := C.verify_seal_batch((*C.FFISealVerifyInfo)(cSeals), cSealsLen)
+	defer C.destroy_verify_seal_batch_response(resPtr)
+
+	if resPtr.status_code != 0 {
+		return nil, errors.New(C.GoString(resPtr.error_msg))
+	}
+
+	// prep response
+	results := make([]bool, resPtr.results_len)
+	cResults := (*[1 << 30]C.bool)(unsafe.Pointer(resPtr.results_ptr))[:resPtr.results_len:resPtr.results_len]
+	for i := range results {
+		results[i] = bool(cResults[i])
+	}
+
+	return results, nil
+}
+
 // VerifyPoSt returns true if the PoSt-generation operation from which its
 // inputs were derived was valid, and false if not.
 func VerifyPoSt(
@@ -225,6 +308,9 @@ func VerifyPoSt(
 	winners []Candidate,
 	proverID [32]byte,
 ) (bool, error) {
+	markProofsCalled()
+	defer observeDuration(OpVerifyPoSt, time.Now())
+
 	// CommRs and sector ids must be provided to C.verify_post in the same order
 	// that they were provided to the C.generate_post
 	sortedCommRs := make([][CommitmentBytesLen]byte, len(sectorInfo.Values()))
@@ -284,13 +370,157 @@ func VerifyPoSt(
 	return bool(resPtr.is_valid), nil
 }
 
+// PoStVerifyInfo holds everything needed to verify one proof-of-spacetime
+type PoStVerifyInfo struct {
+	SectorSize     uint64
+	SectorInfo     SortedPublicSectorInfo
+	Randomness     [32]byte
+	ChallengeCount uint64
+	Proof          []byte
+	Winners        []Candidate
+	ProverID       [32]byte
+}
+
+// VerifyPoStBatch verifies many proofs-of-spacetime in one call, in parallel on
+// the Rust side. The i-th result reports whether the i-th proof is valid;
+// unlike VerifyPoSt, a proof which can't be checked at all is reported as
+// invalid rather than as an error.
+func VerifyPoStBatch(posts []PoStVerifyInfo) ([]bool, error) {
+	markProofsCalled()
+	defer observeDuration(OpVerifyPoStBatch, time.Now())
+
+	if len(posts) == 0 {
+		return []bool{}, nil
+	}
+
+	// everything allocated in the C heap, freed once the call returns
+	var allocations []unsafe.Pointer
+	defer func() {
+		for _, allocation := range allocations {
+			C.free(allocation)
+		}
+	}()
+
+	// prep request
+	cPostsLen := C.size_t(len(posts))
+	cPosts := C.malloc(cPostsLen * C.sizeof_FFIPoStVerifyInfo)
+	allocations = append(allocations, cPosts)
+
+	pp := (*[1 << 30]C.FFIPoStVerifyInfo)(cPosts)
+	for i, v := range posts {
+		sectorIds := make([]uint64, len(v.SectorInfo.Values()))
+		flattenedCommRs := make([]byte, CommitmentBytesLen*len(v.SectorInfo.Values()))
+		for idx, info := range v.SectorInfo.Values() {
+			sectorIds[idx] = info.SectorID
+			copy(flattenedCommRs[(CommitmentBytesLen*idx):(CommitmentBytesLen*(1+idx))], info.CommR[:])
+		}
+
+		sectorIdsPtr, sectorIdsSize := cUint64s(sectorIds)
+		flattenedCommRsCBytes := C.CBytes(flattenedCommRs)
+		proofCBytes := C.CBytes(v.Proof)
+		winnersPtr, winnersSize := cCandidates(v.Winners)
+		allocations = append(allocations, unsafe.Pointer(sectorIdsPtr), flattenedCommRsCBytes, proofCBytes, unsafe.Pointer(winnersPtr))
+
+		pp[i] = C.FFIPoStVerifyInfo{
+			sector_size:           C.uint64_t(v.SectorSize),
+			randomness:            *(*[32]C.uint8_t)(unsafe.Pointer(&v.Randomness)),
+			challenge_count:       C.uint64_t(v.ChallengeCount),
+			sector_ids_ptr:        sectorIdsPtr,
+			sector_ids_len:        sectorIdsSize,
+			flattened_comm_rs_ptr: (*C.uint8_t)(flattenedCommRsCBytes),
+			flattened_comm_rs_len: C.size_t(len(flattenedCommRs)),
+			flattened_proofs_ptr:  (*C.uint8_t)(proofCBytes),
+			flattened_proofs_len:  C.size_t(len(v.Proof)),
+			winners_ptr:           winnersPtr,
+			winners_len:           winnersSize,
+			prover_id:             *(*[32]C.uint8_t)(unsafe.Pointer(&v.ProverID)),
+		}
+	}
+
+	// call method
+	resPtr := C.verify_post_batch((*C.FFIPoStVerifyInfo)(cPosts), cPostsLen)
+	defer C.destroy_verify_post_batch_response(resPtr)
+
+	if resPtr.status_code != 0 {
+		return nil, errors.New(C.GoString(resPtr.error_msg))
+	}
+
+	// prep response
+	results := make([]bool, resPtr.results_len)
+	cResults := (*[1 << 30]C.bool)(unsafe.Pointer(resPtr.results_ptr))[:resPtr.results_len:resPtr.results_len]
+	for i := range results {
+		results[i] = bool(cResults[i])
+	}
+
+	return results, nil
+}
+
 // GetMaxUserBytesPerStagedSector returns the number of user bytes that will fit
 // into a staged sector. Due to bit-padding, the number of user bytes that will
 // fit into the staged sector will be less than number of bytes in sectorSize.
 func GetMaxUserBytesPerStagedSector(sectorSize uint64) uint64 {
+	markProofsCalled()
+
 	return uint64(C.get_max_user_bytes_per_staged_sector(C.uint64_t(sectorSize)))
 }
 
+// SectorSizeInfo describes a sector size the linked proofs library supports
+type SectorSizeInfo struct {
+	SectorSize uint64
+
+	// PoRepProofPartitions is the number of partitions the library proves
+	// seals of this size with by default
+	PoRepProofPartitions uint8
+
+	// PoRepProofBytesPerPartition is the length of each partition's proof, so
+	// a seal proof is PoRepProofPartitions times as long
+	PoRepProofBytesPerPartition uint64
+
+	// MaxUserBytes is the number of unpadded bytes the sector holds
+	MaxUserBytes uint64
+}
+
+// SupportedSectorSizes lists the sector sizes the linked proofs library can
+// seal and prove, in ascending order. Checking configuration against it at
+// startup avoids finding out mid-seal.
+func SupportedSectorSizes() []SectorSizeInfo {
+	markProofsCalled()
+
+	// call method
+	resPtr := C.get_supported_sector_sizes()
+	defer C.destroy_supported_sector_sizes_response(resPtr)
+
+	// prep response
+	infos := make([]SectorSizeInfo, resPtr.sector_sizes_len)
+	if resPtr.sector_sizes_ptr == nil {
+		return infos
+	}
+
+	cInfos := (*[1 << 30]C.FFISectorSizeInfo)(unsafe.Pointer(resPtr.sector_sizes_ptr))[:resPtr.sector_sizes_len:resPtr.sector_sizes_len]
+	for i, cInfo := range cInfos {
+		infos[i] = SectorSizeInfo{
+			SectorSize:                  uint64(cInfo.sector_size),
+			PoRepProofPartitions:        uint8(cInfo.porep_proof_partitions),
+			PoRepProofBytesPerPartition: uint64(cInfo.porep_proof_bytes_per_partition),
+			MaxUserBytes:                GetMaxUserBytesPerStagedSector(uint64(cInfo.sector_size)),
+		}
+	}
+
+	return infos
+}
+
+// IsSupportedSectorSize returns true if the linked proofs library can seal
+// sectors of the given size
+func IsSupportedSectorSize(sectorSize uint64) bool {
+	for _, info := range SupportedSectorSizes() {
+		if info.SectorSize == sectorSize {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GeneratePieceCommitment produces a piece commitment for the provided data
 // stored at a given path.
 func GeneratePieceCommitment(piecePath string, pieceSize uint64) ([CommitmentBytesLen]byte, error) {
@@ -298,13 +528,155 @@ func GeneratePieceCommitment(piecePath string, pieceSize uint64) ([CommitmentByt
 	if err != nil {
 		return [CommitmentBytesLen]byte{}, err
 	}
+	defer pieceFile.Close() // nolint: errcheck
 
 	return GeneratePieceCommitmentFromFile(pieceFile, pieceSize)
 }
 
-// GenerateDataCommitment produces a commitment for the sector containing the
-// provided pieces.
+// GeneratePieceCommitmentFromReader produces a piece commitment for pieceSize
+// bytes of data read from pieceReader. The data is streamed to the hasher
+// through a pipe, so it never has to be written out to a file first.
+func GeneratePieceCommitmentFromReader(pieceReader io.Reader, pieceSize uint64) ([CommitmentBytesLen]byte, error) {
+	defer observeDuration(OpGeneratePieceCommitment, time.Now())
+
+	return pieceCommitmentFromReader(pieceReader, pieceSize)
+}
+
+// pieceCommitmentFromReader is GeneratePieceCommitmentFromReader without the
+// metrics, for callers which measure themselves
+func pieceCommitmentFromReader(pieceReader io.Reader, pieceSize uint64) ([CommitmentBytesLen]byte, error) {
+	pipeReader, pipeWriter, err := os.Pipe()
+	if err != nil {
+		return [CommitmentBytesLen]byte{}, errors.Wrap(err, "failed to create pipe")
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		src := &sourceReader{r: pieceReader}
+		_, err := io.CopyN(pipeWriter, src, int64(pieceSize))
+		pipeWriter.Close() // nolint: errcheck
+
+		// only report reads which failed; a failed write means the hasher
+		// stopped reading, and its own error says why
+		if err != nil {
+			readErr <- src.err
+		} else {
+			readErr <- nil
+		}
+	}()
+
+	commP, err := pieceCommitmentFromFile(pipeReader, pieceSize)
+
+	// unblock the copy if the hasher stopped reading early
+	pipeReader.Close() // nolint: errcheck
+
+	// a piece which couldn't be read makes the hasher fail too, so its error
+	// is the cause
+	if err := <-readErr; err != nil {
+		return [CommitmentBytesLen]byte{}, errors.Wrap(err, "failed to read piece")
+	}
+
+	return commP, err
+}
+
+// sourceReader remembers the last error reading from r, which tells a copy
+// failing to read apart from one failing to write
+type sourceReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *sourceReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil {
+		s.err = err
+	}
+
+	return n, err
+}
+
+// GeneratePieceCommitmentParallel produces the same piece commitment as
+// GeneratePieceCommitmentFromFile for pieceSize bytes of pieceReader, hashing
+// chunks of chunkSize unpadded bytes on up to parallelism goroutines and then
+// merging their commitments. Both sizes must be 127 times a power of two.
+// Values of parallelism below 1 use one goroutine per CPU. The whole piece is
+// measured as one piece commitment, however many chunks it takes.
+func GeneratePieceCommitmentParallel(pieceReader io.ReaderAt, pieceSize uint64, chunkSize uint64, parallelism int) ([CommitmentBytesLen]byte, error) {
+	defer observeDuration(OpGeneratePieceCommitment, time.Now())
+
+	if !validPieceSize(pieceSize) {
+		return [CommitmentBytesLen]byte{}, errors.Errorf("piece size %d is not 127 times a power of two", pieceSize)
+	}
+	if !validPieceSize(chunkSize) {
+		return [CommitmentBytesLen]byte{}, errors.Errorf("chunk size %d is not 127 times a power of two", chunkSize)
+	}
+	if chunkSize > pieceSize {
+		return [CommitmentBytesLen]byte{}, errors.Errorf("chunk size %d is larger than piece size %d", chunkSize, pieceSize)
+	}
+
+	if parallelism < 1 {
+		parallelism = runtime.NumCPU()
+	}
+
+	chunks := make([]piece.Commitment, pieceSize/chunkSize)
+	errs := make([]error, len(chunks))
+
+	next := make(chan int, len(chunks))
+	for idx := range chunks {
+		next <- idx
+	}
+	close(next)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism && i < len(chunks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range next {
+				chunkReader := io.NewSectionReader(pieceReader, int64(uint64(idx)*chunkSize), int64(chunkSize))
+				chunks[idx], errs[idx] = pieceCommitmentFromReader(chunkReader, chunkSize)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for idx, err := range errs {
+		if err != nil {
+			return [CommitmentBytesLen]byte{}, errors.Wrapf(err, "failed to generate commitment of chunk %d", idx)
+		}
+	}
+
+	commP, err := piece.MergeCommitments(paddedSize(chunkSize), chunks, paddedSize(pieceSize))
+	if err != nil {
+		return [CommitmentBytesLen]byte{}, err
+	}
+
+	return commP, nil
+}
+
+// validPieceSize reports whether an unpadded piece size is 127 times a power of
+// two, which is the size of a whole tree of Fr32 padded chunks
+func validPieceSize(unpaddedSize uint64) bool {
+	if unpaddedSize == 0 || unpaddedSize%fr32.UnpaddedChunkBytes != 0 {
+		return false
+	}
+
+	chunks := unpaddedSize / fr32.UnpaddedChunkBytes
+	return chunks&(chunks-1) == 0
+}
+
+// paddedSize is the size unpaddedSize bytes take once Fr32 padded
+func paddedSize(unpaddedSize uint64) uint64 {
+	return unpaddedSize / fr32.UnpaddedChunkBytes * fr32.PaddedChunkBytes
+}
+
+// GenerateDataCommitment produces a commitment (CommD) for the sector
+// containing the provided pieces, in order. Any space the pieces don't fill is
+// treated as zeroes, so the result matches the CommD SealPreCommit reports for
+// the same pieces.
 func GenerateDataCommitment(sectorSize uint64, pieces []PublicPieceInfo) ([CommitmentBytesLen]byte, error) {
+	markProofsCalled()
+
 	cPiecesPtr, cPiecesLen := cPublicPieceInfo(pieces)
 	defer C.free(unsafe.Pointer(cPiecesPtr))
 
@@ -321,6 +693,16 @@ func GenerateDataCommitment(sectorSize uint64, pieces []PublicPieceInfo) ([Commi
 // GeneratePieceCommitmentFromFile produces a piece commitment for the provided data
 // stored in a given file.
 func GeneratePieceCommitmentFromFile(pieceFile *os.File, pieceSize uint64) (commP [CommitmentBytesLen]byte, err error) {
+	defer observeDuration(OpGeneratePieceCommitment, time.Now())
+
+	return pieceCommitmentFromFile(pieceFile, pieceSize)
+}
+
+// pieceCommitmentFromFile is GeneratePieceCommitmentFromFile without the
+// metrics, for callers which measure themselves
+func pieceCommitmentFromFile(pieceFile *os.File, pieceSize uint64) ([CommitmentBytesLen]byte, error) {
+	markProofsCalled()
+
 	pieceFd := pieceFile.Fd()
 
 	resPtr := C.generate_piece_commitment(C.int(pieceFd), C.uint64_t(pieceSize))
@@ -336,18 +718,21 @@ func GeneratePieceCommitmentFromFile(pieceFile *os.File, pieceSize uint64) (comm
 	return goCommitment(&resPtr.comm_p[0]), nil
 }
 
-// WriteWithAlignment
+// WriteWithAlignment Fr32-pads pieceBytes bytes read from pieceFile and
+// appends them to stagedSectorFile, after first writing the zero alignment
+// needed for the piece to follow the pieces of existingPieceSizes. Returns the
+// unpadded size of the alignment, the unpadded total written (alignment
+// included) and the piece's commitment.
 func WriteWithAlignment(
 	pieceFile *os.File,
 	pieceBytes uint64,
 	stagedSectorFile *os.File,
 	existingPieceSizes []uint64,
 ) (leftAlignment, total uint64, commP [CommitmentBytesLen]byte, retErr error) {
-	pieceFd := pieceFile.Fd()
-	runtime.KeepAlive(pieceFile)
+	markProofsCalled()
 
+	pieceFd := pieceFile.Fd()
 	stagedSectorFd := stagedSectorFile.Fd()
-	runtime.KeepAlive(stagedSectorFile)
 
 	ptr, len := cUint64s(existingPieceSizes)
 	defer C.free(unsafe.Pointer(ptr))
@@ -361,6 +746,10 @@ func WriteWithAlignment(
 	)
 	defer C.destroy_write_with_alignment_response(resPtr)
 
+	// keep the files, and so their descriptors, open until Rust is done
+	runtime.KeepAlive(pieceFile)
+	runtime.KeepAlive(stagedSectorFile)
+
 	if resPtr.status_code != 0 {
 		return 0, 0, [CommitmentBytesLen]byte{}, errors.New(C.GoString(resPtr.error_msg))
 	}
@@ -368,17 +757,18 @@ func WriteWithAlignment(
 	return uint64(resPtr.left_alignment_unpadded), uint64(resPtr.total_write_unpadded), goCommitment(&resPtr.comm_p[0]), nil
 }
 
-// WriteWithoutAlignment
+// WriteWithoutAlignment Fr32-pads pieceBytes bytes read from pieceFile and
+// appends them to stagedSectorFile as they are, for the first piece of a
+// sector. Returns the unpadded total written and the piece's commitment.
 func WriteWithoutAlignment(
 	pieceFile *os.File,
 	pieceBytes uint64,
 	stagedSectorFile *os.File,
 ) (uint64, [CommitmentBytesLen]byte, error) {
-	pieceFd := pieceFile.Fd()
-	runtime.KeepAlive(pieceFile)
+	markProofsCalled()
 
+	pieceFd := pieceFile.Fd()
 	stagedSectorFd := stagedSectorFile.Fd()
-	runtime.KeepAlive(stagedSectorFile)
 
 	resPtr := C.write_without_alignment(
 		C.int(pieceFd),
@@ -387,6 +777,10 @@ func WriteWithoutAlignment(
 	)
 	defer C.destroy_write_without_alignment_response(resPtr)
 
+	// keep the files, and so their descriptors, open until Rust is done
+	runtime.KeepAlive(pieceFile)
+	runtime.KeepAlive(stagedSectorFile)
+
 	if resPtr.status_code != 0 {
 		return 0, [CommitmentBytesLen]byte{}, errors.New(C.GoString(resPtr.error_msg))
 	}
@@ -406,6 +800,9 @@ func SealPreCommit(
 	ticket [32]byte,
 	pieces []PublicPieceInfo,
 ) (RawSealPreCommitOutput, error) {
+	markProofsCalled()
+	defer observeDuration(OpSealPreCommit, time.Now())
+
 	cCacheDirPath := C.CString(cacheDirPath)
 	defer C.free(unsafe.Pointer(cCacheDirPath))
 
@@ -456,6 +853,9 @@ func SealCommit(
 	pieces []PublicPieceInfo,
 	rspco RawSealPreCommitOutput,
 ) ([]byte, error) {
+	markProofsCalled()
+	defer observeDuration(OpSealCommit, time.Now())
+
 	cCacheDirPath := C.CString(cacheDirPath)
 	defer C.free(unsafe.Pointer(cCacheDirPath))
 
@@ -488,7 +888,10 @@ func SealCommit(
 		return nil, errors.New(C.GoString(resPtr.error_msg))
 	}
 
-	return C.GoBytes(unsafe.Pointer(resPtr.proof_ptr), C.int(resPtr.proof_len)), nil
+	proof := C.GoBytes(unsafe.Pointer(resPtr.proof_ptr), C.int(resPtr.proof_len))
+	observeProofSize(OpSealCommit, proof)
+
+	return proof, nil
 }
 
 // Unseal
@@ -503,6 +906,9 @@ func Unseal(
 	ticket [32]byte,
 	commD [CommitmentBytesLen]byte,
 ) error {
+	markProofsCalled()
+	defer observeDuration(OpUnseal, time.Now())
+
 	cCacheDirPath := C.CString(cacheDirPath)
 	defer C.free(unsafe.Pointer(cCacheDirPath))
 
@@ -555,6 +961,9 @@ func UnsealRange(
 	offset uint64,
 	len uint64,
 ) error {
+	markProofsCalled()
+	defer observeDuration(OpUnsealRange, time.Now())
+
 	cCacheDirPath := C.CString(cacheDirPath)
 	defer C.free(unsafe.Pointer(cCacheDirPath))
 
@@ -594,8 +1003,80 @@ func UnsealRange(
 	return nil
 }
 
+// UnsealToWriter is like Unseal, but copies the unsealed sector to w rather
+// than leaving it at a path. The sector is staged in a file in stagingDir,
+// which needs room for a whole unsealed sector.
+func UnsealToWriter(
+	sectorSize uint64,
+	poRepProofPartitions uint8,
+	cacheDirPath string,
+	sealedSectorPath string,
+	w io.Writer,
+	stagingDir string,
+	sectorID uint64,
+	proverID [32]byte,
+	ticket [32]byte,
+	commD [CommitmentBytesLen]byte,
+) error {
+	return unsealToWriter(w, stagingDir, func(unsealOutputPath string) error {
+		return Unseal(sectorSize, poRepProofPartitions, cacheDirPath, sealedSectorPath, unsealOutputPath, sectorID, proverID, ticket, commD)
+	})
+}
+
+// UnsealRangeToWriter is like UnsealRange, but copies the unsealed bytes to w
+// rather than leaving them at a path, so a retrieval can be streamed straight
+// to the client. The bytes are staged in a file in stagingDir, which needs room
+// for len bytes.
+func UnsealRangeToWriter(
+	sectorSize uint64,
+	poRepProofPartitions uint8,
+	cacheDirPath string,
+	sealedSectorPath string,
+	w io.Writer,
+	stagingDir string,
+	sectorID uint64,
+	proverID [32]byte,
+	ticket [32]byte,
+	commD [CommitmentBytesLen]byte,
+	offset uint64,
+	len uint64,
+) error {
+	return unsealToWriter(w, stagingDir, func(unsealOutputPath string) error {
+		return UnsealRange(sectorSize, poRepProofPartitions, cacheDirPath, sealedSectorPath, unsealOutputPath, sectorID, proverID, ticket, commD, offset, len)
+	})
+}
+
+// unsealToWriter runs unseal against a temporary file in stagingDir and copies
+// the result to w. The unsealer can only write to a path, so the bytes are
+// staged on disk rather than held in memory. Sectors run to tens of GiB, so
+// the caller must pick the disk rather than fall back on os.TempDir.
+func unsealToWriter(w io.Writer, stagingDir string, unseal func(unsealOutputPath string) error) error {
+	if stagingDir == "" {
+		return errors.New("no staging directory given")
+	}
+
+	staged, err := ioutil.TempFile(stagingDir, "unseal")
+	if err != nil {
+		return errors.Wrap(err, "failed to create staging file")
+	}
+	defer os.Remove(staged.Name()) // nolint: errcheck
+	defer staged.Close()           // nolint: errcheck
+
+	if err := unseal(staged.Name()); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, staged); err != nil {
+		return errors.Wrap(err, "failed to copy unsealed bytes")
+	}
+
+	return nil
+}
+
 // FinalizeTicket creates an actual ticket from a partial ticket.
 func FinalizeTicket(partialTicket [32]byte) ([32]byte, error) {
+	markProofsCalled()
+
 	partialTicketPtr := unsafe.Pointer(&(partialTicket)[0])
 	resPtr := C.finalize_ticket(
 		(*[32]C.uint8_t)(partialTicketPtr),
@@ -617,6 +1098,9 @@ func GenerateCandidates(
 	challengeCount uint64,
 	privateSectorInfo SortedPrivateSectorInfo,
 ) ([]Candidate, error) {
+	markProofsCalled()
+	defer observeDuration(OpGenerateCandidates, time.Now())
+
 	randomessCBytes := C.CBytes(randomness[:])
 	defer C.free(randomessCBytes)
 
@@ -651,6 +1135,9 @@ func GeneratePoSt(
 	randomness [32]byte,
 	winners []Candidate,
 ) ([]byte, error) {
+	markProofsCalled()
+	defer observeDuration(OpGeneratePoSt, time.Now())
+
 	replicasPtr, replicasSize := cPrivateReplicaInfos(privateSectorInfo.Values())
 	defer C.free(unsafe.Pointer(replicasPtr))
 
@@ -675,7 +1162,10 @@ func GeneratePoSt(
 		return nil, errors.New(C.GoString(resPtr.error_msg))
 	}
 
-	return goBytes(resPtr.flattened_proofs_ptr, resPtr.flattened_proofs_len), nil
+	proof := goBytes(resPtr.flattened_proofs_ptr, resPtr.flattened_proofs_len)
+	observeProofSize(OpGeneratePoSt, proof)
+
+	return proof, nil
 }
 
 // SingleProofPartitionProofLen denotes the number of bytes in a proof generated
@@ -683,6 +1173,24 @@ func GeneratePoSt(
 // with the number of partitions used when creating that proof.
 const SingleProofPartitionProofLen = 192
 
+// SealProofLen returns the length of a seal proof made with
+// poRepProofPartitions partitions, so callers can allocate for it
+func SealProofLen(poRepProofPartitions uint8) int {
+	return int(poRepProofPartitions) * SingleProofPartitionProofLen
+}
+
+// ProofPartitions returns the number of partitions a seal proof or PoSt was
+// made with, which the verifier infers from its length. Returns an error for
+// a length no proof can have, saving a call the verifier would fail.
+func ProofPartitions(proof []byte) (uint8, error) {
+	partitions := len(proof) / SingleProofPartitionProofLen
+	if len(proof) == 0 || len(proof)%SingleProofPartitionProofLen != 0 || partitions > math.MaxUint8 {
+		return 0, errors.Errorf("proof length must be a multiple of %d bytes, for 1 to %d partitions, got %d", SingleProofPartitionProofLen, math.MaxUint8, len(proof))
+	}
+
+	return uint8(partitions), nil
+}
+
 func cPublicPieceInfo(src []PublicPieceInfo) (*C.FFIPublicPieceInfo, C.size_t) {
 	srcCSizeT := C.size_t(len(src))
 
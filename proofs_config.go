@@ -0,0 +1,89 @@
+package ffi
+
+import (
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ProofsConfig configures the Rust proofs library. Zero-valued fields leave
+// the corresponding setting, and whatever the environment says about it,
+// untouched.
+type ProofsConfig struct {
+	// ParameterCacheDir is where Groth16 parameters are read from
+	// (FIL_PROOFS_PARAMETER_CACHE)
+	ParameterCacheDir string
+
+	// MaximizeCaching keeps more of the replication graph in memory, trading
+	// RAM for sealing speed (FIL_PROOFS_MAXIMIZE_CACHING)
+	MaximizeCaching bool
+
+	// NumProvingThreads caps the threads used to synthesize proofs
+	// (FIL_PROOFS_NUM_PROVING_THREADS)
+	NumProvingThreads int
+
+	// LogLevel is the Rust log filter, e.g. "info" (RUST_LOG)
+	LogLevel string
+}
+
+// ErrProofsCalled is returned by ApplyProofsConfig once a proofs function has
+// been called
+var ErrProofsCalled = errors.New("proofs functions have already been called")
+
+// proofsCalledLk makes ApplyProofsConfig and the first proofs call mutually
+// exclusive, since setting the environment while Rust reads it is a data race.
+var (
+	proofsCalledLk sync.Mutex
+	proofsCalled   bool
+)
+
+// markProofsCalled must be called by every proofs function before it calls
+// into Rust
+func markProofsCalled() {
+	proofsCalledLk.Lock()
+	defer proofsCalledLk.Unlock()
+
+	proofsCalled = true
+}
+
+// ApplyProofsConfig applies cfg by setting the environment variables the Rust
+// side reads its settings from. The Rust side reads them once, the first time
+// they're needed, so ApplyProofsConfig has to be called before any proofs
+// function; afterwards it returns ErrProofsCalled and changes nothing.
+func ApplyProofsConfig(cfg ProofsConfig) error {
+	proofsCalledLk.Lock()
+	defer proofsCalledLk.Unlock()
+
+	if proofsCalled {
+		return ErrProofsCalled
+	}
+
+	if cfg.NumProvingThreads < 0 {
+		return errors.Errorf("number of proving threads must not be negative, got %d", cfg.NumProvingThreads)
+	}
+
+	settings := map[string]string{}
+	if cfg.ParameterCacheDir != "" {
+		settings["FIL_PROOFS_PARAMETER_CACHE"] = cfg.ParameterCacheDir
+	}
+	if cfg.MaximizeCaching {
+		settings["FIL_PROOFS_MAXIMIZE_CACHING"] = "true"
+	}
+	if cfg.NumProvingThreads > 0 {
+		settings["FIL_PROOFS_NUM_PROVING_THREADS"] = strconv.Itoa(cfg.NumProvingThreads)
+	}
+	if cfg.LogLevel != "" {
+		settings["RUST_LOG"] = cfg.LogLevel
+	}
+
+	// with cgo, os.Setenv also updates the C environment Rust reads
+	for key, value := range settings {
+		if err := os.Setenv(key, value); err != nil {
+			return errors.Wrapf(err, "failed to set %s", key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package ffi
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyProofsConfig(t *testing.T) {
+	defer os.Unsetenv("FIL_PROOFS_MAXIMIZE_CACHING")    // nolint: errcheck
+	defer os.Unsetenv("FIL_PROOFS_NUM_PROVING_THREADS") // nolint: errcheck
+
+	// earlier tests may already have called proofs functions
+	proofsCalledLk.Lock()
+	proofsCalled = false
+	proofsCalledLk.Unlock()
+
+	// zero values leave the environment alone
+	require.NoError(t, os.Setenv("FIL_PROOFS_NUM_PROVING_THREADS", "3"))
+	require.NoError(t, ApplyProofsConfig(ProofsConfig{MaximizeCaching: true}))
+	assert.Equal(t, "true", os.Getenv("FIL_PROOFS_MAXIMIZE_CACHING"))
+	assert.Equal(t, "3", os.Getenv("FIL_PROOFS_NUM_PROVING_THREADS"))
+
+	require.NoError(t, ApplyProofsConfig(ProofsConfig{NumProvingThreads: 8}))
+	assert.Equal(t, "8", os.Getenv("FIL_PROOFS_NUM_PROVING_THREADS"))
+
+	assert.Error(t, ApplyProofsConfig(ProofsConfig{NumProvingThreads: -1}))
+
+	// once the proofs library may have read its settings, they're left alone
+	markProofsCalled()
+	assert.Equal(t, ErrProofsCalled, ApplyProofsConfig(ProofsConfig{NumProvingThreads: 4}))
+	assert.Equal(t, "8", os.Getenv("FIL_PROOFS_NUM_PROVING_THREADS"))
+}
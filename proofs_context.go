@@ -0,0 +1,184 @@
+package ffi
+
+import (
+	"context"
+)
+
+// The *WithContext variants below return as soon as their context is done.
+// The Rust call can't be interrupted, so it keeps running in the background
+// until it finishes on its own; its result is then discarded and everything
+// it allocated, on both sides of the FFI, is freed as usual. This lets a
+// caller shut down or move on without waiting hours for a seal or a proof,
+// but doesn't free the CPU, memory or disk the abandoned call is using.
+// Each call is traced as a span by the Tracer given to SetTracer, if any, and
+// waits for its resources from the Limiter given to SetLimiter, if any.
+
+// SealPreCommitWithContext is SealPreCommit, abandoned if ctx is done first
+func SealPreCommitWithContext(
+	ctx context.Context,
+	sectorSize uint64,
+	poRepProofPartitions uint8,
+	cacheDirPath string,
+	stagedSectorPath string,
+	sealedSectorPath string,
+	sectorID uint64,
+	proverID [32]byte,
+	ticket [32]byte,
+	pieces []PublicPieceInfo,
+) (RawSealPreCommitOutput, error) {
+	var output RawSealPreCommitOutput
+	err := abandonOnDone(ctx, OpSealPreCommit, func() (err error) {
+		output, err = SealPreCommit(sectorSize, poRepProofPartitions, cacheDirPath, stagedSectorPath, sealedSectorPath, sectorID, proverID, ticket, pieces)
+		return err
+	})
+	if err != nil {
+		return RawSealPreCommitOutput{}, err
+	}
+
+	return output, nil
+}
+
+// SealCommitWithContext is SealCommit, abandoned if ctx is done first
+func SealCommitWithContext(
+	ctx context.Context,
+	sectorSize uint64,
+	poRepProofPartitions uint8,
+	cacheDirPath string,
+	sectorID uint64,
+	proverID [32]byte,
+	ticket [32]byte,
+	seed [32]byte,
+	pieces []PublicPieceInfo,
+	rspco RawSealPreCommitOutput,
+) ([]byte, error) {
+	var proof []byte
+	err := abandonOnDone(ctx, OpSealCommit, func() (err error) {
+		proof, err = SealCommit(sectorSize, poRepProofPartitions, cacheDirPath, sectorID, proverID, ticket, seed, pieces, rspco)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// UnsealWithContext is Unseal, abandoned if ctx is done first. The output
+// file may be left partially written.
+func UnsealWithContext(
+	ctx context.Context,
+	sectorSize uint64,
+	poRepProofPartitions uint8,
+	cacheDirPath string,
+	sealedSectorPath string,
+	unsealOutputPath string,
+	sectorID uint64,
+	proverID [32]byte,
+	ticket [32]byte,
+	commD [CommitmentBytesLen]byte,
+) error {
+	return abandonOnDone(ctx, OpUnseal, func() error {
+		return Unseal(sectorSize, poRepProofPartitions, cacheDirPath, sealedSectorPath, unsealOutputPath, sectorID, proverID, ticket, commD)
+	})
+}
+
+// UnsealRangeWithContext is UnsealRange, abandoned if ctx is done first. The
+// output file may be left partially written.
+func UnsealRangeWithContext(
+	ctx context.Context,
+	sectorSize uint64,
+	poRepProofPartitions uint8,
+	cacheDirPath string,
+	sealedSectorPath string,
+	unsealOutputPath string,
+	sectorID uint64,
+	proverID [32]byte,
+	ticket [32]byte,
+	commD [CommitmentBytesLen]byte,
+	offset uint64,
+	len uint64,
+) error {
+	return abandonOnDone(ctx, OpUnsealRange, func() error {
+		return UnsealRange(sectorSize, poRepProofPartitions, cacheDirPath, sealedSectorPath, unsealOutputPath, sectorID, proverID, ticket, commD, offset, len)
+	})
+}
+
+// GenerateCandidatesWithContext is GenerateCandidates, abandoned if ctx is
+// done first
+func GenerateCandidatesWithContext(
+	ctx context.Context,
+	sectorSize uint64,
+	proverID [32]byte,
+	randomness [32]byte,
+	challengeCount uint64,
+	privateSectorInfo SortedPrivateSectorInfo,
+) ([]Candidate, error) {
+	var candidates []Candidate
+	err := abandonOnDone(ctx, OpGenerateCandidates, func() (err error) {
+		candidates, err = GenerateCandidates(sectorSize, proverID, randomness, challengeCount, privateSectorInfo)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return candidates, nil
+}
+
+// GeneratePoStWithContext is GeneratePoSt, abandoned if ctx is done first
+func GeneratePoStWithContext(
+	ctx context.Context,
+	sectorSize uint64,
+	proverID [32]byte,
+	privateSectorInfo SortedPrivateSectorInfo,
+	randomness [32]byte,
+	winners []Candidate,
+) ([]byte, error) {
+	var proof []byte
+	err := abandonOnDone(ctx, OpGeneratePoSt, func() (err error) {
+		proof, err = GeneratePoSt(sectorSize, proverID, privateSectorInfo, randomness, winners)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return proof, nil
+}
+
+// abandonOnDone runs op in a goroutine and waits for it or for ctx, whichever
+// comes first. op is traced as operation, and holds the resources the current
+// Limiter says operation needs until it returns. abandonOnDone returns op's
+// error, or ctx.Err() if op was abandoned, in which case the variables op
+// writes its results to must not be touched again: op is still running and
+// writing them.
+func abandonOnDone(ctx context.Context, operation string, op func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	endSpan := startSpan(ctx, operation)
+
+	release, err := acquireResources(ctx, operation)
+	if err != nil {
+		endSpan(err)
+		return err
+	}
+
+	var opErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer release()
+		opErr = op()
+	}()
+
+	select {
+	case <-done:
+		endSpan(opErr)
+		return opErr
+	case <-ctx.Done():
+		endSpan(ctx.Err())
+		return ctx.Err()
+	}
+}
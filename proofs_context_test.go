@@ -0,0 +1,61 @@
+package ffi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTracer struct {
+	spans map[string][]error
+}
+
+func (r *recordingTracer) StartSpan(ctx context.Context, operation string) func(err error) {
+	return func(err error) {
+		r.spans[operation] = append(r.spans[operation], err)
+	}
+}
+
+func TestAbandonOnDone(t *testing.T) {
+	tracer := &recordingTracer{spans: make(map[string][]error)}
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	ran := false
+	assert.NoError(t, abandonOnDone(context.Background(), OpUnseal, func() error {
+		ran = true
+		return nil
+	}))
+	assert.True(t, ran)
+
+	failure := errors.New("failure")
+	assert.Equal(t, failure, abandonOnDone(context.Background(), OpUnseal, func() error { return failure }))
+
+	// an operation outliving its context is abandoned
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	finished := make(chan struct{})
+
+	go cancel()
+	assert.Equal(t, context.Canceled, abandonOnDone(ctx, OpSealCommit, func() error {
+		<-release
+		close(finished)
+		return nil
+	}))
+
+	// and still runs to completion
+	close(release)
+	<-finished
+
+	// nothing is started once the context is done
+	assert.Equal(t, context.Canceled, abandonOnDone(ctx, OpSealCommit, func() error {
+		t.Fatal("operation started")
+		return nil
+	}))
+
+	// each started operation was traced, with its outcome
+	assert.Equal(t, []error{nil, failure}, tracer.spans[OpUnseal])
+	assert.Equal(t, []error{context.Canceled}, tracer.spans[OpSealCommit])
+}
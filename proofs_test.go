@@ -2,10 +2,12 @@ package ffi
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -13,6 +15,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/filecoin-ffi/piece"
 )
 
 func TestImportSector(t *testing.T) {
@@ -79,6 +83,35 @@ func TestImportSector(t *testing.T) {
 	commPA, err := GeneratePieceCommitmentFromFile(pieceFileA, 127)
 	require.NoError(t, err)
 
+	// streaming the piece gives the same commitment
+	commPFromReader, err := GeneratePieceCommitmentFromReader(bytes.NewReader(someBytes[0:127]), 127)
+	require.NoError(t, err)
+	require.Equal(t, commPA, commPFromReader)
+
+	_, err = GeneratePieceCommitmentFromReader(bytes.NewReader(someBytes[0:100]), 127)
+	require.Error(t, err)
+
+	// hashing chunks in parallel gives the same commitment
+	commPAllBytes, err := GeneratePieceCommitmentFromReader(bytes.NewReader(someBytes), 1016)
+	require.NoError(t, err)
+	commPParallel, err := GeneratePieceCommitmentParallel(bytes.NewReader(someBytes), 1016, 127, 3)
+	require.NoError(t, err)
+	require.Equal(t, commPAllBytes, commPParallel)
+
+	_, err = GeneratePieceCommitmentParallel(bytes.NewReader(someBytes), 1016, 100, 3)
+	require.Error(t, err)
+
+	// a multiple of the chunk size isn't enough, the piece must be a whole tree
+	_, err = GeneratePieceCommitmentParallel(bytes.NewReader(someBytes), 381, 127, 3)
+	require.Error(t, err)
+
+	// zero pieces match the precomputed table
+	zeroCommP, err := GeneratePieceCommitmentFromReader(bytes.NewReader(make([]byte, 508)), 508)
+	require.NoError(t, err)
+	expectedZeroCommP, err := piece.ZeroPieceCommitment(512)
+	require.NoError(t, err)
+	require.Equal(t, [CommitmentBytesLen]byte(expectedZeroCommP), zeroCommP)
+
 	// seek back to head (generating piece commitment moves offset)
 	_, err = pieceFileA.Seek(0, 0)
 	require.NoError(t, err)
@@ -137,11 +170,37 @@ func TestImportSector(t *testing.T) {
 	proof, err := SealCommit(sectorSize, poRepProofPartitions, sectorCacheDirPath, sectorID, proverID, ticket.TicketBytes, seed.TicketBytes, publicPieces, output)
 	require.NoError(t, err)
 
+	partitions, err := ProofPartitions(proof)
+	require.NoError(t, err)
+	require.Equal(t, poRepProofPartitions, partitions)
+	require.Equal(t, SealProofLen(poRepProofPartitions), len(proof))
+
 	// verify the 'ole proofy
 	isValid, err := VerifySeal(sectorSize, output.CommR, output.CommD, proverID, ticket.TicketBytes, seed.TicketBytes, sectorID, proof)
 	require.NoError(t, err)
 	require.True(t, isValid, "proof wasn't valid")
 
+	// verify it again in a batch, next to a proof with the wrong seed and a
+	// malformed one
+	sealInfo := SealVerifyInfo{
+		SectorSize: sectorSize,
+		CommR:      output.CommR,
+		CommD:      output.CommD,
+		ProverID:   proverID,
+		Ticket:     ticket.TicketBytes,
+		Seed:       seed.TicketBytes,
+		SectorID:   sectorID,
+		Proof:      proof,
+	}
+	wrongSeed := sealInfo
+	wrongSeed.Seed = ticket.TicketBytes
+	malformed := sealInfo
+	malformed.Proof = proof[1:]
+
+	results, err := VerifySealBatch([]SealVerifyInfo{sealInfo, wrongSeed, malformed})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false, false}, results)
+
 	// unseal the entire sector and verify that things went as we planned
 	require.NoError(t, Unseal(sectorSize, poRepProofPartitions, sectorCacheDirPath, sealedSectorFile.Name(), unsealOutputFileA.Name(), sectorID, proverID, ticket.TicketBytes, output.CommD))
 	contents, err := ioutil.ReadFile(unsealOutputFileA.Name())
@@ -155,6 +214,22 @@ func TestImportSector(t *testing.T) {
 	require.Equal(t, alignment, contents[127:508])
 	require.Equal(t, someBytes[0:508], contents[508:1016])
 
+	// recover it again through the bulk API
+	var recovered bytes.Buffer
+	errs := BulkUnseal(context.Background(), []UnsealRequest{{
+		SectorSize:           sectorSize,
+		PoRepProofPartitions: poRepProofPartitions,
+		CacheDirPath:         sectorCacheDirPath,
+		SealedSectorPath:     sealedSectorFile.Name(),
+		SectorID:             sectorID,
+		ProverID:             proverID,
+		Ticket:               ticket.TicketBytes,
+		CommD:                output.CommD,
+		Output:               &recovered,
+	}}, 0, 1<<20)
+	require.Equal(t, []error{nil}, errs)
+	require.Equal(t, contents, recovered.Bytes())
+
 	// unseal just the first piece
 	err = UnsealRange(sectorSize, poRepProofPartitions, sectorCacheDirPath, sealedSectorFile.Name(), unsealOutputFileB.Name(), sectorID, proverID, ticket.TicketBytes, output.CommD, 0, 127)
 	require.NoError(t, err)
@@ -171,6 +246,12 @@ func TestImportSector(t *testing.T) {
 	require.Equal(t, 508, len(contentsC))
 	require.Equal(t, someBytes[0:508], contentsC[0:508])
 
+	// unseal the second piece again, straight into memory
+	var contentsD bytes.Buffer
+	err = UnsealRangeToWriter(sectorSize, poRepProofPartitions, sectorCacheDirPath, sealedSectorFile.Name(), &contentsD, os.TempDir(), sectorID, proverID, ticket.TicketBytes, output.CommD, 508, 508)
+	require.NoError(t, err)
+	require.Equal(t, contentsC, contentsD.Bytes())
+
 	// verify that the sector builder owns no sealed sectors
 	var sealedSectorPaths []string
 	require.NoError(t, filepath.Walk(sealedSectorsDir, visit(&sealedSectorPaths)))
@@ -181,6 +262,9 @@ func TestImportSector(t *testing.T) {
 	require.NoError(t, filepath.Walk(sectorCacheRootDir, visit(&sectorCacheDirPaths)))
 	assert.Equal(t, 1, len(sectorCacheDirPaths), sectorCacheDirPaths)
 
+	// PoSt only needs what ClearCache leaves behind
+	require.NoError(t, ClearCache(sectorCacheDirPath))
+
 	// generate a PoSt over the proving set before importing, just to exercise
 	// the new API
 	privateInfo := NewSortedPrivateSectorInfo(PrivateSectorInfo{
@@ -190,6 +274,9 @@ func TestImportSector(t *testing.T) {
 		SealedSectorPath: sealedSectorFile.Name(),
 	})
 
+	// the sealed sector and what's left of its cache are provable
+	require.NoError(t, CheckProvable(sectorSize, privateInfo.Values()[0]))
+
 	publicInfo := NewSortedPublicSectorInfo(PublicSectorInfo{
 		SectorID: sectorID,
 		CommR:    output.CommR,
@@ -209,6 +296,50 @@ func TestImportSector(t *testing.T) {
 	isValid, err = VerifyPoSt(sectorSize, publicInfo, randomness, challengeCount, proofA, candidatesA, proverID)
 	require.NoError(t, err)
 	require.True(t, isValid, "VerifyPoSt rejected the (standalone) proof as invalid")
+
+	// verify it again in a batch, next to one claimed by another prover
+	postInfo := PoStVerifyInfo{
+		SectorSize:     sectorSize,
+		SectorInfo:     publicInfo,
+		Randomness:     randomness,
+		ChallengeCount: challengeCount,
+		Proof:          proofA,
+		Winners:        candidatesA,
+		ProverID:       proverID,
+	}
+	otherProver := postInfo
+	otherProver.ProverID = [32]byte{}
+
+	results, err = VerifyPoStBatch([]PoStVerifyInfo{postInfo, otherProver})
+	require.NoError(t, err)
+	require.Equal(t, []bool{true, false}, results)
+}
+
+func TestProofPartitions(t *testing.T) {
+	for _, partitions := range []uint8{1, 2, 10, math.MaxUint8} {
+		inferred, err := ProofPartitions(make([]byte, SealProofLen(partitions)))
+		require.NoError(t, err)
+		assert.Equal(t, partitions, inferred)
+	}
+
+	for _, length := range []int{0, 1, SingleProofPartitionProofLen + 1, SingleProofPartitionProofLen * 256} {
+		_, err := ProofPartitions(make([]byte, length))
+		assert.Error(t, err, length)
+	}
+}
+
+func TestSupportedSectorSizes(t *testing.T) {
+	infos := SupportedSectorSizes()
+	require.NotEmpty(t, infos)
+
+	for _, info := range infos {
+		assert.True(t, info.PoRepProofPartitions > 0)
+		assert.True(t, info.PoRepProofBytesPerPartition > 0)
+		assert.True(t, info.MaxUserBytes < info.SectorSize)
+	}
+
+	assert.True(t, IsSupportedSectorSize(1024))
+	assert.False(t, IsSupportedSectorSize(1000))
 }
 
 func TestJsonMarshalSymmetry(t *testing.T) {
@@ -1,16 +1,24 @@
+use std::collections::{HashSet, VecDeque};
 use std::slice::from_raw_parts;
+use std::sync::{Arc, Condvar, Mutex, RwLock};
 
+use blake2b_simd::Params as Blake2bParams;
 use bls_signatures::{
     aggregate as aggregate_sig,
     groupy::{CurveAffine, CurveProjective, EncodedPoint, GroupDecodingError},
     hash as hash_sig,
-    paired::bls12_381::{G2Affine, G2Compressed},
+    paired::bls12_381::{Bls12, Fq12, Fr, G1Affine, G2Affine, G2Compressed, G1, G2},
+    paired::Engine,
     verify as verify_sig, PrivateKey, PublicKey, Serialize, Signature,
 };
+use ff::Field;
 use libc;
 use rand::rngs::OsRng;
+use rand::SeedableRng;
+use rand_chacha::ChaChaRng;
 
 use rayon::prelude::*;
+use rayon::{ThreadPool, ThreadPoolBuilder};
 
 use crate::bls::types;
 
@@ -24,6 +32,15 @@ pub type BLSPrivateKey = [u8; PRIVATE_KEY_BYTES];
 pub type BLSPublicKey = [u8; PUBLIC_KEY_BYTES];
 pub type BLSDigest = [u8; DIGEST_BYTES];
 
+/// Prefix of the message signed to prove possession of a private key. The
+/// signing functions refuse messages starting with it, so that an ordinary
+/// signature can't be used as a proof of possession.
+pub const POP_DOMAIN: &[u8] = b"BLS_POP_BLS12381G2_FILECOIN_FFI_";
+
+pub const VRF_OUTPUT_BYTES: usize = 32;
+
+pub type VRFOutput = [u8; VRF_OUTPUT_BYTES];
+
 /// Unwraps or returns the passed in value.
 macro_rules! try_ffi {
     ($res:expr, $val:expr) => {{
@@ -34,6 +51,70 @@ macro_rules! try_ffi {
     }};
 }
 
+lazy_static! {
+    /// Pool the parallel BLS functions run on, if one has been configured with
+    /// `bls_set_num_threads`. Otherwise they run on rayon's global pool.
+    static ref THREAD_POOL: RwLock<Option<Arc<ThreadPool>>> = RwLock::new(None);
+
+    /// Results of `verify_async` calls, waiting to be picked up by
+    /// `verify_async_next`.
+    static ref VERIFY_COMPLETIONS: (Mutex<VecDeque<types::VerifyCompletion>>, Condvar) =
+        (Mutex::new(VecDeque::new()), Condvar::new());
+}
+
+/// Set the number of threads used by the parallel BLS functions, or pass 0 to
+/// go back to rayon's global pool, which has a thread per core
+///
+/// Calls already in progress finish on the pool they started on.
+///
+/// # Arguments
+///
+/// * `num_threads` - number of threads
+///
+/// Returns 0 if the pool could not be created.
+#[no_mangle]
+pub unsafe extern "C" fn bls_set_num_threads(num_threads: libc::size_t) -> libc::c_int {
+    let pool = if num_threads == 0 {
+        None
+    } else {
+        match ThreadPoolBuilder::new().num_threads(num_threads).build() {
+            Ok(pool) => Some(Arc::new(pool)),
+            Err(_) => return 0,
+        }
+    };
+
+    *THREAD_POOL.write().expect("thread pool lock poisoned") = pool;
+
+    1
+}
+
+/// Run `f` on the configured thread pool, so that any parallel work it does is
+/// limited to that pool's threads.
+fn in_pool<R: Send, F: FnOnce() -> R + Send>(f: F) -> R {
+    let pool = THREAD_POOL
+        .read()
+        .expect("thread pool lock poisoned")
+        .clone();
+
+    match pool {
+        Some(pool) => pool.install(f),
+        None => f(),
+    }
+}
+
+/// Run `f` in the background on the configured thread pool.
+fn spawn_in_pool<F: FnOnce() + Send + 'static>(f: F) {
+    let pool = THREAD_POOL
+        .read()
+        .expect("thread pool lock poisoned")
+        .clone();
+
+    match pool {
+        Some(pool) => pool.spawn(f),
+        None => rayon::spawn(f),
+    }
+}
+
 /// Compute the digest of a message
 ///
 /// # Arguments
@@ -74,11 +155,15 @@ pub unsafe extern "C" fn aggregate(
     flattened_signatures_len: libc::size_t,
 ) -> *mut types::AggregateResponse {
     // prep request
+    let raw_signatures = from_raw_parts(flattened_signatures_ptr, flattened_signatures_len);
+
     let signatures = try_ffi!(
-        from_raw_parts(flattened_signatures_ptr, flattened_signatures_len)
-            .par_chunks(SIGNATURE_BYTES)
-            .map(|item| { Signature::from_bytes(item) })
-            .collect::<Result<Vec<_>, _>>(),
+        in_pool(|| {
+            raw_signatures
+                .par_chunks(SIGNATURE_BYTES)
+                .map(|item| Signature::from_bytes(item))
+                .collect::<Result<Vec<_>, _>>()
+        }),
         std::ptr::null_mut()
     );
 
@@ -94,6 +179,105 @@ pub unsafe extern "C" fn aggregate(
     Box::into_raw(Box::new(response))
 }
 
+/// Create an aggregator folding in signatures one at a time, to be fed with
+/// `aggregator_add` and finished with `aggregator_finish`
+///
+/// Result must be freed using `destroy_aggregator`.
+#[no_mangle]
+pub unsafe extern "C" fn aggregator_new() -> *mut types::Aggregator {
+    Box::into_raw(Box::new(types::Aggregator {
+        signature: G2::zero(),
+    }))
+}
+
+/// Add a signature to the aggregate held by an aggregator
+///
+/// # Arguments
+///
+/// * `aggregator_ptr` - pointer to an aggregator created by `aggregator_new`
+/// * `signature_ptr` - pointer to a signature byte array (SIGNATURE_BYTES long)
+///
+/// Returns 0 and leaves the aggregate unchanged if the signature can't be
+/// decoded, 1 otherwise.
+#[no_mangle]
+pub unsafe extern "C" fn aggregator_add(
+    aggregator_ptr: *mut types::Aggregator,
+    signature_ptr: *const u8,
+) -> libc::c_int {
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(Signature::from_bytes(raw_signature), 0);
+
+    (*aggregator_ptr).signature.add_assign(&G2::from(signature));
+
+    1
+}
+
+/// Return the aggregate of the signatures added to an aggregator so far
+///
+/// # Arguments
+///
+/// * `aggregator_ptr` - pointer to an aggregator created by `aggregator_new`
+///
+/// Result must be freed using `destroy_aggregate_response`.
+#[no_mangle]
+pub unsafe extern "C" fn aggregator_finish(
+    aggregator_ptr: *const types::Aggregator,
+) -> *mut types::AggregateResponse {
+    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    Signature::from((*aggregator_ptr).signature)
+        .write_bytes(&mut raw_signature.as_mut())
+        .expect("preallocated");
+
+    let response = types::AggregateResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Aggregate public keys together into a new public key
+///
+/// # Arguments
+///
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+///
+/// Returns `NULL` on error. Result must be freed using `destroy_aggregate_public_keys_response`.
+#[no_mangle]
+pub unsafe extern "C" fn aggregate_public_keys(
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+) -> *mut types::AggregatePublicKeysResponse {
+    // prep request
+    let raw_public_keys = from_raw_parts(flattened_public_keys_ptr, flattened_public_keys_len);
+
+    if raw_public_keys.len() % PUBLIC_KEY_BYTES != 0 {
+        return std::ptr::null_mut();
+    }
+
+    let public_key = try_ffi!(
+        in_pool(|| {
+            raw_public_keys
+                .par_chunks(PUBLIC_KEY_BYTES)
+                .map(|item| PublicKey::from_bytes(item))
+                .collect::<Result<Vec<_>, _>>()
+                .map(|public_keys| aggregate_public_keys_inner(&public_keys))
+        }),
+        std::ptr::null_mut()
+    );
+
+    let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
+    public_key
+        .write_bytes(&mut raw_public_key.as_mut())
+        .expect("preallocated");
+
+    let response = types::AggregatePublicKeysResponse {
+        public_key: raw_public_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
 /// Verify that a signature is the aggregated signature of hashes - pubkeys
 ///
 /// # Arguments
@@ -128,162 +312,2119 @@ pub unsafe extern "C" fn verify(
         return 0;
     }
 
-    let digests: Vec<_> = try_ffi!(
-        raw_digests
-            .par_chunks(DIGEST_BYTES)
-            .map(|item: &[u8]| {
-                let mut digest = G2Compressed::empty();
-                digest.as_mut().copy_from_slice(item);
+    in_pool(|| {
+        let digests: Vec<_> = try_ffi!(
+            raw_digests
+                .par_chunks(DIGEST_BYTES)
+                .map(|item: &[u8]| {
+                    let mut digest = G2Compressed::empty();
+                    digest.as_mut().copy_from_slice(item);
 
-                let affine: G2Affine = digest.into_affine()?;
-                let projective = affine.into_projective();
-                Ok(projective)
-            })
-            .collect::<Result<Vec<_>, GroupDecodingError>>(),
-        0
-    );
+                    let affine: G2Affine = digest.into_affine()?;
+                    let projective = affine.into_projective();
+                    Ok(projective)
+                })
+                .collect::<Result<Vec<_>, GroupDecodingError>>(),
+            0
+        );
 
-    let public_keys: Vec<_> = try_ffi!(
-        raw_public_keys
-            .par_chunks(PUBLIC_KEY_BYTES)
-            .map(|item| { PublicKey::from_bytes(item) })
-            .collect::<Result<_, _>>(),
-        0
-    );
+        let public_keys: Vec<_> = try_ffi!(
+            raw_public_keys
+                .par_chunks(PUBLIC_KEY_BYTES)
+                .map(|item| { PublicKey::from_bytes(item) })
+                .collect::<Result<_, _>>(),
+            0
+        );
 
-    verify_sig(&signature, digests.as_slice(), public_keys.as_slice()) as libc::c_int
+        verify_sig(&signature, digests.as_slice(), public_keys.as_slice()) as libc::c_int
+    })
 }
 
-/// Generate a new private key
+/// Decompress a public key into a handle which can be passed to the
+/// `*_with_public_key_handles` verification functions
 ///
 /// # Arguments
 ///
-/// * `raw_seed_ptr` - pointer to a seed byte array
+/// * `raw_public_key_ptr` - pointer to a public key byte array (PUBLIC_KEY_BYTES long)
+///
+/// Returns `NULL` if the public key can't be decoded. Result must be freed
+/// using `destroy_public_key_handle`.
 #[no_mangle]
-pub unsafe extern "C" fn private_key_generate() -> *mut types::PrivateKeyGenerateResponse {
-    let mut raw_private_key: [u8; PRIVATE_KEY_BYTES] = [0; PRIVATE_KEY_BYTES];
-    PrivateKey::generate(&mut OsRng)
-        .write_bytes(&mut raw_private_key.as_mut())
-        .expect("preallocated");
-
-    let response = types::PrivateKeyGenerateResponse {
-        private_key: raw_private_key,
-    };
+pub unsafe extern "C" fn public_key_deserialize(
+    raw_public_key_ptr: *const u8,
+) -> *mut types::PublicKeyHandle {
+    let raw_public_key = from_raw_parts(raw_public_key_ptr, PUBLIC_KEY_BYTES);
+    let public_key = try_ffi!(PublicKey::from_bytes(raw_public_key), std::ptr::null_mut());
 
-    Box::into_raw(Box::new(response))
+    Box::into_raw(Box::new(types::PublicKeyHandle { public_key }))
 }
 
-/// Sign a message with a private key and return the signature
+/// Verify that a signature is the aggregated signature of digests - pubkeys,
+/// taking the pubkeys as handles created by `public_key_deserialize`
 ///
 /// # Arguments
 ///
-/// * `raw_private_key_ptr` - pointer to a private key byte array
-/// * `message_ptr` - pointer to a message byte array
-/// * `message_len` - length of the byte array
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `flattened_digests_ptr`     - pointer to a byte array containing digests
+/// * `flattened_digests_len`     - length of the byte array (multiple of DIGEST_BYTES)
+/// * `public_key_handles_ptr`    - pointer to an array of public key handles
+/// * `public_key_handles_len`    - number of public key handles
+#[no_mangle]
+pub unsafe extern "C" fn verify_with_public_key_handles(
+    signature_ptr: *const u8,
+    flattened_digests_ptr: *const u8,
+    flattened_digests_len: libc::size_t,
+    public_key_handles_ptr: *const *const types::PublicKeyHandle,
+    public_key_handles_len: libc::size_t,
+) -> libc::c_int {
+    // prep request
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(Signature::from_bytes(raw_signature), 0);
+
+    let raw_digests = from_raw_parts(flattened_digests_ptr, flattened_digests_len);
+
+    if raw_digests.len() % DIGEST_BYTES != 0 {
+        return 0;
+    }
+
+    if raw_digests.len() / DIGEST_BYTES != public_key_handles_len {
+        return 0;
+    }
+
+    let public_keys = try_ffi!(
+        public_keys_from_handles(public_key_handles_ptr, public_key_handles_len),
+        0
+    );
+
+    in_pool(|| {
+        let digests: Vec<_> = try_ffi!(
+            raw_digests
+                .par_chunks(DIGEST_BYTES)
+                .map(|item: &[u8]| {
+                    let mut digest = G2Compressed::empty();
+                    digest.as_mut().copy_from_slice(item);
+
+                    let affine: G2Affine = digest.into_affine()?;
+                    Ok(affine.into_projective())
+                })
+                .collect::<Result<Vec<_>, GroupDecodingError>>(),
+            0
+        );
+
+        verify_sig(&signature, digests.as_slice(), public_keys.as_slice()) as libc::c_int
+    })
+}
+
+/// Verify that a signature is the aggregated signature of a single message
+/// signed by every pubkey, taking the pubkeys as handles created by
+/// `public_key_deserialize`. Callers must guard against rogue-key attacks.
 ///
-/// Returns `NULL` when passed invalid arguments.
+/// # Arguments
+///
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `message_ptr`               - pointer to a message byte array
+/// * `message_len`               - length of the byte array
+/// * `public_key_handles_ptr`    - pointer to an array of public key handles
+/// * `public_key_handles_len`    - number of public key handles
 #[no_mangle]
-pub unsafe extern "C" fn private_key_sign(
-    raw_private_key_ptr: *const u8,
+pub unsafe extern "C" fn fast_aggregate_verify_with_public_key_handles(
+    signature_ptr: *const u8,
     message_ptr: *const u8,
     message_len: libc::size_t,
-) -> *mut types::PrivateKeySignResponse {
+    public_key_handles_ptr: *const *const types::PublicKeyHandle,
+    public_key_handles_len: libc::size_t,
+) -> libc::c_int {
     // prep request
-    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
-    let private_key = try_ffi!(
-        PrivateKey::from_bytes(private_key_slice),
-        std::ptr::null_mut()
-    );
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(Signature::from_bytes(raw_signature), 0);
+
     let message = from_raw_parts(message_ptr, message_len);
 
-    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
-    PrivateKey::sign(&private_key, message)
-        .write_bytes(&mut raw_signature.as_mut())
-        .expect("preallocated");
+    if public_key_handles_len == 0 {
+        return 0;
+    }
 
-    let response = types::PrivateKeySignResponse {
-        signature: raw_signature,
-    };
+    let public_keys = try_ffi!(
+        public_keys_from_handles(public_key_handles_ptr, public_key_handles_len),
+        0
+    );
 
-    Box::into_raw(Box::new(response))
+    in_pool(|| {
+        let public_key = aggregate_public_keys_inner(&public_keys);
+
+        verify_sig(&signature, &[hash_sig(message)], &[public_key]) as libc::c_int
+    })
 }
 
-/// Generate the public key for a private key
+/// Start verifying that a signature is the aggregated signature of digests -
+/// pubkeys in the background, returning immediately. The arguments are copied,
+/// so they may be freed as soon as this returns.
 ///
-/// # Arguments
+/// The result is delivered, tagged with `id`, by `verify_async_next`.
 ///
-/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// # Arguments
 ///
-/// Returns `NULL` when passed invalid arguments.
+/// * `id`                        - caller-chosen identifier for the verification
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `flattened_digests_ptr`     - pointer to a byte array containing digests
+/// * `flattened_digests_len`     - length of the byte array (multiple of DIGEST_BYTES)
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
 #[no_mangle]
-pub unsafe extern "C" fn private_key_public_key(
-    raw_private_key_ptr: *const u8,
-) -> *mut types::PrivateKeyPublicKeyResponse {
-    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
-    let private_key = try_ffi!(
-        PrivateKey::from_bytes(private_key_slice),
-        std::ptr::null_mut()
-    );
-
-    let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
-    private_key
-        .public_key()
-        .write_bytes(&mut raw_public_key.as_mut())
-        .expect("preallocated");
+pub unsafe extern "C" fn verify_async(
+    id: u64,
+    signature_ptr: *const u8,
+    flattened_digests_ptr: *const u8,
+    flattened_digests_len: libc::size_t,
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+) {
+    // prep request
+    let signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES).to_vec();
+    let digests = from_raw_parts(flattened_digests_ptr, flattened_digests_len).to_vec();
+    let public_keys = from_raw_parts(flattened_public_keys_ptr, flattened_public_keys_len).to_vec();
 
-    let response = types::PrivateKeyPublicKeyResponse {
-        public_key: raw_public_key,
-    };
+    spawn_in_pool(move || {
+        let valid = verify(
+            signature.as_ptr(),
+            digests.as_ptr(),
+            digests.len(),
+            public_keys.as_ptr(),
+            public_keys.len(),
+        );
 
-    Box::into_raw(Box::new(response))
+        let (completions, ready) = &*VERIFY_COMPLETIONS;
+        completions
+            .lock()
+            .expect("verify completions lock poisoned")
+            .push_back(types::VerifyCompletion { id, valid });
+        ready.notify_one();
+    });
 }
 
-#[cfg(test)]
-mod tests {
-    use super::*;
+/// Wait for any verification started by `verify_async` to finish and return
+/// its result. Each result is returned exactly once, in order of completion.
+#[no_mangle]
+pub unsafe extern "C" fn verify_async_next() -> types::VerifyCompletion {
+    let (completions, ready) = &*VERIFY_COMPLETIONS;
+    let mut completions = completions
+        .lock()
+        .expect("verify completions lock poisoned");
 
-    #[test]
-    fn key_verification() {
-        unsafe {
-            let private_key = (*private_key_generate()).private_key;
-            let public_key = (*private_key_public_key(&private_key[0])).public_key;
-            let message = "hello world".as_bytes();
-            let digest = (*hash(&message[0], message.len())).digest;
-            let signature =
-                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
-            let verified = verify(
-                &signature[0],
-                &digest[0],
-                digest.len(),
-                &public_key[0],
-                public_key.len(),
-            );
+    loop {
+        if let Some(completion) = completions.pop_front() {
+            return completion;
+        }
 
-            assert_eq!(1, verified);
+        completions = ready
+            .wait(completions)
+            .expect("verify completions lock poisoned");
+    }
+}
 
-            let different_message = "bye world".as_bytes();
-            let different_digest = (*hash(&different_message[0], different_message.len())).digest;
-            let not_verified = verify(
-                &signature[0],
-                &different_digest[0],
-                different_digest.len(),
-                &public_key[0],
-                public_key.len(),
-            );
+/// Verify that a signature is the aggregated signature of digests - pubkeys,
+/// reporting why verification failed. Problems with the shape of the arguments
+/// are reported before problems with their contents.
+///
+/// # Arguments
+///
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `flattened_digests_ptr`     - pointer to a byte array containing digests
+/// * `flattened_digests_len`     - length of the byte array (multiple of DIGEST_BYTES)
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+#[no_mangle]
+pub unsafe extern "C" fn verify_with_reason(
+    signature_ptr: *const u8,
+    flattened_digests_ptr: *const u8,
+    flattened_digests_len: libc::size_t,
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+) -> types::VerifyStatus {
+    use types::VerifyStatus::*;
 
-            assert_eq!(0, not_verified);
+    // prep request
+    let raw_digests = from_raw_parts(flattened_digests_ptr, flattened_digests_len);
+    let raw_public_keys = from_raw_parts(flattened_public_keys_ptr, flattened_public_keys_len);
 
-            // garbage verification
-            let different_digest = vec![0, 1, 2, 3, 4];
-            let not_verified = verify(
+    if raw_digests.len() % DIGEST_BYTES != 0
+        || raw_public_keys.len() % PUBLIC_KEY_BYTES != 0
+        || raw_digests.len() / DIGEST_BYTES != raw_public_keys.len() / PUBLIC_KEY_BYTES
+    {
+        return VerifyLengthMismatch;
+    }
+
+    if raw_digests.is_empty() {
+        return VerifyNoDigests;
+    }
+
+    let unique: HashSet<&[u8]> = raw_digests.chunks(DIGEST_BYTES).collect();
+    if unique.len() != raw_digests.len() / DIGEST_BYTES {
+        return VerifyDuplicateDigest;
+    }
+
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(
+        Signature::from_bytes(raw_signature),
+        VerifyMalformedSignature
+    );
+
+    in_pool(|| {
+        let digests: Vec<_> = try_ffi!(
+            raw_digests
+                .par_chunks(DIGEST_BYTES)
+                .map(|item: &[u8]| {
+                    let mut digest = G2Compressed::empty();
+                    digest.as_mut().copy_from_slice(item);
+
+                    let affine: G2Affine = digest.into_affine()?;
+                    Ok(affine.into_projective())
+                })
+                .collect::<Result<Vec<_>, GroupDecodingError>>(),
+            VerifyMalformedDigest
+        );
+
+        let public_keys: Vec<_> = try_ffi!(
+            raw_public_keys
+                .par_chunks(PUBLIC_KEY_BYTES)
+                .map(|item| { PublicKey::from_bytes(item) })
+                .collect::<Result<_, _>>(),
+            VerifyMalformedPublicKey
+        );
+
+        if verify_sig(&signature, digests.as_slice(), public_keys.as_slice()) {
+            VerifyValid
+        } else {
+            VerifyPairingMismatch
+        }
+    })
+}
+
+/// Verify that a signature is the aggregated signature of the hashed messages
+/// - pubkeys. Messages are hashed internally and must be distinct.
+///
+/// # Arguments
+///
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `flattened_messages_ptr`    - pointer to a byte array containing the concatenated messages
+/// * `flattened_messages_len`    - length of the byte array
+/// * `message_sizes_ptr`         - pointer to an array containing the length of each message
+/// * `message_sizes_len`         - number of messages
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+#[no_mangle]
+pub unsafe extern "C" fn aggregate_verify(
+    signature_ptr: *const u8,
+    flattened_messages_ptr: *const u8,
+    flattened_messages_len: libc::size_t,
+    message_sizes_ptr: *const libc::size_t,
+    message_sizes_len: libc::size_t,
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+) -> libc::c_int {
+    aggregate_verify_with_scheme(
+        signature_ptr,
+        flattened_messages_ptr,
+        flattened_messages_len,
+        message_sizes_ptr,
+        message_sizes_len,
+        flattened_public_keys_ptr,
+        flattened_public_keys_len,
+        types::SigningScheme::SchemeBasic,
+    )
+}
+
+/// Verify that a signature is the aggregated signature of the hashed messages
+/// - pubkeys, with the messages signed under the given scheme. Under
+/// `SchemeBasic` the messages must be distinct.
+///
+/// # Arguments
+///
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `flattened_messages_ptr`    - pointer to a byte array containing the concatenated messages
+/// * `flattened_messages_len`    - length of the byte array
+/// * `message_sizes_ptr`         - pointer to an array containing the length of each message
+/// * `message_sizes_len`         - number of messages
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+/// * `scheme`                    - scheme the messages were signed under (a `SigningScheme`)
+#[no_mangle]
+pub unsafe extern "C" fn aggregate_verify_with_scheme(
+    signature_ptr: *const u8,
+    flattened_messages_ptr: *const u8,
+    flattened_messages_len: libc::size_t,
+    message_sizes_ptr: *const libc::size_t,
+    message_sizes_len: libc::size_t,
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+    scheme: u32,
+) -> libc::c_int {
+    // prep request
+    let scheme = try_ffi!(types::SigningScheme::from_raw(scheme), 0);
+
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(Signature::from_bytes(raw_signature), 0);
+
+    let messages = try_ffi!(
+        split_messages(
+            flattened_messages_ptr,
+            flattened_messages_len,
+            message_sizes_ptr,
+            message_sizes_len,
+        ),
+        0
+    );
+
+    let raw_public_keys = from_raw_parts(flattened_public_keys_ptr, flattened_public_keys_len);
+
+    if raw_public_keys.len() % PUBLIC_KEY_BYTES != 0 {
+        return 0;
+    }
+
+    if messages.len() != raw_public_keys.len() / PUBLIC_KEY_BYTES {
+        return 0;
+    }
+
+    in_pool(|| {
+        let digests: Vec<_> = messages
+            .into_par_iter()
+            .zip(raw_public_keys.par_chunks(PUBLIC_KEY_BYTES))
+            .map(|(message, raw_public_key)| match scheme {
+                types::SigningScheme::SchemeBasic => hash_sig(message),
+                types::SigningScheme::SchemeAugmented => {
+                    hash_sig(&augmented_message(raw_public_key, message))
+                }
+            })
+            .collect();
+
+        let public_keys: Vec<_> = try_ffi!(
+            raw_public_keys
+                .par_chunks(PUBLIC_KEY_BYTES)
+                .map(|item| { PublicKey::from_bytes(item) })
+                .collect::<Result<_, _>>(),
+            0
+        );
+
+        verify_sig(&signature, digests.as_slice(), public_keys.as_slice()) as libc::c_int
+    })
+}
+
+/// Verify that a signature is the aggregated signature of a single message
+/// signed by every pubkey. The pubkeys are aggregated before performing a
+/// single pairing check, so callers must guard against rogue-key attacks.
+///
+/// # Arguments
+///
+/// * `signature_ptr`             - pointer to a signature byte array (SIGNATURE_BYTES long)
+/// * `message_ptr`               - pointer to a message byte array
+/// * `message_len`               - length of the byte array
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+#[no_mangle]
+pub unsafe extern "C" fn fast_aggregate_verify(
+    signature_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+) -> libc::c_int {
+    // prep request
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(Signature::from_bytes(raw_signature), 0);
+
+    let message = from_raw_parts(message_ptr, message_len);
+
+    let raw_public_keys = from_raw_parts(flattened_public_keys_ptr, flattened_public_keys_len);
+
+    if raw_public_keys.is_empty() || raw_public_keys.len() % PUBLIC_KEY_BYTES != 0 {
+        return 0;
+    }
+
+    in_pool(|| {
+        let public_keys: Vec<_> = try_ffi!(
+            raw_public_keys
+                .par_chunks(PUBLIC_KEY_BYTES)
+                .map(|item| { PublicKey::from_bytes(item) })
+                .collect::<Result<_, _>>(),
+            0
+        );
+
+        let public_key = aggregate_public_keys_inner(&public_keys);
+
+        verify_sig(&signature, &[hash_sig(message)], &[public_key]) as libc::c_int
+    })
+}
+
+/// Verify many independent signatures of digests - pubkeys, returning whether
+/// each signature is valid. The signatures are checked together using
+/// randomized batching and only re-checked individually if the batch fails.
+///
+/// # Arguments
+///
+/// * `flattened_signatures_ptr`  - pointer to a byte array containing signatures
+/// * `flattened_signatures_len`  - length of the byte array (multiple of SIGNATURE_BYTES)
+/// * `flattened_digests_ptr`     - pointer to a byte array containing digests
+/// * `flattened_digests_len`     - length of the byte array (multiple of DIGEST_BYTES)
+/// * `flattened_public_keys_ptr` - pointer to a byte array containing public keys
+/// * `flattened_public_keys_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+///
+/// Returns `NULL` if the arrays don't contain the same number of items. Result
+/// must be freed using `destroy_batch_verify_response`.
+#[no_mangle]
+pub unsafe extern "C" fn batch_verify(
+    flattened_signatures_ptr: *const u8,
+    flattened_signatures_len: libc::size_t,
+    flattened_digests_ptr: *const u8,
+    flattened_digests_len: libc::size_t,
+    flattened_public_keys_ptr: *const u8,
+    flattened_public_keys_len: libc::size_t,
+) -> *mut types::BatchVerifyResponse {
+    // prep request
+    let raw_signatures = from_raw_parts(flattened_signatures_ptr, flattened_signatures_len);
+    let raw_digests = from_raw_parts(flattened_digests_ptr, flattened_digests_len);
+    let raw_public_keys = from_raw_parts(flattened_public_keys_ptr, flattened_public_keys_len);
+
+    if raw_signatures.len() % SIGNATURE_BYTES != 0
+        || raw_digests.len() % DIGEST_BYTES != 0
+        || raw_public_keys.len() % PUBLIC_KEY_BYTES != 0
+    {
+        return std::ptr::null_mut();
+    }
+
+    let n = raw_signatures.len() / SIGNATURE_BYTES;
+    if n != raw_digests.len() / DIGEST_BYTES || n != raw_public_keys.len() / PUBLIC_KEY_BYTES {
+        return std::ptr::null_mut();
+    }
+
+    let results: Vec<bool> = in_pool(|| {
+        // items which fail to decode are invalid and excluded from the batch
+        let items: Vec<Option<(Signature, G2, PublicKey)>> = raw_signatures
+            .par_chunks(SIGNATURE_BYTES)
+            .zip(raw_digests.par_chunks(DIGEST_BYTES))
+            .zip(raw_public_keys.par_chunks(PUBLIC_KEY_BYTES))
+            .map(|((raw_signature, raw_digest), raw_public_key)| {
+                let signature = Signature::from_bytes(raw_signature).ok()?;
+
+                let mut digest = G2Compressed::empty();
+                digest.as_mut().copy_from_slice(raw_digest);
+                let digest = digest.into_affine().ok()?.into_projective();
+
+                let public_key = PublicKey::from_bytes(raw_public_key).ok()?;
+                if G1::from(public_key).is_zero() {
+                    return None;
+                }
+
+                Some((signature, digest, public_key))
+            })
+            .collect();
+
+        let decoded: Vec<_> = items.iter().filter_map(|item| *item).collect();
+
+        if decoded.len() == n && batch_check(&decoded) {
+            vec![true; n]
+        } else {
+            items
+                .par_iter()
+                .map(|item| match item {
+                    Some((signature, digest, public_key)) => {
+                        verify_sig(signature, &[*digest], &[*public_key])
+                    }
+                    None => false,
+                })
+                .collect()
+        }
+    });
+
+    let results = results.into_boxed_slice();
+
+    let response = types::BatchVerifyResponse {
+        results_len: results.len(),
+        results_ptr: Box::into_raw(results) as *const bool,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Generate a new private key
+#[no_mangle]
+pub unsafe extern "C" fn private_key_generate() -> *mut types::PrivateKeyGenerateResponse {
+    let mut raw_private_key: [u8; PRIVATE_KEY_BYTES] = [0; PRIVATE_KEY_BYTES];
+    PrivateKey::generate(&mut OsRng)
+        .write_bytes(&mut raw_private_key.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeyGenerateResponse {
+        private_key: raw_private_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Generate a new private key deterministically from a seed
+///
+/// **Warning**: only use this function with seeds which are as secret and as
+/// random as the private key itself, e.g. for testing or deterministic wallets.
+///
+/// # Arguments
+///
+/// * `raw_seed_ptr` - pointer to a seed byte array (32 bytes long)
+#[no_mangle]
+pub unsafe extern "C" fn private_key_generate_with_seed(
+    raw_seed_ptr: *const u8,
+) -> *mut types::PrivateKeyGenerateResponse {
+    let mut seed = <ChaChaRng as SeedableRng>::Seed::default();
+    seed.copy_from_slice(from_raw_parts(raw_seed_ptr, seed.len()));
+
+    let mut raw_private_key: [u8; PRIVATE_KEY_BYTES] = [0; PRIVATE_KEY_BYTES];
+    PrivateKey::generate(&mut ChaChaRng::from_seed(seed))
+        .write_bytes(&mut raw_private_key.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeyGenerateResponse {
+        private_key: raw_private_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Generate a new private key inside a page of memory locked into RAM, so
+/// that the stored key is never written to swap, and return an opaque handle
+/// to it. The copies of the key this library makes on the stack while
+/// generating and signing are wiped after use, but the curve library may
+/// leave copies of its own in its stack frames, which are not locked.
+///
+/// Returns `NULL` if the memory could not be locked. Result must be freed
+/// using `destroy_locked_private_key`, which also wipes the key.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_generate_locked() -> *mut types::LockedPrivateKey {
+    let locked = match types::LockedPrivateKey::new() {
+        Some(locked) => locked,
+        None => return std::ptr::null_mut(),
+    };
+
+    let mut private_key = PrivateKey::generate(&mut OsRng);
+    private_key
+        .write_bytes(&mut std::slice::from_raw_parts_mut(
+            locked.private_key_ptr(),
+            PRIVATE_KEY_BYTES,
+        ))
+        .expect("preallocated");
+    wipe_private_key(&mut private_key);
+
+    Box::into_raw(Box::new(locked))
+}
+
+/// Sign a message with a locked private key and return the signature
+///
+/// # Arguments
+///
+/// * `locked_private_key_ptr` - pointer to a handle from `private_key_generate_locked`
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+///
+/// Returns `NULL` when passed a message starting with `POP_DOMAIN`.
+#[no_mangle]
+pub unsafe extern "C" fn locked_private_key_sign(
+    locked_private_key_ptr: *const types::LockedPrivateKey,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+) -> *mut types::PrivateKeySignResponse {
+    // prep request
+    let message = from_raw_parts(message_ptr, message_len);
+    if is_reserved_message(message) {
+        return std::ptr::null_mut();
+    }
+
+    let mut private_key = try_ffi!(
+        locked_private_key(locked_private_key_ptr),
+        std::ptr::null_mut()
+    );
+
+    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    PrivateKey::sign(&private_key, message)
+        .write_bytes(&mut raw_signature.as_mut())
+        .expect("preallocated");
+    wipe_private_key(&mut private_key);
+
+    let response = types::PrivateKeySignResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Generate the public key for a locked private key
+///
+/// # Arguments
+///
+/// * `locked_private_key_ptr` - pointer to a handle from `private_key_generate_locked`
+#[no_mangle]
+pub unsafe extern "C" fn locked_private_key_public_key(
+    locked_private_key_ptr: *const types::LockedPrivateKey,
+) -> *mut types::PrivateKeyPublicKeyResponse {
+    let mut private_key = try_ffi!(
+        locked_private_key(locked_private_key_ptr),
+        std::ptr::null_mut()
+    );
+
+    let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
+    private_key
+        .public_key()
+        .write_bytes(&mut raw_public_key.as_mut())
+        .expect("preallocated");
+    wipe_private_key(&mut private_key);
+
+    let response = types::PrivateKeyPublicKeyResponse {
+        public_key: raw_public_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Decode the private key held by a locked handle.
+unsafe fn locked_private_key(
+    locked_private_key_ptr: *const types::LockedPrivateKey,
+) -> Result<PrivateKey, bls_signatures::Error> {
+    PrivateKey::from_bytes(from_raw_parts(
+        (*locked_private_key_ptr).private_key_ptr(),
+        PRIVATE_KEY_BYTES,
+    ))
+}
+
+/// Overwrite `len` bytes at `ptr` with zeros in a way the compiler won't
+/// optimise away.
+pub(crate) unsafe fn wipe(ptr: *mut u8, len: usize) {
+    for i in 0..len {
+        std::ptr::write_volatile(ptr.add(i), 0);
+    }
+    std::sync::atomic::compiler_fence(std::sync::atomic::Ordering::SeqCst);
+}
+
+/// Wipe a decoded private key once it is no longer needed. The all-zero
+/// scalar left behind is still a valid `PrivateKey`.
+unsafe fn wipe_private_key(private_key: &mut PrivateKey) {
+    wipe(
+        private_key as *mut PrivateKey as *mut u8,
+        std::mem::size_of::<PrivateKey>(),
+    );
+}
+
+/// Sign a message with a private key and return the signature
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+///
+/// Returns `NULL` when passed invalid arguments or a message starting with
+/// `POP_DOMAIN`.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_sign(
+    raw_private_key_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+) -> *mut types::PrivateKeySignResponse {
+    // prep request
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+    let message = from_raw_parts(message_ptr, message_len);
+    if is_reserved_message(message) {
+        return std::ptr::null_mut();
+    }
+
+    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    PrivateKey::sign(&private_key, message)
+        .write_bytes(&mut raw_signature.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeySignResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Sign a message with a private key under the given scheme and return the
+/// signature
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+/// * `scheme` - scheme to sign the message under (a `SigningScheme`)
+///
+/// Returns `NULL` when passed invalid arguments, including an unknown scheme,
+/// or when the bytes signed under the scheme start with `POP_DOMAIN`.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_sign_with_scheme(
+    raw_private_key_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+    scheme: u32,
+) -> *mut types::PrivateKeySignResponse {
+    // prep request
+    let scheme = try_ffi!(types::SigningScheme::from_raw(scheme), std::ptr::null_mut());
+
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+    let message = from_raw_parts(message_ptr, message_len);
+
+    let signed = match scheme {
+        types::SigningScheme::SchemeBasic => message.to_vec(),
+        types::SigningScheme::SchemeAugmented => {
+            let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
+            private_key
+                .public_key()
+                .write_bytes(&mut raw_public_key.as_mut())
+                .expect("preallocated");
+
+            augmented_message(&raw_public_key, message)
+        }
+    };
+    if is_reserved_message(&signed) {
+        return std::ptr::null_mut();
+    }
+
+    let signature = PrivateKey::sign(&private_key, signed);
+
+    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    signature
+        .write_bytes(&mut raw_signature.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeySignResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Sign a message with a private key, writing the signature to a buffer
+/// provided by the caller instead of allocating a response
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+/// * `signature_ptr` - pointer to a buffer receiving the signature (SIGNATURE_BYTES long)
+///
+/// Returns 0 and leaves the buffer untouched when passed invalid arguments or a
+/// message starting with `POP_DOMAIN`, 1 otherwise.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_sign_into(
+    raw_private_key_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+    signature_ptr: *mut u8,
+) -> libc::c_int {
+    // prep request
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(PrivateKey::from_bytes(private_key_slice), 0);
+    let message = from_raw_parts(message_ptr, message_len);
+    if is_reserved_message(message) {
+        return 0;
+    }
+
+    let mut raw_signature = std::slice::from_raw_parts_mut(signature_ptr, SIGNATURE_BYTES);
+    PrivateKey::sign(&private_key, message)
+        .write_bytes(&mut raw_signature)
+        .expect("preallocated");
+
+    1
+}
+
+/// Sign many messages with a private key in parallel and return the
+/// signatures in order
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr`    - pointer to a private key byte array
+/// * `flattened_messages_ptr` - pointer to a byte array containing all messages
+/// * `flattened_messages_len` - length of the byte array
+/// * `message_sizes_ptr`      - pointer to the length of each message
+/// * `message_sizes_len`      - number of messages
+///
+/// Returns `NULL` when passed invalid arguments, including any message starting
+/// with `POP_DOMAIN`. Result must be freed using
+/// `destroy_private_key_sign_many_response`.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_sign_many(
+    raw_private_key_ptr: *const u8,
+    flattened_messages_ptr: *const u8,
+    flattened_messages_len: libc::size_t,
+    message_sizes_ptr: *const libc::size_t,
+    message_sizes_len: libc::size_t,
+) -> *mut types::PrivateKeySignManyResponse {
+    // prep request
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+
+    let messages = try_ffi!(
+        split_messages(
+            flattened_messages_ptr,
+            flattened_messages_len,
+            message_sizes_ptr,
+            message_sizes_len,
+        ),
+        std::ptr::null_mut()
+    );
+
+    if messages.iter().any(|message| is_reserved_message(message)) {
+        return std::ptr::null_mut();
+    }
+
+    // call method
+    let mut flattened_signatures = vec![0; messages.len() * SIGNATURE_BYTES];
+    in_pool(|| {
+        flattened_signatures
+            .par_chunks_mut(SIGNATURE_BYTES)
+            .zip(messages.into_par_iter())
+            .for_each(|(raw_signature, message)| {
+                PrivateKey::sign(&private_key, message)
+                    .write_bytes(&mut raw_signature.as_mut())
+                    .expect("preallocated");
+            })
+    });
+
+    // prep response
+    let flattened_signatures = flattened_signatures.into_boxed_slice();
+    let response = types::PrivateKeySignManyResponse {
+        flattened_signatures_len: flattened_signatures.len(),
+        flattened_signatures_ptr: Box::into_raw(flattened_signatures) as *const u8,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Sign each message with the private key at the same position, in parallel,
+/// and return the signatures in order
+///
+/// # Arguments
+///
+/// * `flattened_private_keys_ptr` - pointer to a byte array containing private keys
+/// * `flattened_private_keys_len` - length of the byte array (multiple of PRIVATE_KEY_BYTES)
+/// * `flattened_messages_ptr`     - pointer to a byte array containing all messages
+/// * `flattened_messages_len`     - length of the byte array
+/// * `message_sizes_ptr`          - pointer to the length of each message
+/// * `message_sizes_len`          - number of messages
+///
+/// Returns `NULL` when passed invalid arguments, including a different number
+/// of private keys and messages or any message starting with `POP_DOMAIN`.
+/// Result must be freed using `destroy_private_key_sign_many_response`.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_sign_multi(
+    flattened_private_keys_ptr: *const u8,
+    flattened_private_keys_len: libc::size_t,
+    flattened_messages_ptr: *const u8,
+    flattened_messages_len: libc::size_t,
+    message_sizes_ptr: *const libc::size_t,
+    message_sizes_len: libc::size_t,
+) -> *mut types::PrivateKeySignManyResponse {
+    // prep request
+    let raw_private_keys = from_raw_parts(flattened_private_keys_ptr, flattened_private_keys_len);
+
+    if raw_private_keys.len() % PRIVATE_KEY_BYTES != 0 {
+        return std::ptr::null_mut();
+    }
+
+    let private_keys: Vec<_> = try_ffi!(
+        raw_private_keys
+            .chunks(PRIVATE_KEY_BYTES)
+            .map(|item| { PrivateKey::from_bytes(item) })
+            .collect::<Result<_, _>>(),
+        std::ptr::null_mut()
+    );
+
+    let messages = try_ffi!(
+        split_messages(
+            flattened_messages_ptr,
+            flattened_messages_len,
+            message_sizes_ptr,
+            message_sizes_len,
+        ),
+        std::ptr::null_mut()
+    );
+
+    if messages.len() != private_keys.len()
+        || messages.iter().any(|message| is_reserved_message(message))
+    {
+        return std::ptr::null_mut();
+    }
+
+    // call method
+    let mut flattened_signatures = vec![0; messages.len() * SIGNATURE_BYTES];
+    in_pool(|| {
+        flattened_signatures
+            .par_chunks_mut(SIGNATURE_BYTES)
+            .zip(private_keys.par_iter().zip(messages.into_par_iter()))
+            .for_each(|(raw_signature, (private_key, message))| {
+                PrivateKey::sign(private_key, message)
+                    .write_bytes(&mut raw_signature.as_mut())
+                    .expect("preallocated");
+            })
+    });
+
+    // prep response
+    let flattened_signatures = flattened_signatures.into_boxed_slice();
+    let response = types::PrivateKeySignManyResponse {
+        flattened_signatures_len: flattened_signatures.len(),
+        flattened_signatures_ptr: Box::into_raw(flattened_signatures) as *const u8,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Generate the public key for a private key
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+///
+/// Returns `NULL` when passed invalid arguments.
+#[no_mangle]
+pub unsafe extern "C" fn private_key_public_key(
+    raw_private_key_ptr: *const u8,
+) -> *mut types::PrivateKeyPublicKeyResponse {
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+
+    let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
+    private_key
+        .public_key()
+        .write_bytes(&mut raw_public_key.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeyPublicKeyResponse {
+        public_key: raw_public_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Check that every signature is valid for its digest - pubkey pair by
+/// combining them with random scalars and performing a single multi-pairing.
+fn batch_check(items: &[(Signature, G2, PublicKey)]) -> bool {
+    if items.is_empty() {
+        return true;
+    }
+
+    let mut rng = OsRng;
+    let mut aggregated_signature = G2::zero();
+    let mut prepared = Vec::with_capacity(items.len() + 1);
+
+    for (signature, digest, public_key) in items {
+        let scalar = Fr::random(&mut rng);
+
+        let mut s = G2::from(*signature);
+        s.mul_assign(scalar);
+        aggregated_signature.add_assign(&s);
+
+        let mut p = G1::from(*public_key);
+        p.mul_assign(scalar);
+        prepared.push((p.into_affine().prepare(), digest.into_affine().prepare()));
+    }
+
+    let mut g1_neg = G1Affine::one();
+    g1_neg.negate();
+    prepared.push((
+        g1_neg.prepare(),
+        aggregated_signature.into_affine().prepare(),
+    ));
+
+    let terms: Vec<_> = prepared.iter().map(|(p, q)| (p, q)).collect();
+
+    match Bls12::final_exponentiation(&Bls12::miller_loop(&terms)) {
+        Some(result) => result == Fq12::one(),
+        None => false,
+    }
+}
+
+/// Sum the provided pubkeys into a single pubkey.
+fn aggregate_public_keys_inner(public_keys: &[PublicKey]) -> PublicKey {
+    public_keys
+        .par_iter()
+        .map(|public_key| G1::from(*public_key))
+        .reduce(G1::zero, |mut acc, public_key| {
+            acc.add_assign(&public_key);
+            acc
+        })
+        .into()
+}
+
+/// Copy the public keys out of an array of public key handles, failing if any
+/// of the handles is null.
+unsafe fn public_keys_from_handles(
+    public_key_handles_ptr: *const *const types::PublicKeyHandle,
+    public_key_handles_len: libc::size_t,
+) -> Result<Vec<PublicKey>, ()> {
+    if public_key_handles_len == 0 {
+        return Ok(Vec::new());
+    }
+
+    from_raw_parts(public_key_handles_ptr, public_key_handles_len)
+        .iter()
+        .map(|handle| handle.as_ref().map(|h| h.public_key).ok_or(()))
+        .collect()
+}
+
+/// Split a flattened byte array into messages of the provided sizes.
+unsafe fn split_messages<'a>(
+    flattened_messages_ptr: *const u8,
+    flattened_messages_len: libc::size_t,
+    message_sizes_ptr: *const libc::size_t,
+    message_sizes_len: libc::size_t,
+) -> Result<Vec<&'a [u8]>, ()> {
+    let flattened_messages = from_raw_parts(flattened_messages_ptr, flattened_messages_len);
+    let message_sizes = from_raw_parts(message_sizes_ptr, message_sizes_len);
+
+    if message_sizes.iter().sum::<usize>() != flattened_messages.len() {
+        return Err(());
+    }
+
+    let mut start = 0;
+    let mut messages = Vec::with_capacity(message_sizes.len());
+    for size in message_sizes {
+        messages.push(&flattened_messages[start..start + size]);
+        start += size;
+    }
+
+    Ok(messages)
+}
+
+/// Check that a private key is a canonical, non-zero scalar
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array (PRIVATE_KEY_BYTES long)
+#[no_mangle]
+pub unsafe extern "C" fn private_key_validate(raw_private_key_ptr: *const u8) -> libc::c_int {
+    let raw_private_key = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(PrivateKey::from_bytes(raw_private_key), 0);
+
+    !Fr::from(private_key).is_zero() as libc::c_int
+}
+
+/// Check that a public key decompresses to a non-identity point of the G1
+/// prime-order subgroup
+///
+/// # Arguments
+///
+/// * `raw_public_key_ptr` - pointer to a public key byte array (PUBLIC_KEY_BYTES long)
+#[no_mangle]
+pub unsafe extern "C" fn public_key_validate(raw_public_key_ptr: *const u8) -> libc::c_int {
+    let raw_public_key = from_raw_parts(raw_public_key_ptr, PUBLIC_KEY_BYTES);
+    let public_key = try_ffi!(PublicKey::from_bytes(raw_public_key), 0);
+
+    !G1::from(public_key).is_zero() as libc::c_int
+}
+
+/// Check that a signature decompresses to a non-identity point of the G2
+/// prime-order subgroup
+///
+/// # Arguments
+///
+/// * `signature_ptr` - pointer to a signature byte array (SIGNATURE_BYTES long)
+#[no_mangle]
+pub unsafe extern "C" fn signature_validate(signature_ptr: *const u8) -> libc::c_int {
+    let raw_signature = from_raw_parts(signature_ptr, SIGNATURE_BYTES);
+    let signature = try_ffi!(Signature::from_bytes(raw_signature), 0);
+
+    !G2Affine::from(signature).is_zero() as libc::c_int
+}
+
+/// Check that a digest decompresses to a non-identity point of the G2
+/// prime-order subgroup
+///
+/// # Arguments
+///
+/// * `digest_ptr` - pointer to a digest byte array (DIGEST_BYTES long)
+#[no_mangle]
+pub unsafe extern "C" fn digest_validate(digest_ptr: *const u8) -> libc::c_int {
+    let mut digest = G2Compressed::empty();
+    digest
+        .as_mut()
+        .copy_from_slice(from_raw_parts(digest_ptr, DIGEST_BYTES));
+
+    let affine: G2Affine = try_ffi!(digest.into_affine(), 0);
+
+    !affine.is_zero() as libc::c_int
+}
+
+/// Generate a proof of possession for the public key of a private key
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+///
+/// Returns `NULL` when passed invalid arguments.
+#[no_mangle]
+pub unsafe extern "C" fn pop_prove(raw_private_key_ptr: *const u8) -> *mut types::PopProveResponse {
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+
+    let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
+    private_key
+        .public_key()
+        .write_bytes(&mut raw_public_key.as_mut())
+        .expect("preallocated");
+
+    let mut raw_proof: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    PrivateKey::sign(&private_key, pop_message(&raw_public_key))
+        .write_bytes(&mut raw_proof.as_mut())
+        .expect("preallocated");
+
+    let response = types::PopProveResponse { proof: raw_proof };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Verify a proof of possession for a public key
+///
+/// # Arguments
+///
+/// * `raw_public_key_ptr` - pointer to a public key byte array (PUBLIC_KEY_BYTES long)
+/// * `proof_ptr`          - pointer to a proof byte array (SIGNATURE_BYTES long)
+#[no_mangle]
+pub unsafe extern "C" fn pop_verify(
+    raw_public_key_ptr: *const u8,
+    proof_ptr: *const u8,
+) -> libc::c_int {
+    let raw_public_key = from_raw_parts(raw_public_key_ptr, PUBLIC_KEY_BYTES);
+    let public_key = try_ffi!(PublicKey::from_bytes(raw_public_key), 0);
+
+    let raw_proof = from_raw_parts(proof_ptr, SIGNATURE_BYTES);
+    let proof = try_ffi!(Signature::from_bytes(raw_proof), 0);
+
+    verify_sig(
+        &proof,
+        &[hash_sig(&pop_message(raw_public_key))],
+        &[public_key],
+    ) as libc::c_int
+}
+
+/// The message signed by a proof of possession of a public key.
+fn pop_message(raw_public_key: &[u8]) -> Vec<u8> {
+    [POP_DOMAIN, raw_public_key].concat()
+}
+
+/// Whether a message is reserved for proofs of possession, and so must not be
+/// signed by the ordinary signing functions.
+fn is_reserved_message(message: &[u8]) -> bool {
+    message.starts_with(POP_DOMAIN)
+}
+
+/// The bytes signed for a message under `SchemeAugmented`.
+fn augmented_message(raw_public_key: &[u8], message: &[u8]) -> Vec<u8> {
+    [raw_public_key, message].concat()
+}
+
+/// Compute a VRF proof for an input. BLS signatures are unique, so, as in
+/// Filecoin, the proof is the plain signature of the input.
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// * `input_ptr`           - pointer to an input byte array
+/// * `input_len`           - length of the byte array
+///
+/// Returns `NULL` when passed an invalid private key or an input starting with
+/// `POP_DOMAIN`. Result must be freed using `destroy_vrf_compute_response`.
+#[no_mangle]
+pub unsafe extern "C" fn vrf_compute(
+    raw_private_key_ptr: *const u8,
+    input_ptr: *const u8,
+    input_len: libc::size_t,
+) -> *mut types::VrfComputeResponse {
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+    let input = from_raw_parts(input_ptr, input_len);
+    if is_reserved_message(input) {
+        return std::ptr::null_mut();
+    }
+
+    let mut raw_proof: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    PrivateKey::sign(&private_key, input)
+        .write_bytes(&mut raw_proof.as_mut())
+        .expect("preallocated");
+
+    let response = types::VrfComputeResponse { proof: raw_proof };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Verify a VRF proof for an input
+///
+/// # Arguments
+///
+/// * `raw_public_key_ptr` - pointer to a public key byte array (PUBLIC_KEY_BYTES long)
+/// * `proof_ptr`          - pointer to a proof byte array (SIGNATURE_BYTES long)
+/// * `input_ptr`          - pointer to an input byte array
+/// * `input_len`          - length of the byte array
+#[no_mangle]
+pub unsafe extern "C" fn vrf_verify(
+    raw_public_key_ptr: *const u8,
+    proof_ptr: *const u8,
+    input_ptr: *const u8,
+    input_len: libc::size_t,
+) -> libc::c_int {
+    let raw_public_key = from_raw_parts(raw_public_key_ptr, PUBLIC_KEY_BYTES);
+    let public_key = try_ffi!(PublicKey::from_bytes(raw_public_key), 0);
+
+    let raw_proof = from_raw_parts(proof_ptr, SIGNATURE_BYTES);
+    let proof = try_ffi!(Signature::from_bytes(raw_proof), 0);
+
+    let input = from_raw_parts(input_ptr, input_len);
+
+    verify_sig(&proof, &[hash_sig(input)], &[public_key]) as libc::c_int
+}
+
+/// Derive the VRF output from a proof, the blake2b-256 digest of its bytes
+///
+/// # Arguments
+///
+/// * `proof_ptr` - pointer to a proof byte array (SIGNATURE_BYTES long)
+///
+/// Result must be freed using `destroy_vrf_proof_to_hash_response`.
+#[no_mangle]
+pub unsafe extern "C" fn vrf_proof_to_hash(
+    proof_ptr: *const u8,
+) -> *mut types::VrfProofToHashResponse {
+    let raw_proof = from_raw_parts(proof_ptr, SIGNATURE_BYTES);
+
+    let mut output: VRFOutput = [0; VRF_OUTPUT_BYTES];
+    output.copy_from_slice(
+        Blake2bParams::new()
+            .hash_length(VRF_OUTPUT_BYTES)
+            .hash(raw_proof)
+            .as_bytes(),
+    );
+
+    let response = types::VrfProofToHashResponse { output };
+
+    Box::into_raw(Box::new(response))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn key_verification() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello world".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature =
+                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+            let verified = verify(
+                &signature[0],
+                &digest[0],
+                digest.len(),
+                &public_key[0],
+                public_key.len(),
+            );
+
+            assert_eq!(1, verified);
+
+            let different_message = "bye world".as_bytes();
+            let different_digest = (*hash(&different_message[0], different_message.len())).digest;
+            let not_verified = verify(
+                &signature[0],
+                &different_digest[0],
+                different_digest.len(),
+                &public_key[0],
+                public_key.len(),
+            );
+
+            assert_eq!(0, not_verified);
+
+            // garbage verification
+            let different_digest = vec![0, 1, 2, 3, 4];
+            let not_verified = verify(
+                &signature[0],
+                &different_digest[0],
+                different_digest.len(),
+                &public_key[0],
+                public_key.len(),
+            );
+
+            assert_eq!(0, not_verified);
+        }
+    }
+
+    #[test]
+    fn incremental_aggregation() {
+        unsafe {
+            let mut signatures = Vec::new();
+            for i in 0..4u8 {
+                let private_key = (*private_key_generate()).private_key;
+                let message = [i; 8];
+                let signature =
+                    (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+                signatures.extend_from_slice(&signature);
+            }
+            let expected = (*aggregate(signatures.as_ptr(), signatures.len())).signature;
+
+            let aggregator = aggregator_new();
+            for signature in signatures.chunks(SIGNATURE_BYTES) {
+                assert_eq!(1, aggregator_add(aggregator, signature.as_ptr()));
+            }
+
+            let garbage = [0xffu8; SIGNATURE_BYTES];
+            assert_eq!(0, aggregator_add(aggregator, garbage.as_ptr()));
+
+            let signature = (*aggregator_finish(aggregator)).signature;
+            assert_eq!(&expected[..], &signature[..]);
+
+            types::destroy_aggregator(aggregator);
+        }
+    }
+
+    #[test]
+    fn public_key_handles() {
+        unsafe {
+            let mut handles = Vec::new();
+            let mut digests = Vec::new();
+            let mut signatures = Vec::new();
+            let message = "hello handle world".as_bytes();
+            let mut message_signatures = Vec::new();
+            for i in 0..3u8 {
+                let private_key = (*private_key_generate()).private_key;
+                let public_key = (*private_key_public_key(&private_key[0])).public_key;
+                handles.push(public_key_deserialize(&public_key[0]) as *const _);
+
+                let own_message = [i; 8];
+                digests.extend_from_slice(&(*hash(&own_message[0], own_message.len())).digest);
+                signatures.extend_from_slice(
+                    &(*private_key_sign(&private_key[0], &own_message[0], own_message.len()))
+                        .signature,
+                );
+                message_signatures.extend_from_slice(
+                    &(*private_key_sign(&private_key[0], &message[0], message.len())).signature,
+                );
+            }
+
+            let signature = (*aggregate(signatures.as_ptr(), signatures.len())).signature;
+            assert_eq!(
+                1,
+                verify_with_public_key_handles(
+                    &signature[0],
+                    digests.as_ptr(),
+                    digests.len(),
+                    handles.as_ptr(),
+                    handles.len(),
+                )
+            );
+            assert_eq!(
+                0,
+                verify_with_public_key_handles(
+                    &signature[0],
+                    digests.as_ptr(),
+                    digests.len(),
+                    handles.as_ptr(),
+                    handles.len() - 1,
+                )
+            );
+
+            let signature =
+                (*aggregate(message_signatures.as_ptr(), message_signatures.len())).signature;
+            assert_eq!(
+                1,
+                fast_aggregate_verify_with_public_key_handles(
+                    &signature[0],
+                    &message[0],
+                    message.len(),
+                    handles.as_ptr(),
+                    handles.len(),
+                )
+            );
+
+            let garbage = [0xffu8; PUBLIC_KEY_BYTES];
+            assert!(public_key_deserialize(&garbage[0]).is_null());
+
+            for handle in handles {
+                types::destroy_public_key_handle(handle as *mut _);
+            }
+        }
+    }
+
+    #[test]
+    fn augmented_scheme() {
+        use types::SigningScheme::*;
+
+        unsafe {
+            let message = "hello augmented world".as_bytes();
+            let mut signatures = Vec::new();
+            let mut public_keys = Vec::new();
+            for _ in 0..2 {
+                let private_key = (*private_key_generate()).private_key;
+                public_keys
+                    .extend_from_slice(&(*private_key_public_key(&private_key[0])).public_key);
+                signatures.extend_from_slice(
+                    &(*private_key_sign_with_scheme(
+                        &private_key[0],
+                        &message[0],
+                        message.len(),
+                        SchemeAugmented as u32,
+                    ))
+                    .signature,
+                );
+            }
+            let signature = (*aggregate(signatures.as_ptr(), signatures.len())).signature;
+
+            // the same message twice is fine once augmented
+            let messages = [message, message].concat();
+            let sizes = [message.len(), message.len()];
+            let check = |scheme| {
+                aggregate_verify_with_scheme(
+                    &signature[0],
+                    messages.as_ptr(),
+                    messages.len(),
+                    sizes.as_ptr(),
+                    sizes.len(),
+                    public_keys.as_ptr(),
+                    public_keys.len(),
+                    scheme,
+                )
+            };
+
+            assert_eq!(1, check(SchemeAugmented as u32));
+            assert_eq!(0, check(SchemeBasic as u32));
+
+            // unknown schemes are refused
+            assert_eq!(0, check(2));
+            let private_key = (*private_key_generate()).private_key;
+            assert!(
+                private_key_sign_with_scheme(&private_key[0], &message[0], message.len(), 2)
+                    .is_null()
+            );
+        }
+    }
+
+    #[test]
+    fn asynchronous_verification() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello async world".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature =
+                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+            let garbage = [0u8; SIGNATURE_BYTES];
+
+            for id in 0..4u64 {
+                let signature = if id % 2 == 0 { &signature } else { &garbage };
+                verify_async(
+                    id,
+                    &signature[0],
+                    &digest[0],
+                    DIGEST_BYTES,
+                    &public_key[0],
+                    PUBLIC_KEY_BYTES,
+                );
+            }
+
+            let mut seen = HashSet::new();
+            for _ in 0..4 {
+                let completion = verify_async_next();
+                assert_eq!((completion.id % 2 == 0) as libc::c_int, completion.valid);
+                assert!(seen.insert(completion.id));
+            }
+        }
+    }
+
+    #[test]
+    fn sign_into_buffer() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let message = "hello buffer world".as_bytes();
+            let expected =
+                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+
+            let mut signature = [0u8; SIGNATURE_BYTES];
+            assert_eq!(
+                1,
+                private_key_sign_into(
+                    &private_key[0],
+                    &message[0],
+                    message.len(),
+                    &mut signature[0]
+                )
+            );
+            assert_eq!(&expected[..], &signature[..]);
+
+            let garbage = [0xffu8; PRIVATE_KEY_BYTES];
+            let mut untouched = [0u8; SIGNATURE_BYTES];
+            assert_eq!(
+                0,
+                private_key_sign_into(&garbage[0], &message[0], message.len(), &mut untouched[0])
+            );
+            assert_eq!(&[0u8; SIGNATURE_BYTES][..], &untouched[..]);
+        }
+    }
+
+    #[test]
+    fn locked_private_key() {
+        unsafe {
+            let locked = private_key_generate_locked();
+            assert!(!locked.is_null());
+
+            let public_key = (*locked_private_key_public_key(locked)).public_key;
+            let message = "hello locked world".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature =
+                (*locked_private_key_sign(locked, &message[0], message.len())).signature;
+
+            assert_eq!(
+                1,
+                verify(
+                    &signature[0],
+                    &digest[0],
+                    digest.len(),
+                    &public_key[0],
+                    public_key.len(),
+                )
+            );
+
+            // every key lives in a page of its own, so destroying one leaves
+            // the others intact
+            let other = private_key_generate_locked();
+            assert!(!other.is_null());
+            let page_len = libc::sysconf(libc::_SC_PAGESIZE) as usize;
+            assert_eq!(0, (*locked).private_key_ptr() as usize % page_len);
+            assert_eq!(0, (*other).private_key_ptr() as usize % page_len);
+            assert_ne!((*locked).private_key_ptr(), (*other).private_key_ptr());
+
+            let other_public_key = (*locked_private_key_public_key(other)).public_key;
+            types::destroy_locked_private_key(locked);
+            assert_eq!(
+                &other_public_key[..],
+                &(*locked_private_key_public_key(other)).public_key[..]
+            );
+
+            types::destroy_locked_private_key(other);
+        }
+    }
+
+    #[test]
+    fn vrf() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let input = "hello vrf".as_bytes();
+
+            let proof = (*vrf_compute(&private_key[0], &input[0], input.len())).proof;
+            assert_eq!(
+                1,
+                vrf_verify(&public_key[0], &proof[0], &input[0], input.len())
+            );
+
+            // proofs are deterministic, and so are outputs
+            let again = (*vrf_compute(&private_key[0], &input[0], input.len())).proof;
+            assert_eq!(&proof[..], &again[..]);
+            assert_eq!(
+                (*vrf_proof_to_hash(&proof[0])).output,
+                (*vrf_proof_to_hash(&again[0])).output
+            );
+
+            // the proof is the plain signature of the input
+            let signature = (*private_key_sign(&private_key[0], &input[0], input.len())).signature;
+            assert_eq!(&proof[..], &signature[..]);
+
+            let other = "bye vrf".as_bytes();
+            assert_eq!(
+                0,
+                vrf_verify(&public_key[0], &proof[0], &other[0], other.len())
+            );
+        }
+    }
+
+    #[test]
+    fn sign_many() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let messages: Vec<&[u8]> = vec![&b"hello"[..], &b""[..], &b"many worlds"[..]];
+            let flattened_messages = messages.concat();
+            let message_sizes: Vec<usize> = messages.iter().map(|m| m.len()).collect();
+
+            let response = &*private_key_sign_many(
+                &private_key[0],
+                flattened_messages.as_ptr(),
+                flattened_messages.len(),
+                message_sizes.as_ptr(),
+                message_sizes.len(),
+            );
+            let flattened_signatures = from_raw_parts(
+                response.flattened_signatures_ptr,
+                response.flattened_signatures_len,
+            );
+
+            assert_eq!(messages.len() * SIGNATURE_BYTES, flattened_signatures.len());
+            for (message, signature) in messages
+                .iter()
+                .zip(flattened_signatures.chunks(SIGNATURE_BYTES))
+            {
+                let expected =
+                    (*private_key_sign(&private_key[0], message.as_ptr(), message.len())).signature;
+                assert_eq!(&expected[..], signature);
+            }
+        }
+    }
+
+    #[test]
+    fn sign_multi() {
+        unsafe {
+            let private_keys: Vec<_> = (0..3)
+                .map(|_| (*private_key_generate()).private_key)
+                .collect();
+            let messages: Vec<&[u8]> = vec![&b"hello"[..], &b""[..], &b"multi worlds"[..]];
+            let flattened_private_keys = private_keys.concat();
+            let flattened_messages = messages.concat();
+            let message_sizes: Vec<usize> = messages.iter().map(|m| m.len()).collect();
+
+            let response = &*private_key_sign_multi(
+                flattened_private_keys.as_ptr(),
+                flattened_private_keys.len(),
+                flattened_messages.as_ptr(),
+                flattened_messages.len(),
+                message_sizes.as_ptr(),
+                message_sizes.len(),
+            );
+            let flattened_signatures = from_raw_parts(
+                response.flattened_signatures_ptr,
+                response.flattened_signatures_len,
+            );
+
+            for ((private_key, message), signature) in private_keys
+                .iter()
+                .zip(messages.iter())
+                .zip(flattened_signatures.chunks(SIGNATURE_BYTES))
+            {
+                let expected =
+                    (*private_key_sign(&private_key[0], message.as_ptr(), message.len())).signature;
+                assert_eq!(&expected[..], signature);
+            }
+
+            // one key too few
+            assert!(private_key_sign_multi(
+                flattened_private_keys.as_ptr(),
+                flattened_private_keys.len() - PRIVATE_KEY_BYTES,
+                flattened_messages.as_ptr(),
+                flattened_messages.len(),
+                message_sizes.as_ptr(),
+                message_sizes.len(),
+            )
+            .is_null());
+        }
+    }
+
+    #[test]
+    fn configured_thread_pool() {
+        unsafe {
+            assert_eq!(1, bls_set_num_threads(2));
+
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello pool".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature =
+                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+
+            let verified = verify(
                 &signature[0],
-                &different_digest[0],
-                different_digest.len(),
+                &digest[0],
+                digest.len(),
                 &public_key[0],
                 public_key.len(),
             );
 
+            assert_eq!(1, bls_set_num_threads(0));
+            assert_eq!(1, verified);
+        }
+    }
+
+    #[test]
+    fn verification_reasons() {
+        use types::VerifyStatus::*;
+
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello world".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature =
+                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+
+            let check = |signature: &[u8], digests: &[u8], public_keys: &[u8]| {
+                verify_with_reason(
+                    signature.as_ptr(),
+                    digests.as_ptr(),
+                    digests.len(),
+                    public_keys.as_ptr(),
+                    public_keys.len(),
+                )
+            };
+
+            assert_eq!(VerifyValid, check(&signature, &digest, &public_key));
+
+            let garbage = [0u8; SIGNATURE_BYTES];
+            assert_eq!(
+                VerifyMalformedSignature,
+                check(&garbage, &digest, &public_key)
+            );
+            assert_eq!(
+                VerifyMalformedDigest,
+                check(&signature, &garbage, &public_key)
+            );
+            assert_eq!(
+                VerifyMalformedPublicKey,
+                check(&signature, &digest, &garbage[..PUBLIC_KEY_BYTES])
+            );
+
+            let two_keys = [&public_key[..], &public_key[..]].concat();
+            assert_eq!(VerifyLengthMismatch, check(&signature, &digest, &two_keys));
+            assert_eq!(VerifyNoDigests, check(&signature, &[], &[]));
+            assert_eq!(VerifyNoDigests, check(&garbage, &[], &[]));
+
+            let two_digests = [&digest[..], &digest[..]].concat();
+            assert_eq!(
+                VerifyDuplicateDigest,
+                check(&signature, &two_digests, &two_keys)
+            );
+
+            let other_message = "bye world".as_bytes();
+            let other_digest = (*hash(&other_message[0], other_message.len())).digest;
+            assert_eq!(
+                VerifyPairingMismatch,
+                check(&signature, &other_digest, &public_key)
+            );
+        }
+    }
+
+    #[test]
+    fn aggregate_verification() {
+        unsafe {
+            let private_key_a = (*private_key_generate()).private_key;
+            let private_key_b = (*private_key_generate()).private_key;
+            let public_key_a = (*private_key_public_key(&private_key_a[0])).public_key;
+            let public_key_b = (*private_key_public_key(&private_key_b[0])).public_key;
+
+            let message_a = "hello world".as_bytes();
+            let message_b = "bye world".as_bytes();
+            let signature_a =
+                (*private_key_sign(&private_key_a[0], &message_a[0], message_a.len())).signature;
+            let signature_b =
+                (*private_key_sign(&private_key_b[0], &message_b[0], message_b.len())).signature;
+
+            let flattened_signatures = [&signature_a[..], &signature_b[..]].concat();
+            let signature =
+                (*aggregate(&flattened_signatures[0], flattened_signatures.len())).signature;
+
+            let flattened_messages = [message_a, message_b].concat();
+            let message_sizes = [message_a.len(), message_b.len()];
+            let flattened_public_keys = [&public_key_a[..], &public_key_b[..]].concat();
+
+            let verified = aggregate_verify(
+                &signature[0],
+                &flattened_messages[0],
+                flattened_messages.len(),
+                &message_sizes[0],
+                message_sizes.len(),
+                &flattened_public_keys[0],
+                flattened_public_keys.len(),
+            );
+
+            assert_eq!(1, verified);
+
+            // swapping the public keys must fail
+            let swapped_public_keys = [&public_key_b[..], &public_key_a[..]].concat();
+            let not_verified = aggregate_verify(
+                &signature[0],
+                &flattened_messages[0],
+                flattened_messages.len(),
+                &message_sizes[0],
+                message_sizes.len(),
+                &swapped_public_keys[0],
+                swapped_public_keys.len(),
+            );
+
+            assert_eq!(0, not_verified);
+
+            // message sizes which don't add up must fail
+            let bad_message_sizes = [message_a.len(), message_b.len() + 1];
+            let not_verified = aggregate_verify(
+                &signature[0],
+                &flattened_messages[0],
+                flattened_messages.len(),
+                &bad_message_sizes[0],
+                bad_message_sizes.len(),
+                &flattened_public_keys[0],
+                flattened_public_keys.len(),
+            );
+
+            assert_eq!(0, not_verified);
+        }
+    }
+
+    #[test]
+    fn fast_aggregate_verification() {
+        unsafe {
+            let private_key_a = (*private_key_generate()).private_key;
+            let private_key_b = (*private_key_generate()).private_key;
+            let public_key_a = (*private_key_public_key(&private_key_a[0])).public_key;
+            let public_key_b = (*private_key_public_key(&private_key_b[0])).public_key;
+
+            let message = "hello committee".as_bytes();
+            let signature_a =
+                (*private_key_sign(&private_key_a[0], &message[0], message.len())).signature;
+            let signature_b =
+                (*private_key_sign(&private_key_b[0], &message[0], message.len())).signature;
+
+            let flattened_signatures = [&signature_a[..], &signature_b[..]].concat();
+            let signature =
+                (*aggregate(&flattened_signatures[0], flattened_signatures.len())).signature;
+
+            let flattened_public_keys = [&public_key_a[..], &public_key_b[..]].concat();
+
+            let verified = fast_aggregate_verify(
+                &signature[0],
+                &message[0],
+                message.len(),
+                &flattened_public_keys[0],
+                flattened_public_keys.len(),
+            );
+
+            assert_eq!(1, verified);
+
+            // a missing signer must fail
+            let not_verified = fast_aggregate_verify(
+                &signature[0],
+                &message[0],
+                message.len(),
+                &public_key_a[0],
+                public_key_a.len(),
+            );
+
             assert_eq!(0, not_verified);
         }
     }
+
+    #[test]
+    fn batch_verification() {
+        unsafe {
+            let mut flattened_signatures = Vec::new();
+            let mut flattened_digests = Vec::new();
+            let mut flattened_public_keys = Vec::new();
+
+            for i in 0..4u8 {
+                let private_key = (*private_key_generate()).private_key;
+                let public_key = (*private_key_public_key(&private_key[0])).public_key;
+                let message = [i; 8];
+                let digest = (*hash(&message[0], message.len())).digest;
+                let signature =
+                    (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+
+                flattened_signatures.extend_from_slice(&signature);
+                flattened_digests.extend_from_slice(&digest);
+                flattened_public_keys.extend_from_slice(&public_key);
+            }
+
+            let resp = batch_verify(
+                &flattened_signatures[0],
+                flattened_signatures.len(),
+                &flattened_digests[0],
+                flattened_digests.len(),
+                &flattened_public_keys[0],
+                flattened_public_keys.len(),
+            );
+
+            let results = from_raw_parts((*resp).results_ptr, (*resp).results_len);
+            assert_eq!(vec![true; 4], results.to_vec());
+            types::destroy_batch_verify_response(resp);
+
+            // swap two digests so their signatures no longer match
+            let (first, rest) = flattened_digests.split_at_mut(DIGEST_BYTES);
+            first.swap_with_slice(&mut rest[..DIGEST_BYTES]);
+
+            let resp = batch_verify(
+                &flattened_signatures[0],
+                flattened_signatures.len(),
+                &flattened_digests[0],
+                flattened_digests.len(),
+                &flattened_public_keys[0],
+                flattened_public_keys.len(),
+            );
+
+            let results = from_raw_parts((*resp).results_ptr, (*resp).results_len);
+            assert_eq!(vec![false, false, true, true], results.to_vec());
+            types::destroy_batch_verify_response(resp);
+        }
+    }
+
+    #[test]
+    fn private_key_with_seed() {
+        unsafe {
+            let seed = [5u8; 32];
+            let private_key_a = (*private_key_generate_with_seed(&seed[0])).private_key;
+            let private_key_b = (*private_key_generate_with_seed(&seed[0])).private_key;
+            assert_eq!(private_key_a, private_key_b);
+
+            let other_seed = [6u8; 32];
+            let private_key_c = (*private_key_generate_with_seed(&other_seed[0])).private_key;
+            assert_ne!(private_key_a, private_key_c);
+        }
+    }
+
+    #[test]
+    fn proof_of_possession() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let proof = (*pop_prove(&private_key[0])).proof;
+
+            assert_eq!(1, pop_verify(&public_key[0], &proof[0]));
+
+            // an ordinary signature over the public key is not a proof
+            let signature =
+                (*private_key_sign(&private_key[0], &public_key[0], public_key.len())).signature;
+            assert_eq!(0, pop_verify(&public_key[0], &signature[0]));
+
+            // nor can one sign the message of a proof directly
+            let message = pop_message(&public_key);
+            assert!(private_key_sign(&private_key[0], &message[0], message.len()).is_null());
+            assert!(private_key_sign_with_scheme(
+                &private_key[0],
+                &message[0],
+                message.len(),
+                types::SigningScheme::SchemeBasic as u32
+            )
+            .is_null());
+            let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+            assert_eq!(
+                0,
+                private_key_sign_into(
+                    &private_key[0],
+                    &message[0],
+                    message.len(),
+                    &mut raw_signature[0]
+                )
+            );
+            let sizes = [message.len()];
+            assert!(private_key_sign_many(
+                &private_key[0],
+                &message[0],
+                message.len(),
+                &sizes[0],
+                sizes.len()
+            )
+            .is_null());
+            assert!(private_key_sign_multi(
+                &private_key[0],
+                private_key.len(),
+                &message[0],
+                message.len(),
+                &sizes[0],
+                sizes.len()
+            )
+            .is_null());
+            assert!(vrf_compute(&private_key[0], &message[0], message.len()).is_null());
+
+            // a proof for another key is not a proof
+            let other_private_key = (*private_key_generate()).private_key;
+            let other_proof = (*pop_prove(&other_private_key[0])).proof;
+            assert_eq!(0, pop_verify(&public_key[0], &other_proof[0]));
+        }
+    }
+
+    #[test]
+    fn public_key_aggregation() {
+        unsafe {
+            let private_key_a = (*private_key_generate()).private_key;
+            let private_key_b = (*private_key_generate()).private_key;
+            let public_key_a = (*private_key_public_key(&private_key_a[0])).public_key;
+            let public_key_b = (*private_key_public_key(&private_key_b[0])).public_key;
+
+            let flattened_public_keys = [&public_key_a[..], &public_key_b[..]].concat();
+            let public_key =
+                (*aggregate_public_keys(&flattened_public_keys[0], flattened_public_keys.len()))
+                    .public_key;
+
+            let message = "hello committee".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature_a =
+                (*private_key_sign(&private_key_a[0], &message[0], message.len())).signature;
+            let signature_b =
+                (*private_key_sign(&private_key_b[0], &message[0], message.len())).signature;
+
+            let flattened_signatures = [&signature_a[..], &signature_b[..]].concat();
+            let signature =
+                (*aggregate(&flattened_signatures[0], flattened_signatures.len())).signature;
+
+            let verified = verify(
+                &signature[0],
+                &digest[0],
+                digest.len(),
+                &public_key[0],
+                public_key.len(),
+            );
+
+            assert_eq!(1, verified);
+
+            // garbage public keys
+            let garbage = vec![0u8; PUBLIC_KEY_BYTES];
+            assert!(aggregate_public_keys(&garbage[0], garbage.len()).is_null());
+        }
+    }
+
+    #[test]
+    fn validation() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello world".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+            let signature =
+                (*private_key_sign(&private_key[0], &message[0], message.len())).signature;
+
+            assert_eq!(1, private_key_validate(&private_key[0]));
+            assert_eq!(1, public_key_validate(&public_key[0]));
+            assert_eq!(1, signature_validate(&signature[0]));
+            assert_eq!(1, digest_validate(&digest[0]));
+
+            // garbage
+            let garbage = [0u8; SIGNATURE_BYTES];
+            assert_eq!(0, private_key_validate(&garbage[0]));
+            assert_eq!(0, public_key_validate(&garbage[0]));
+            assert_eq!(0, signature_validate(&garbage[0]));
+            assert_eq!(0, digest_validate(&garbage[0]));
+
+            // the identity is well-formed but not valid
+            let mut identity = [0u8; SIGNATURE_BYTES];
+            identity.copy_from_slice(G2Affine::zero().into_compressed().as_ref());
+            assert_eq!(0, signature_validate(&identity[0]));
+            assert_eq!(0, digest_validate(&identity[0]));
+        }
+    }
 }
@@ -0,0 +1,194 @@
+use std::slice::from_raw_parts;
+
+use bls_signatures::{
+    groupy::{CurveAffine, CurveProjective, EncodedPoint},
+    hash as hash_sig,
+    paired::bls12_381::{Fr, G2Compressed, G2},
+    PrivateKey, Serialize, Signature,
+};
+use ff::Field;
+use libc;
+use rand::rngs::OsRng;
+
+use crate::bls::api::{DIGEST_BYTES, PRIVATE_KEY_BYTES, SIGNATURE_BYTES};
+use crate::bls::types;
+
+macro_rules! try_ffi {
+    ($res:expr, $val:expr) => {{
+        match $res {
+            Ok(res) => res,
+            Err(_) => return $val,
+        }
+    }};
+}
+
+/// Blind a message so it can be signed without the signer learning it: the
+/// digest of the message is multiplied by a random, non-zero blinding factor
+///
+/// # Arguments
+///
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+///
+/// Result must be freed using `destroy_blind_message_response`.
+#[no_mangle]
+pub unsafe extern "C" fn blind_message(
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+) -> *mut types::BlindMessageResponse {
+    let message = from_raw_parts(message_ptr, message_len);
+
+    let mut rng = OsRng;
+    let mut factor = Fr::random(&mut rng);
+    while factor.is_zero() {
+        factor = Fr::random(&mut rng);
+    }
+
+    let mut blinded = hash_sig(message);
+    blinded.mul_assign(factor);
+
+    let mut raw_blinded_message: [u8; DIGEST_BYTES] = [0; DIGEST_BYTES];
+    raw_blinded_message.copy_from_slice(blinded.into_affine().into_compressed().as_ref());
+
+    let mut raw_blinding_factor: [u8; PRIVATE_KEY_BYTES] = [0; PRIVATE_KEY_BYTES];
+    PrivateKey::from(factor)
+        .write_bytes(&mut raw_blinding_factor.as_mut())
+        .expect("preallocated");
+
+    let response = types::BlindMessageResponse {
+        blinded_message: raw_blinded_message,
+        blinding_factor: raw_blinding_factor,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Sign a blinded message with a private key
+///
+/// The signer learns nothing about the message behind the blinded one, so this
+/// signs any message at all, `POP_DOMAIN` ones included. A key used here must
+/// be used for blind signing only.
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr`  - pointer to a private key byte array
+/// * `blinded_message_ptr`  - pointer to a blinded message byte array (DIGEST_BYTES long)
+///
+/// Returns `NULL` when passed an invalid private key, or a blinded message
+/// which isn't a non-identity point of the G2 subgroup. Result must be freed
+/// using `destroy_private_key_sign_response`.
+#[no_mangle]
+pub unsafe extern "C" fn blind_sign(
+    raw_private_key_ptr: *const u8,
+    blinded_message_ptr: *const u8,
+) -> *mut types::PrivateKeySignResponse {
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+
+    let mut blinded_message = G2Compressed::empty();
+    blinded_message
+        .as_mut()
+        .copy_from_slice(from_raw_parts(blinded_message_ptr, DIGEST_BYTES));
+    let blinded_message = try_ffi!(blinded_message.into_affine(), std::ptr::null_mut());
+    if blinded_message.is_zero() {
+        return std::ptr::null_mut();
+    }
+
+    signature_response(blinded_message.mul(Fr::from(private_key)))
+}
+
+/// Remove the blinding factor from the signature of a blinded message, giving
+/// an ordinary signature of the original message
+///
+/// # Arguments
+///
+/// * `blinded_signature_ptr` - pointer to a blinded signature byte array (SIGNATURE_BYTES long)
+/// * `blinding_factor_ptr`   - pointer to the blinding factor returned by `blind_message`
+///
+/// Returns `NULL` when passed an invalid blinded signature or blinding factor.
+/// Result must be freed using `destroy_private_key_sign_response`.
+#[no_mangle]
+pub unsafe extern "C" fn unblind_signature(
+    blinded_signature_ptr: *const u8,
+    blinding_factor_ptr: *const u8,
+) -> *mut types::PrivateKeySignResponse {
+    let raw_blinded_signature = from_raw_parts(blinded_signature_ptr, SIGNATURE_BYTES);
+    let blinded_signature = try_ffi!(
+        Signature::from_bytes(raw_blinded_signature),
+        std::ptr::null_mut()
+    );
+
+    let raw_blinding_factor = from_raw_parts(blinding_factor_ptr, PRIVATE_KEY_BYTES);
+    let blinding_factor = try_ffi!(
+        PrivateKey::from_bytes(raw_blinding_factor),
+        std::ptr::null_mut()
+    );
+    let inverse = match Fr::from(blinding_factor).inverse() {
+        Some(inverse) => inverse,
+        None => return std::ptr::null_mut(),
+    };
+
+    let mut signature = G2::from(blinded_signature);
+    signature.mul_assign(inverse);
+
+    signature_response(signature)
+}
+
+fn signature_response(signature: G2) -> *mut types::PrivateKeySignResponse {
+    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    Signature::from(signature)
+        .write_bytes(&mut raw_signature.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeySignResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use crate::bls::api::{hash, private_key_generate, private_key_public_key, verify};
+
+    #[test]
+    fn blind_signing() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello blind world".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+
+            let blinded = &*blind_message(&message[0], message.len());
+            assert_ne!(&digest[..], &blinded.blinded_message[..]);
+
+            let blinded_signature =
+                (*blind_sign(&private_key[0], &blinded.blinded_message[0])).signature;
+            let signature =
+                (*unblind_signature(&blinded_signature[0], &blinded.blinding_factor[0])).signature;
+
+            assert_eq!(
+                1,
+                verify(
+                    &signature[0],
+                    &digest[0],
+                    DIGEST_BYTES,
+                    &public_key[0],
+                    public_key.len()
+                )
+            );
+
+            let zero = [0u8; PRIVATE_KEY_BYTES];
+            assert!(unblind_signature(&blinded_signature[0], &zero[0]).is_null());
+
+            let mut identity = [0u8; DIGEST_BYTES];
+            identity[0] = 0xc0;
+            assert!(blind_sign(&private_key[0], &identity[0]).is_null());
+        }
+    }
+}
@@ -0,0 +1,284 @@
+use std::slice::from_raw_parts;
+
+use bls_signatures::{
+    groupy::{CurveAffine, CurveProjective, EncodedPoint},
+    paired::bls12_381::{Bls12, Fq12, Fr, G1Affine, G1Compressed, G2Affine, G2Compressed},
+    paired::Engine,
+    PrivateKey, Serialize,
+};
+use ff::Field;
+use libc;
+
+use crate::bls::api::{PRIVATE_KEY_BYTES, PUBLIC_KEY_BYTES, SIGNATURE_BYTES};
+use crate::bls::types;
+
+/// Length of a compressed G1 point, the same encoding as a public key.
+pub const G1_BYTES: usize = PUBLIC_KEY_BYTES;
+
+/// Length of a compressed G2 point, the same encoding as a signature.
+pub const G2_BYTES: usize = SIGNATURE_BYTES;
+
+/// Length of a scalar, the same encoding as a private key.
+pub const SCALAR_BYTES: usize = PRIVATE_KEY_BYTES;
+
+macro_rules! try_ffi {
+    ($res:expr, $val:expr) => {{
+        match $res {
+            Ok(res) => res,
+            Err(_) => return $val,
+        }
+    }};
+}
+
+/// Return the generator of G1
+///
+/// Result must be freed using `destroy_g1_response`.
+#[no_mangle]
+pub unsafe extern "C" fn g1_generator() -> *mut types::G1Response {
+    g1_response(G1Affine::one())
+}
+
+/// Return the generator of G2
+///
+/// Result must be freed using `destroy_g2_response`.
+#[no_mangle]
+pub unsafe extern "C" fn g2_generator() -> *mut types::G2Response {
+    g2_response(G2Affine::one())
+}
+
+/// Add two G1 points
+///
+/// # Arguments
+///
+/// * `a_ptr` - pointer to a compressed G1 point (G1_BYTES long)
+/// * `b_ptr` - pointer to a compressed G1 point (G1_BYTES long)
+///
+/// Returns `NULL` if either point can't be decoded. Result must be freed using
+/// `destroy_g1_response`.
+#[no_mangle]
+pub unsafe extern "C" fn g1_add(a_ptr: *const u8, b_ptr: *const u8) -> *mut types::G1Response {
+    let a = try_ffi!(decode_g1(a_ptr), std::ptr::null_mut());
+    let b = try_ffi!(decode_g1(b_ptr), std::ptr::null_mut());
+
+    let mut sum = a.into_projective();
+    sum.add_assign_mixed(&b);
+
+    g1_response(sum.into_affine())
+}
+
+/// Multiply a G1 point by a scalar
+///
+/// # Arguments
+///
+/// * `point_ptr`  - pointer to a compressed G1 point (G1_BYTES long)
+/// * `scalar_ptr` - pointer to a scalar (SCALAR_BYTES long)
+///
+/// Returns `NULL` if the point or the scalar can't be decoded. Result must be
+/// freed using `destroy_g1_response`.
+#[no_mangle]
+pub unsafe extern "C" fn g1_mul(
+    point_ptr: *const u8,
+    scalar_ptr: *const u8,
+) -> *mut types::G1Response {
+    let point = try_ffi!(decode_g1(point_ptr), std::ptr::null_mut());
+    let scalar = try_ffi!(decode_scalar(scalar_ptr), std::ptr::null_mut());
+
+    g1_response(point.mul(scalar).into_affine())
+}
+
+/// Add two G2 points
+///
+/// # Arguments
+///
+/// * `a_ptr` - pointer to a compressed G2 point (G2_BYTES long)
+/// * `b_ptr` - pointer to a compressed G2 point (G2_BYTES long)
+///
+/// Returns `NULL` if either point can't be decoded. Result must be freed using
+/// `destroy_g2_response`.
+#[no_mangle]
+pub unsafe extern "C" fn g2_add(a_ptr: *const u8, b_ptr: *const u8) -> *mut types::G2Response {
+    let a = try_ffi!(decode_g2(a_ptr), std::ptr::null_mut());
+    let b = try_ffi!(decode_g2(b_ptr), std::ptr::null_mut());
+
+    let mut sum = a.into_projective();
+    sum.add_assign_mixed(&b);
+
+    g2_response(sum.into_affine())
+}
+
+/// Multiply a G2 point by a scalar
+///
+/// # Arguments
+///
+/// * `point_ptr`  - pointer to a compressed G2 point (G2_BYTES long)
+/// * `scalar_ptr` - pointer to a scalar (SCALAR_BYTES long)
+///
+/// Returns `NULL` if the point or the scalar can't be decoded. Result must be
+/// freed using `destroy_g2_response`.
+#[no_mangle]
+pub unsafe extern "C" fn g2_mul(
+    point_ptr: *const u8,
+    scalar_ptr: *const u8,
+) -> *mut types::G2Response {
+    let point = try_ffi!(decode_g2(point_ptr), std::ptr::null_mut());
+    let scalar = try_ffi!(decode_scalar(scalar_ptr), std::ptr::null_mut());
+
+    g2_response(point.mul(scalar).into_affine())
+}
+
+/// Check that the product of the pairings e(g1_i, g2_i) is the identity
+///
+/// # Arguments
+///
+/// * `flattened_g1_ptr` - pointer to a byte array containing compressed G1 points
+/// * `flattened_g1_len` - length of the byte array (multiple of G1_BYTES)
+/// * `flattened_g2_ptr` - pointer to a byte array containing compressed G2 points
+/// * `flattened_g2_len` - length of the byte array (multiple of G2_BYTES)
+///
+/// Returns 0 if a point can't be decoded, if the arrays don't contain the same
+/// number of points or if they're empty, as an empty product checks nothing.
+#[no_mangle]
+pub unsafe extern "C" fn pairing_check(
+    flattened_g1_ptr: *const u8,
+    flattened_g1_len: libc::size_t,
+    flattened_g2_ptr: *const u8,
+    flattened_g2_len: libc::size_t,
+) -> libc::c_int {
+    if flattened_g1_len % G1_BYTES != 0
+        || flattened_g2_len % G2_BYTES != 0
+        || flattened_g1_len / G1_BYTES != flattened_g2_len / G2_BYTES
+    {
+        return 0;
+    }
+
+    let count = flattened_g1_len / G1_BYTES;
+    if count == 0 {
+        return 0;
+    }
+
+    let mut prepared = Vec::with_capacity(count);
+    for i in 0..count {
+        let p = try_ffi!(decode_g1(flattened_g1_ptr.add(i * G1_BYTES)), 0);
+        let q = try_ffi!(decode_g2(flattened_g2_ptr.add(i * G2_BYTES)), 0);
+        prepared.push((p.prepare(), q.prepare()));
+    }
+
+    let terms: Vec<_> = prepared.iter().map(|(p, q)| (p, q)).collect();
+
+    match Bls12::final_exponentiation(&Bls12::miller_loop(&terms)) {
+        Some(result) => (result == Fq12::one()) as libc::c_int,
+        None => 0,
+    }
+}
+
+unsafe fn decode_g1(point_ptr: *const u8) -> Result<G1Affine, ()> {
+    let mut point = G1Compressed::empty();
+    point
+        .as_mut()
+        .copy_from_slice(from_raw_parts(point_ptr, G1_BYTES));
+
+    point.into_affine().map_err(|_| ())
+}
+
+unsafe fn decode_g2(point_ptr: *const u8) -> Result<G2Affine, ()> {
+    let mut point = G2Compressed::empty();
+    point
+        .as_mut()
+        .copy_from_slice(from_raw_parts(point_ptr, G2_BYTES));
+
+    point.into_affine().map_err(|_| ())
+}
+
+unsafe fn decode_scalar(scalar_ptr: *const u8) -> Result<Fr, ()> {
+    PrivateKey::from_bytes(from_raw_parts(scalar_ptr, SCALAR_BYTES))
+        .map(Fr::from)
+        .map_err(|_| ())
+}
+
+fn g1_response(point: G1Affine) -> *mut types::G1Response {
+    let mut raw_point: [u8; G1_BYTES] = [0; G1_BYTES];
+    raw_point.copy_from_slice(point.into_compressed().as_ref());
+
+    Box::into_raw(Box::new(types::G1Response { point: raw_point }))
+}
+
+fn g2_response(point: G2Affine) -> *mut types::G2Response {
+    let mut raw_point: [u8; G2_BYTES] = [0; G2_BYTES];
+    raw_point.copy_from_slice(point.into_compressed().as_ref());
+
+    Box::into_raw(Box::new(types::G2Response { point: raw_point }))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use crate::bls::api::private_key_generate;
+
+    #[test]
+    fn curve_arithmetic() {
+        unsafe {
+            let a = (*private_key_generate()).private_key;
+            let b = (*private_key_generate()).private_key;
+
+            let g1 = (*g1_generator()).point;
+            let g2 = (*g2_generator()).point;
+
+            // a * G1 + b * G1 == (a + b) * G1
+            let a_g1 = (*g1_mul(&g1[0], &a[0])).point;
+            let b_g1 = (*g1_mul(&g1[0], &b[0])).point;
+            let sum = (*g1_add(&a_g1[0], &b_g1[0])).point;
+
+            let mut a_plus_b = Fr::from(PrivateKey::from_bytes(&a).unwrap());
+            a_plus_b.add_assign(&Fr::from(PrivateKey::from_bytes(&b).unwrap()));
+            let mut raw_a_plus_b = [0u8; SCALAR_BYTES];
+            PrivateKey::from(a_plus_b)
+                .write_bytes(&mut raw_a_plus_b.as_mut())
+                .unwrap();
+            assert_eq!(&sum[..], &(*g1_mul(&g1[0], &raw_a_plus_b[0])).point[..]);
+
+            // e(a * G1, b * G2) * e(-(a * G1), b * G2) == 1 and
+            // e(a * G1, b * G2) == e(b * G1, a * G2)
+            let a_g2 = (*g2_mul(&g2[0], &a[0])).point;
+            let b_g2 = (*g2_mul(&g2[0], &b[0])).point;
+            let b_g2_sum = (*g2_add(&b_g2[0], &b_g2[0])).point;
+            let mut neg_a_g1 = decode_g1(&a_g1[0]).unwrap();
+            neg_a_g1.negate();
+            let neg_a_g1 = neg_a_g1.into_compressed();
+
+            let g1s = [&a_g1[..], neg_a_g1.as_ref()].concat();
+            let g2s = [&b_g2[..], &b_g2[..]].concat();
+            assert_eq!(
+                1,
+                pairing_check(g1s.as_ptr(), g1s.len(), g2s.as_ptr(), g2s.len())
+            );
+
+            let g2s = [&b_g2[..], &b_g2_sum[..]].concat();
+            assert_eq!(
+                0,
+                pairing_check(g1s.as_ptr(), g1s.len(), g2s.as_ptr(), g2s.len())
+            );
+
+            let mut neg_b_g1 = decode_g1(&b_g1[0]).unwrap();
+            neg_b_g1.negate();
+            let neg_b_g1 = neg_b_g1.into_compressed();
+
+            let g1s = [&a_g1[..], neg_b_g1.as_ref()].concat();
+            let g2s = [&b_g2[..], &a_g2[..]].concat();
+            assert_eq!(
+                1,
+                pairing_check(g1s.as_ptr(), g1s.len(), g2s.as_ptr(), g2s.len())
+            );
+
+            let garbage = [0xffu8; G2_BYTES];
+            assert!(g1_add(&garbage[0], &g1[0]).is_null());
+            assert!(g2_mul(&garbage[0], &a[0]).is_null());
+            assert_eq!(
+                0,
+                pairing_check(g1s.as_ptr(), g1s.len(), g2s.as_ptr(), G2_BYTES)
+            );
+
+            assert_eq!(0, pairing_check(g1s.as_ptr(), 0, g2s.as_ptr(), 0));
+        }
+    }
+}
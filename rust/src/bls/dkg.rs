@@ -0,0 +1,218 @@
+use std::slice::from_raw_parts;
+
+use bls_signatures::{
+    groupy::CurveProjective,
+    paired::bls12_381::{Fr, G1},
+    PrivateKey, PublicKey, Serialize,
+};
+use ff::Field;
+use libc;
+use rand::rngs::OsRng;
+
+use crate::bls::api::{PRIVATE_KEY_BYTES, PUBLIC_KEY_BYTES};
+use crate::bls::threshold::{evaluate, share_index};
+use crate::bls::types;
+
+macro_rules! try_ffi {
+    ($res:expr, $val:expr) => {{
+        match $res {
+            Ok(res) => res,
+            Err(_) => return $val,
+        }
+    }};
+}
+
+/// Generate a dealer's contribution to a Joint-Feldman distributed key
+/// generation: a secret random polynomial of degree `threshold - 1`, the
+/// public commitments to its coefficients and its evaluation for every
+/// participant
+///
+/// The commitments are broadcast to all participants, while the share at
+/// position `i` is sent privately to the participant with index `i + 1`.
+///
+/// # Arguments
+///
+/// * `threshold`        - number of shares needed to sign with the group key
+/// * `num_participants` - number of participants in the key generation
+///
+/// Returns `NULL` when `threshold` is zero or larger than `num_participants`.
+/// Result must be freed using `destroy_dkg_dealing_response`.
+#[no_mangle]
+pub unsafe extern "C" fn dkg_generate_dealing(
+    threshold: libc::size_t,
+    num_participants: libc::size_t,
+) -> *mut types::DkgDealingResponse {
+    if threshold == 0 || threshold > num_participants {
+        return std::ptr::null_mut();
+    }
+
+    let mut rng = OsRng;
+    let coefficients: Vec<Fr> = (0..threshold).map(|_| Fr::random(&mut rng)).collect();
+
+    let mut flattened_commitments = vec![0; threshold * PUBLIC_KEY_BYTES];
+    for (coefficient, commitment) in coefficients
+        .iter()
+        .zip(flattened_commitments.chunks_mut(PUBLIC_KEY_BYTES))
+    {
+        PrivateKey::from(*coefficient)
+            .public_key()
+            .write_bytes(&mut commitment.as_mut())
+            .expect("preallocated");
+    }
+
+    let mut flattened_shares = vec![0; num_participants * PRIVATE_KEY_BYTES];
+    for (i, share) in flattened_shares.chunks_mut(PRIVATE_KEY_BYTES).enumerate() {
+        PrivateKey::from(evaluate(&coefficients, share_index(i as u64 + 1)))
+            .write_bytes(&mut share.as_mut())
+            .expect("preallocated");
+    }
+
+    let flattened_commitments = flattened_commitments.into_boxed_slice();
+    let flattened_shares = flattened_shares.into_boxed_slice();
+    let response = types::DkgDealingResponse {
+        flattened_commitments_len: flattened_commitments.len(),
+        flattened_commitments_ptr: Box::into_raw(flattened_commitments) as *const u8,
+        flattened_shares_len: flattened_shares.len(),
+        flattened_shares_ptr: Box::into_raw(flattened_shares) as *const u8,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Evaluate a dealer's committed polynomial "in the exponent" at a
+/// participant's index, yielding the public key of the share the dealer must
+/// have sent that participant
+///
+/// # Arguments
+///
+/// * `flattened_commitments_ptr` - pointer to a byte array containing commitments
+/// * `flattened_commitments_len` - length of the byte array (multiple of PUBLIC_KEY_BYTES)
+/// * `index`                     - index of the participant, starting at 1
+///
+/// Returns `NULL` on error. Result must be freed using
+/// `destroy_aggregate_public_keys_response`.
+#[no_mangle]
+pub unsafe extern "C" fn dkg_evaluate_commitments(
+    flattened_commitments_ptr: *const u8,
+    flattened_commitments_len: libc::size_t,
+    index: u64,
+) -> *mut types::AggregatePublicKeysResponse {
+    if index == 0
+        || flattened_commitments_len == 0
+        || flattened_commitments_len % PUBLIC_KEY_BYTES != 0
+    {
+        return std::ptr::null_mut();
+    }
+
+    let commitments = try_ffi!(
+        from_raw_parts(flattened_commitments_ptr, flattened_commitments_len)
+            .chunks(PUBLIC_KEY_BYTES)
+            .map(|item| PublicKey::from_bytes(item).map(G1::from))
+            .collect::<Result<Vec<_>, _>>(),
+        std::ptr::null_mut()
+    );
+
+    // Horner's method over the commitments, highest degree first
+    let x = share_index(index);
+    let evaluation = commitments.iter().rev().fold(G1::zero(), |mut acc, c| {
+        acc.mul_assign(x);
+        acc.add_assign(c);
+        acc
+    });
+
+    let mut raw_public_key: [u8; PUBLIC_KEY_BYTES] = [0; PUBLIC_KEY_BYTES];
+    PublicKey::from(evaluation)
+        .write_bytes(&mut raw_public_key.as_mut())
+        .expect("preallocated");
+
+    let response = types::AggregatePublicKeysResponse {
+        public_key: raw_public_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Sum the shares a participant received from every dealer into its share of
+/// the group private key
+///
+/// # Arguments
+///
+/// * `flattened_shares_ptr` - pointer to a byte array containing private key shares
+/// * `flattened_shares_len` - length of the byte array (multiple of PRIVATE_KEY_BYTES)
+///
+/// Returns `NULL` on error. Result must be freed using
+/// `destroy_private_key_generate_response`.
+#[no_mangle]
+pub unsafe extern "C" fn dkg_combine_shares(
+    flattened_shares_ptr: *const u8,
+    flattened_shares_len: libc::size_t,
+) -> *mut types::PrivateKeyGenerateResponse {
+    if flattened_shares_len == 0 || flattened_shares_len % PRIVATE_KEY_BYTES != 0 {
+        return std::ptr::null_mut();
+    }
+
+    let shares = try_ffi!(
+        from_raw_parts(flattened_shares_ptr, flattened_shares_len)
+            .chunks(PRIVATE_KEY_BYTES)
+            .map(|item| PrivateKey::from_bytes(item).map(Fr::from))
+            .collect::<Result<Vec<_>, _>>(),
+        std::ptr::null_mut()
+    );
+
+    let combined = shares.iter().fold(Fr::zero(), |mut acc, share| {
+        acc.add_assign(share);
+        acc
+    });
+
+    let mut raw_private_key: [u8; PRIVATE_KEY_BYTES] = [0; PRIVATE_KEY_BYTES];
+    PrivateKey::from(combined)
+        .write_bytes(&mut raw_private_key.as_mut())
+        .expect("preallocated");
+
+    let response = types::PrivateKeyGenerateResponse {
+        private_key: raw_private_key,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use crate::bls::api::private_key_public_key;
+
+    #[test]
+    fn dealing_verifies() {
+        unsafe {
+            assert!(dkg_generate_dealing(0, 3).is_null());
+            assert!(dkg_generate_dealing(4, 3).is_null());
+
+            let dealing = &*dkg_generate_dealing(2, 3);
+            let shares = from_raw_parts(dealing.flattened_shares_ptr, dealing.flattened_shares_len);
+
+            for (i, share) in shares.chunks(PRIVATE_KEY_BYTES).enumerate() {
+                let expected = (*private_key_public_key(&share[0])).public_key;
+                let evaluated = (*dkg_evaluate_commitments(
+                    dealing.flattened_commitments_ptr,
+                    dealing.flattened_commitments_len,
+                    i as u64 + 1,
+                ))
+                .public_key;
+
+                assert_eq!(&expected[..], &evaluated[..]);
+            }
+
+            // a share checked against the wrong index doesn't match
+            let expected = (*private_key_public_key(&shares[0])).public_key;
+            let evaluated = (*dkg_evaluate_commitments(
+                dealing.flattened_commitments_ptr,
+                dealing.flattened_commitments_len,
+                2,
+            ))
+            .public_key;
+
+            assert_ne!(&expected[..], &evaluated[..]);
+        }
+    }
+}
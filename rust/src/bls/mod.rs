@@ -1,2 +1,6 @@
 pub mod api;
+pub mod blind;
+pub mod curve;
+pub mod dkg;
+pub mod threshold;
 pub mod types;
@@ -0,0 +1,252 @@
+use std::collections::HashSet;
+use std::slice::from_raw_parts;
+
+use bls_signatures::{
+    groupy::CurveProjective,
+    paired::bls12_381::{Fr, FrRepr, G2},
+    PrivateKey, Serialize, Signature,
+};
+use ff::{Field, PrimeField};
+use libc;
+use rand::rngs::OsRng;
+
+use crate::bls::api::{PRIVATE_KEY_BYTES, SIGNATURE_BYTES};
+use crate::bls::types;
+
+macro_rules! try_ffi {
+    ($res:expr, $val:expr) => {{
+        match $res {
+            Ok(res) => res,
+            Err(_) => return $val,
+        }
+    }};
+}
+
+/// Split a private key into `num_shares` Shamir shares, any `threshold` of
+/// which can together produce signatures for the original key
+///
+/// The share at position `i` of the result has index `i + 1`; the index is
+/// needed again when combining partial signatures made with the share.
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// * `threshold`           - number of shares needed to sign
+/// * `num_shares`          - number of shares to produce
+///
+/// Returns `NULL` when passed an invalid private key or when `threshold` is
+/// zero or larger than `num_shares`. Result must be freed using
+/// `destroy_threshold_split_response`.
+#[no_mangle]
+pub unsafe extern "C" fn threshold_split_private_key(
+    raw_private_key_ptr: *const u8,
+    threshold: libc::size_t,
+    num_shares: libc::size_t,
+) -> *mut types::ThresholdSplitResponse {
+    if threshold == 0 || threshold > num_shares {
+        return std::ptr::null_mut();
+    }
+
+    let private_key_slice = from_raw_parts(raw_private_key_ptr, PRIVATE_KEY_BYTES);
+    let private_key = try_ffi!(
+        PrivateKey::from_bytes(private_key_slice),
+        std::ptr::null_mut()
+    );
+
+    // f(x) = private_key + c_1 x + ... + c_{t-1} x^{t-1}
+    let mut rng = OsRng;
+    let mut coefficients = Vec::with_capacity(threshold);
+    coefficients.push(Fr::from(private_key));
+    for _ in 1..threshold {
+        coefficients.push(Fr::random(&mut rng));
+    }
+
+    let mut flattened_shares = vec![0; num_shares * PRIVATE_KEY_BYTES];
+    for (i, share) in flattened_shares.chunks_mut(PRIVATE_KEY_BYTES).enumerate() {
+        PrivateKey::from(evaluate(&coefficients, share_index(i as u64 + 1)))
+            .write_bytes(&mut share.as_mut())
+            .expect("preallocated");
+    }
+
+    let flattened_shares = flattened_shares.into_boxed_slice();
+    let response = types::ThresholdSplitResponse {
+        flattened_shares_len: flattened_shares.len(),
+        flattened_shares_ptr: Box::into_raw(flattened_shares) as *const u8,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Combine partial signatures made with at least `threshold` distinct shares
+/// into the signature of the original private key
+///
+/// # Arguments
+///
+/// * `flattened_signatures_ptr` - pointer to a byte array containing partial signatures
+/// * `flattened_signatures_len` - length of the byte array (multiple of SIGNATURE_BYTES)
+/// * `indices_ptr`              - pointer to the share index of each partial signature
+/// * `indices_len`              - number of indices
+///
+/// Returns `NULL` on error, including zero or repeated indices. Result must be
+/// freed using `destroy_aggregate_response`.
+#[no_mangle]
+pub unsafe extern "C" fn threshold_combine_signatures(
+    flattened_signatures_ptr: *const u8,
+    flattened_signatures_len: libc::size_t,
+    indices_ptr: *const u64,
+    indices_len: libc::size_t,
+) -> *mut types::AggregateResponse {
+    if indices_len == 0
+        || flattened_signatures_len % SIGNATURE_BYTES != 0
+        || flattened_signatures_len / SIGNATURE_BYTES != indices_len
+    {
+        return std::ptr::null_mut();
+    }
+
+    let indices = from_raw_parts(indices_ptr, indices_len);
+    if indices.contains(&0) || indices.iter().collect::<HashSet<_>>().len() != indices.len() {
+        return std::ptr::null_mut();
+    }
+
+    let signatures = try_ffi!(
+        from_raw_parts(flattened_signatures_ptr, flattened_signatures_len)
+            .chunks(SIGNATURE_BYTES)
+            .map(Signature::from_bytes)
+            .collect::<Result<Vec<_>, _>>(),
+        std::ptr::null_mut()
+    );
+
+    let xs: Vec<Fr> = indices.iter().map(|index| share_index(*index)).collect();
+
+    let mut combined = G2::zero();
+    for (i, signature) in signatures.into_iter().enumerate() {
+        let mut s = G2::from(signature);
+        s.mul_assign(lagrange_at_zero(&xs, i));
+        combined.add_assign(&s);
+    }
+
+    let mut raw_signature: [u8; SIGNATURE_BYTES] = [0; SIGNATURE_BYTES];
+    Signature::from(combined)
+        .write_bytes(&mut raw_signature.as_mut())
+        .expect("preallocated");
+
+    let response = types::AggregateResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+pub(crate) fn share_index(index: u64) -> Fr {
+    Fr::from_repr(FrRepr::from(index)).expect("u64 is smaller than the modulus")
+}
+
+/// Evaluate the polynomial with the given coefficients at `x`.
+pub(crate) fn evaluate(coefficients: &[Fr], x: Fr) -> Fr {
+    coefficients.iter().rev().fold(Fr::zero(), |mut acc, c| {
+        acc.mul_assign(&x);
+        acc.add_assign(c);
+        acc
+    })
+}
+
+/// The Lagrange basis polynomial for `xs[i]` evaluated at zero.
+fn lagrange_at_zero(xs: &[Fr], i: usize) -> Fr {
+    let mut numerator = Fr::one();
+    let mut denominator = Fr::one();
+
+    for (j, x) in xs.iter().enumerate() {
+        if i == j {
+            continue;
+        }
+
+        numerator.mul_assign(x);
+
+        let mut difference = *x;
+        difference.sub_assign(&xs[i]);
+        denominator.mul_assign(&difference);
+    }
+
+    numerator.mul_assign(&denominator.inverse().expect("indices are distinct"));
+    numerator
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    use crate::bls::api::{
+        hash, private_key_generate, private_key_public_key, private_key_sign, verify,
+    };
+
+    #[test]
+    fn threshold_signing() {
+        unsafe {
+            let private_key = (*private_key_generate()).private_key;
+            let public_key = (*private_key_public_key(&private_key[0])).public_key;
+            let message = "hello threshold".as_bytes();
+            let digest = (*hash(&message[0], message.len())).digest;
+
+            assert!(threshold_split_private_key(&private_key[0], 0, 5).is_null());
+            assert!(threshold_split_private_key(&private_key[0], 6, 5).is_null());
+
+            let split = &*threshold_split_private_key(&private_key[0], 3, 5);
+            let shares = from_raw_parts(split.flattened_shares_ptr, split.flattened_shares_len);
+
+            // sign with shares 2, 4 and 5
+            let indices: [u64; 3] = [2, 4, 5];
+            let mut partials = Vec::new();
+            for index in indices.iter() {
+                let start = (*index as usize - 1) * PRIVATE_KEY_BYTES;
+                let partial =
+                    (*private_key_sign(&shares[start], &message[0], message.len())).signature;
+                partials.extend_from_slice(&partial);
+            }
+
+            let combined = (*threshold_combine_signatures(
+                &partials[0],
+                partials.len(),
+                &indices[0],
+                indices.len(),
+            ))
+            .signature;
+
+            assert_eq!(
+                1,
+                verify(
+                    &combined[0],
+                    &digest[0],
+                    digest.len(),
+                    &public_key[0],
+                    public_key.len()
+                )
+            );
+
+            // too few shares produce some other signature
+            let too_few =
+                (*threshold_combine_signatures(&partials[0], 2 * SIGNATURE_BYTES, &indices[0], 2))
+                    .signature;
+
+            assert_eq!(
+                0,
+                verify(
+                    &too_few[0],
+                    &digest[0],
+                    digest.len(),
+                    &public_key[0],
+                    public_key.len()
+                )
+            );
+
+            // repeated indices are rejected
+            let repeated: [u64; 3] = [2, 2, 5];
+            assert!(threshold_combine_signatures(
+                &partials[0],
+                partials.len(),
+                &repeated[0],
+                repeated.len()
+            )
+            .is_null());
+        }
+    }
+}
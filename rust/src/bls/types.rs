@@ -1,4 +1,59 @@
-use crate::bls::api::{BLSDigest, BLSPrivateKey, BLSPublicKey, BLSSignature};
+use bls_signatures::{paired::bls12_381::G2, PublicKey};
+
+use crate::bls::api::{
+    wipe, BLSDigest, BLSPrivateKey, BLSPublicKey, BLSSignature, VRFOutput, PRIVATE_KEY_BYTES,
+};
+use crate::bls::curve::{G1_BYTES, G2_BYTES};
+
+/// VerifyStatus
+
+/// Outcome of `verify_with_reason`.
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum VerifyStatus {
+    VerifyValid = 0,
+    VerifyMalformedSignature = 1,
+    VerifyMalformedDigest = 2,
+    VerifyMalformedPublicKey = 3,
+    VerifyLengthMismatch = 4,
+    VerifyNoDigests = 5,
+    VerifyDuplicateDigest = 6,
+    VerifyPairingMismatch = 7,
+}
+
+/// SigningScheme
+
+/// How a message is turned into the bytes actually signed. Under
+/// `SchemeAugmented` the message is prefixed with the signer's public key, so
+/// aggregate signatures no longer require distinct messages.
+#[repr(C)]
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum SigningScheme {
+    SchemeBasic = 0,
+    SchemeAugmented = 1,
+}
+
+impl SigningScheme {
+    /// Convert a scheme received over FFI, failing on values that aren't a
+    /// known scheme rather than transmuting them into the enum.
+    pub fn from_raw(scheme: u32) -> Result<SigningScheme, ()> {
+        match scheme {
+            0 => Ok(SigningScheme::SchemeBasic),
+            1 => Ok(SigningScheme::SchemeAugmented),
+            _ => Err(()),
+        }
+    }
+}
+
+/// VerifyCompletion
+
+/// Result of a verification started by `verify_async`.
+#[repr(C)]
+#[derive(Debug, Clone, Copy)]
+pub struct VerifyCompletion {
+    pub id: u64,
+    pub valid: libc::c_int,
+}
 
 /// HashResponse
 
@@ -24,6 +79,20 @@ pub unsafe extern "C" fn destroy_aggregate_response(ptr: *mut AggregateResponse)
     let _ = Box::from_raw(ptr);
 }
 
+/// AggregatePublicKeysResponse
+
+#[repr(C)]
+pub struct AggregatePublicKeysResponse {
+    pub public_key: BLSPublicKey,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_aggregate_public_keys_response(
+    ptr: *mut AggregatePublicKeysResponse,
+) {
+    let _ = Box::from_raw(ptr);
+}
+
 /// PrivateKeyGenerateResponse
 
 #[repr(C)]
@@ -63,3 +132,265 @@ pub unsafe extern "C" fn destroy_private_key_public_key_response(
 ) {
     let _ = Box::from_raw(ptr);
 }
+
+/// PopProveResponse
+
+#[repr(C)]
+pub struct PopProveResponse {
+    pub proof: BLSSignature,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_pop_prove_response(ptr: *mut PopProveResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// BatchVerifyResponse
+
+#[repr(C)]
+pub struct BatchVerifyResponse {
+    pub results_ptr: *const bool,
+    pub results_len: libc::size_t,
+}
+
+impl Drop for BatchVerifyResponse {
+    fn drop(&mut self) {
+        unsafe {
+            let _ = Box::from_raw(std::slice::from_raw_parts_mut(
+                self.results_ptr as *mut bool,
+                self.results_len,
+            ));
+        }
+    }
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_batch_verify_response(ptr: *mut BatchVerifyResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// Aggregator
+
+/// Opaque handle accumulating the sum of the signatures passed to
+/// `aggregator_add`.
+pub struct Aggregator {
+    pub signature: G2,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_aggregator(ptr: *mut Aggregator) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// PublicKeyHandle
+
+/// Opaque handle to a public key kept decompressed in Rust memory, so it can
+/// be used for many verifications without being decoded each time.
+pub struct PublicKeyHandle {
+    pub public_key: PublicKey,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_public_key_handle(ptr: *mut PublicKeyHandle) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// BlindMessageResponse
+
+#[repr(C)]
+pub struct BlindMessageResponse {
+    pub blinded_message: BLSDigest,
+    pub blinding_factor: BLSPrivateKey,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_blind_message_response(ptr: *mut BlindMessageResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// LockedPrivateKey
+
+/// Opaque handle to a private key living in a page of its own which is
+/// locked into RAM and wiped when the handle is destroyed. Giving every key its
+/// own page means unlocking one key can't unlock another, nor any other data.
+pub struct LockedPrivateKey {
+    page_ptr: *mut u8,
+    page_len: usize,
+}
+
+impl LockedPrivateKey {
+    /// Map and lock a fresh zeroed page, or return `None` if the page could not
+    /// be mapped or locked.
+    pub unsafe fn new() -> Option<LockedPrivateKey> {
+        let page_len = libc::sysconf(libc::_SC_PAGESIZE) as usize;
+        let page_ptr = libc::mmap(
+            std::ptr::null_mut(),
+            page_len,
+            libc::PROT_READ | libc::PROT_WRITE,
+            libc::MAP_PRIVATE | libc::MAP_ANONYMOUS,
+            -1,
+            0,
+        );
+        if page_ptr == libc::MAP_FAILED {
+            return None;
+        }
+
+        if libc::mlock(page_ptr, page_len) != 0 {
+            libc::munmap(page_ptr, page_len);
+            return None;
+        }
+
+        Some(LockedPrivateKey {
+            page_ptr: page_ptr as *mut u8,
+            page_len,
+        })
+    }
+
+    /// Pointer to the private key, at the start of the locked page.
+    pub fn private_key_ptr(&self) -> *mut u8 {
+        self.page_ptr
+    }
+}
+
+impl Drop for LockedPrivateKey {
+    fn drop(&mut self) {
+        unsafe {
+            wipe(self.page_ptr, PRIVATE_KEY_BYTES);
+            libc::munlock(self.page_ptr as *const libc::c_void, self.page_len);
+            libc::munmap(self.page_ptr as *mut libc::c_void, self.page_len);
+        }
+    }
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_locked_private_key(ptr: *mut LockedPrivateKey) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// VrfComputeResponse
+
+#[repr(C)]
+pub struct VrfComputeResponse {
+    pub proof: BLSSignature,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_vrf_compute_response(ptr: *mut VrfComputeResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// VrfProofToHashResponse
+
+#[repr(C)]
+pub struct VrfProofToHashResponse {
+    pub output: VRFOutput,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_vrf_proof_to_hash_response(ptr: *mut VrfProofToHashResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// ThresholdSplitResponse
+
+#[repr(C)]
+pub struct ThresholdSplitResponse {
+    pub flattened_shares_ptr: *const u8,
+    pub flattened_shares_len: libc::size_t,
+}
+
+impl Drop for ThresholdSplitResponse {
+    fn drop(&mut self) {
+        unsafe {
+            let _ = Box::from_raw(std::slice::from_raw_parts_mut(
+                self.flattened_shares_ptr as *mut u8,
+                self.flattened_shares_len,
+            ));
+        }
+    }
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_threshold_split_response(ptr: *mut ThresholdSplitResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// DkgDealingResponse
+
+#[repr(C)]
+pub struct DkgDealingResponse {
+    pub flattened_commitments_ptr: *const u8,
+    pub flattened_commitments_len: libc::size_t,
+    pub flattened_shares_ptr: *const u8,
+    pub flattened_shares_len: libc::size_t,
+}
+
+impl Drop for DkgDealingResponse {
+    fn drop(&mut self) {
+        unsafe {
+            let _ = Box::from_raw(std::slice::from_raw_parts_mut(
+                self.flattened_commitments_ptr as *mut u8,
+                self.flattened_commitments_len,
+            ));
+            let _ = Box::from_raw(std::slice::from_raw_parts_mut(
+                self.flattened_shares_ptr as *mut u8,
+                self.flattened_shares_len,
+            ));
+        }
+    }
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_dkg_dealing_response(ptr: *mut DkgDealingResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// PrivateKeySignManyResponse
+
+#[repr(C)]
+pub struct PrivateKeySignManyResponse {
+    pub flattened_signatures_ptr: *const u8,
+    pub flattened_signatures_len: libc::size_t,
+}
+
+impl Drop for PrivateKeySignManyResponse {
+    fn drop(&mut self) {
+        unsafe {
+            let _ = Box::from_raw(std::slice::from_raw_parts_mut(
+                self.flattened_signatures_ptr as *mut u8,
+                self.flattened_signatures_len,
+            ));
+        }
+    }
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_private_key_sign_many_response(
+    ptr: *mut PrivateKeySignManyResponse,
+) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// G1Response
+
+#[repr(C)]
+pub struct G1Response {
+    pub point: [u8; G1_BYTES],
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_g1_response(ptr: *mut G1Response) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// G2Response
+
+#[repr(C)]
+pub struct G2Response {
+    pub point: [u8; G2_BYTES],
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_g2_response(ptr: *mut G2Response) {
+    let _ = Box::from_raw(ptr);
+}
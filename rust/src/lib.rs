@@ -4,7 +4,10 @@
 #[macro_use]
 extern crate anyhow;
 #[macro_use]
+extern crate lazy_static;
+#[macro_use]
 extern crate log;
 
 pub mod bls;
 pub mod proofs;
+pub mod secp256k1;
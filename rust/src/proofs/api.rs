@@ -6,11 +6,13 @@ use ffi_toolkit::{
     c_str_to_pbuf, catch_panic_response, raw_ptr, rust_str_to_c_str, FCPResponseStatus,
 };
 use filecoin_proofs as api_fns;
+use filecoin_proofs::constants as api_constants;
 use filecoin_proofs::{
     types as api_types, PaddedBytesAmount, PieceInfo, PoRepConfig, PoRepProofPartitions,
     PoStConfig, SectorClass, SectorSize, UnpaddedByteIndex, UnpaddedBytesAmount,
 };
 use libc;
+use rayon::prelude::*;
 use storage_proofs::sector::SectorId;
 
 use super::helpers::{
@@ -351,27 +353,19 @@ pub unsafe extern "C" fn verify_seal(
 
         info!("verify_seal: start");
 
-        let porep_bytes = super::helpers::try_into_porep_proof_bytes(proof_ptr, proof_len);
-
-        let result = porep_bytes.and_then(|bs| {
-            super::helpers::porep_proof_partitions_try_from_bytes(&bs).and_then(|partitions| {
-                let cfg = api_types::PoRepConfig {
-                    sector_size: api_types::SectorSize(sector_size),
-                    partitions,
-                };
-
-                api_fns::verify_seal(
-                    cfg,
+        let result =
+            super::helpers::try_into_porep_proof_bytes(proof_ptr, proof_len).and_then(|bs| {
+                verify_seal_bytes(
+                    sector_size,
                     *comm_r,
                     *comm_d,
                     *prover_id,
-                    SectorId::from(sector_id),
                     *ticket,
                     *seed,
+                    sector_id,
                     &bs,
                 )
-            })
-        });
+            });
 
         let mut response = VerifySealResponse::default();
 
@@ -423,6 +417,102 @@ pub unsafe extern "C" fn finalize_ticket(partial_ticket: &[u8; 32]) -> *mut Fina
     })
 }
 
+/// Verifies many seal proofs in one call, in parallel. Each result reports
+/// whether the corresponding proof is valid; a proof which can't be checked at
+/// all, e.g. because it's malformed, counts as invalid.
+///
+#[no_mangle]
+pub unsafe extern "C" fn verify_seal_batch(
+    seals_ptr: *const FFISealVerifyInfo,
+    seals_len: libc::size_t,
+) -> *mut VerifySealBatchResponse {
+    catch_panic_response(|| {
+        init_log();
+
+        info!("verify_seal_batch: start");
+
+        // raw pointers can't cross threads, so copy the proofs out first
+        let seals: Vec<_> = from_raw_parts(seals_ptr, seals_len)
+            .iter()
+            .map(|seal| {
+                let proof =
+                    super::helpers::try_into_porep_proof_bytes(seal.proof_ptr, seal.proof_len);
+
+                (
+                    seal.sector_size,
+                    seal.comm_r,
+                    seal.comm_d,
+                    seal.prover_id,
+                    seal.ticket,
+                    seal.seed,
+                    seal.sector_id,
+                    proof,
+                )
+            })
+            .collect();
+
+        let results: Vec<bool> = seals
+            .into_par_iter()
+            .map(
+                |(sector_size, comm_r, comm_d, prover_id, ticket, seed, sector_id, proof)| {
+                    proof
+                        .and_then(|bs| {
+                            verify_seal_bytes(
+                                sector_size,
+                                comm_r,
+                                comm_d,
+                                prover_id,
+                                ticket,
+                                seed,
+                                sector_id,
+                                &bs,
+                            )
+                        })
+                        .unwrap_or(false)
+                },
+            )
+            .collect();
+
+        let mut response = VerifySealBatchResponse::default();
+        response.status_code = FCPResponseStatus::FCPNoError;
+        response.results_ptr = results.as_ptr();
+        response.results_len = results.len();
+        mem::forget(results);
+
+        info!("verify_seal_batch: finish");
+
+        raw_ptr(response)
+    })
+}
+
+fn verify_seal_bytes(
+    sector_size: u64,
+    comm_r: [u8; 32],
+    comm_d: [u8; 32],
+    prover_id: [u8; 32],
+    ticket: [u8; 32],
+    seed: [u8; 32],
+    sector_id: u64,
+    proof: &[u8],
+) -> anyhow::Result<bool> {
+    let partitions = super::helpers::porep_proof_partitions_try_from_bytes(proof)?;
+    let cfg = api_types::PoRepConfig {
+        sector_size: api_types::SectorSize(sector_size),
+        partitions,
+    };
+
+    api_fns::verify_seal(
+        cfg,
+        comm_r,
+        comm_d,
+        prover_id,
+        SectorId::from(sector_id),
+        ticket,
+        seed,
+        proof,
+    )
+}
+
 /// Verifies that a proof-of-spacetime is valid.
 #[no_mangle]
 pub unsafe extern "C" fn verify_post(
@@ -486,6 +576,84 @@ pub unsafe extern "C" fn verify_post(
     })
 }
 
+/// Verifies many proofs-of-spacetime in one call, in parallel. Each result
+/// reports whether the corresponding proof is valid; a proof which can't be
+/// checked at all, e.g. because its inputs are malformed, counts as invalid.
+///
+#[no_mangle]
+pub unsafe extern "C" fn verify_post_batch(
+    posts_ptr: *const FFIPoStVerifyInfo,
+    posts_len: libc::size_t,
+) -> *mut VerifyPoStBatchResponse {
+    catch_panic_response(|| {
+        init_log();
+
+        info!("verify_post_batch: start");
+
+        // raw pointers can't cross threads, so copy the inputs out first
+        let posts: Vec<_> = from_raw_parts(posts_ptr, posts_len)
+            .iter()
+            .map(|post| {
+                let inputs = super::helpers::to_public_replica_info_map(
+                    post.sector_ids_ptr,
+                    post.sector_ids_len,
+                    post.flattened_comm_rs_ptr,
+                    post.flattened_comm_rs_len,
+                )
+                .and_then(|map| {
+                    let proofs =
+                        c_to_rust_proofs(post.flattened_proofs_ptr, post.flattened_proofs_len)?;
+                    let winners = c_to_rust_candidates(post.winners_ptr, post.winners_len)?;
+
+                    Ok((map, proofs, winners))
+                });
+
+                (
+                    post.sector_size,
+                    post.randomness,
+                    post.challenge_count,
+                    post.prover_id,
+                    inputs,
+                )
+            })
+            .collect();
+
+        let results: Vec<bool> = posts
+            .into_par_iter()
+            .map(
+                |(sector_size, randomness, challenge_count, prover_id, inputs)| {
+                    inputs
+                        .and_then(|(map, proofs, winners)| {
+                            let cfg = api_types::PoStConfig {
+                                sector_size: api_types::SectorSize(sector_size),
+                            };
+                            api_fns::verify_post(
+                                cfg,
+                                &randomness,
+                                challenge_count,
+                                &proofs,
+                                &map,
+                                &winners,
+                                prover_id,
+                            )
+                        })
+                        .unwrap_or(false)
+                },
+            )
+            .collect();
+
+        let mut response = VerifyPoStBatchResponse::default();
+        response.status_code = FCPResponseStatus::FCPNoError;
+        response.results_ptr = results.as_ptr();
+        response.results_len = results.len();
+        mem::forget(results);
+
+        info!("verify_post_batch: finish");
+
+        raw_ptr(response)
+    })
+}
+
 /// Returns the merkle root for a piece after piece padding and alignment.
 /// The caller is responsible for closing the passed in file descriptor.
 #[no_mangle]
@@ -737,6 +905,36 @@ pub unsafe extern "C" fn get_max_user_bytes_per_staged_sector(sector_size: u64)
     )))
 }
 
+/// Returns the sector sizes the linked proofs library can seal, with the
+/// number of PoRep partitions it proves them with by default.
+///
+#[no_mangle]
+pub unsafe extern "C" fn get_supported_sector_sizes() -> *mut SupportedSectorSizesResponse {
+    catch_panic_response(|| {
+        let sector_sizes: Vec<FFISectorSizeInfo> = [
+            api_constants::SECTOR_SIZE_ONE_KIB,
+            api_constants::SECTOR_SIZE_16_MIB,
+            api_constants::SECTOR_SIZE_256_MIB,
+            api_constants::SECTOR_SIZE_1_GIB,
+            api_constants::SECTOR_SIZE_32_GIB,
+        ]
+        .iter()
+        .map(|sector_size| FFISectorSizeInfo {
+            sector_size: *sector_size,
+            porep_proof_partitions: api_constants::DEFAULT_POREP_PROOF_PARTITIONS.0,
+            porep_proof_bytes_per_partition: api_constants::SINGLE_PARTITION_PROOF_LEN,
+        })
+        .collect();
+
+        let mut response = SupportedSectorSizesResponse::default();
+        response.sector_sizes_ptr = sector_sizes.as_ptr();
+        response.sector_sizes_len = sector_sizes.len();
+        mem::forget(sector_sizes);
+
+        raw_ptr(response)
+    })
+}
+
 /// Deallocates a VerifySealResponse.
 ///
 #[no_mangle]
@@ -744,6 +942,22 @@ pub unsafe extern "C" fn destroy_verify_seal_response(ptr: *mut VerifySealRespon
     let _ = Box::from_raw(ptr);
 }
 
+/// Deallocates a SupportedSectorSizesResponse.
+///
+#[no_mangle]
+pub unsafe extern "C" fn destroy_supported_sector_sizes_response(
+    ptr: *mut SupportedSectorSizesResponse,
+) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// Deallocates a VerifySealBatchResponse.
+///
+#[no_mangle]
+pub unsafe extern "C" fn destroy_verify_seal_batch_response(ptr: *mut VerifySealBatchResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
 #[no_mangle]
 pub unsafe extern "C" fn destroy_finalize_ticket_response(ptr: *mut FinalizeTicketResponse) {
     let _ = Box::from_raw(ptr);
@@ -756,6 +970,13 @@ pub unsafe extern "C" fn destroy_verify_post_response(ptr: *mut VerifyPoStRespon
     let _ = Box::from_raw(ptr);
 }
 
+/// Deallocates a VerifyPoStBatchResponse.
+///
+#[no_mangle]
+pub unsafe extern "C" fn destroy_verify_post_batch_response(ptr: *mut VerifyPoStBatchResponse) {
+    let _ = Box::from_raw(ptr);
+}
+
 #[no_mangle]
 pub unsafe extern "C" fn destroy_generate_post_response(ptr: *mut GeneratePoStResponse) {
     let _ = Box::from_raw(ptr);
@@ -789,6 +1010,21 @@ pub mod tests {
 
     use super::*;
 
+    #[test]
+    fn test_get_supported_sector_sizes() {
+        unsafe {
+            let resp = get_supported_sector_sizes();
+            let sector_sizes = from_raw_parts((*resp).sector_sizes_ptr, (*resp).sector_sizes_len);
+
+            assert!(sector_sizes.iter().any(|info| info.sector_size == 1024));
+            assert!(sector_sizes
+                .iter()
+                .all(|info| info.porep_proof_partitions > 0));
+
+            destroy_supported_sector_sizes_response(resp);
+        }
+    }
+
     #[test]
     fn test_write_with_and_without_alignment() -> Result<()> {
         // write some bytes to a temp file to be used as the byte source
@@ -962,6 +1198,36 @@ pub mod tests {
 
             assert!((*resp_d).is_valid, "proof was not valid");
 
+            let seal = FFISealVerifyInfo {
+                sector_size,
+                comm_r: (*resp_b).seal_pre_commit_output.comm_r,
+                comm_d: (*resp_b).seal_pre_commit_output.comm_d,
+                prover_id,
+                ticket,
+                seed,
+                sector_id,
+                proof_ptr: (*resp_c).proof_ptr,
+                proof_len: (*resp_c).proof_len,
+            };
+            let seals = vec![
+                seal.clone(),
+                FFISealVerifyInfo {
+                    seed: ticket,
+                    ..seal.clone()
+                },
+                FFISealVerifyInfo {
+                    proof_len: 1,
+                    ..seal
+                },
+            ];
+
+            let resp_batch = verify_seal_batch(seals.as_ptr(), seals.len());
+            assert_eq!(
+                from_raw_parts((*resp_batch).results_ptr, (*resp_batch).results_len),
+                &[true, false, false]
+            );
+            destroy_verify_seal_batch_response(resp_batch);
+
             let resp_e = unseal(
                 sector_class.clone(),
                 cache_dir_path_c_str,
@@ -1063,6 +1329,39 @@ pub mod tests {
                 panic!("verify_post rejected the provided proof as invalid");
             }
 
+            let post = FFIPoStVerifyInfo {
+                sector_size,
+                randomness,
+                challenge_count,
+                sector_ids_ptr: &sector_id as *const u64,
+                sector_ids_len: 1,
+                flattened_comm_rs_ptr: &(*resp_b).seal_pre_commit_output.comm_r[0],
+                flattened_comm_rs_len: 32,
+                flattened_proofs_ptr: (*resp_h).flattened_proofs_ptr,
+                flattened_proofs_len: (*resp_h).flattened_proofs_len,
+                winners_ptr: (*resp_f).candidates_ptr,
+                winners_len: (*resp_f).candidates_len,
+                prover_id,
+            };
+            let posts = vec![
+                post.clone(),
+                FFIPoStVerifyInfo {
+                    prover_id: [0u8; 32],
+                    ..post.clone()
+                },
+                FFIPoStVerifyInfo {
+                    sector_ids_ptr: std::ptr::null(),
+                    ..post
+                },
+            ];
+
+            let resp_batch = verify_post_batch(posts.as_ptr(), posts.len());
+            assert_eq!(
+                from_raw_parts((*resp_batch).results_ptr, (*resp_batch).results_len),
+                &[true, false, false]
+            );
+            destroy_verify_post_batch_response(resp_batch);
+
             destroy_write_without_alignment_response(resp_a);
             destroy_seal_pre_commit_response(resp_b);
             destroy_seal_commit_response(resp_c);
@@ -313,6 +313,72 @@ impl Default for VerifySealResponse {
 
 code_and_message_impl!(VerifySealResponse);
 
+#[repr(C)]
+#[derive(Clone)]
+pub struct FFISectorSizeInfo {
+    pub sector_size: u64,
+    pub porep_proof_partitions: u8,
+    pub porep_proof_bytes_per_partition: libc::size_t,
+}
+
+#[repr(C)]
+#[derive(DropStructMacro)]
+pub struct SupportedSectorSizesResponse {
+    pub status_code: FCPResponseStatus,
+    pub error_msg: *const libc::c_char,
+    pub sector_sizes_ptr: *const FFISectorSizeInfo,
+    pub sector_sizes_len: libc::size_t,
+}
+
+impl Default for SupportedSectorSizesResponse {
+    fn default() -> SupportedSectorSizesResponse {
+        SupportedSectorSizesResponse {
+            status_code: FCPResponseStatus::FCPNoError,
+            error_msg: ptr::null(),
+            sector_sizes_ptr: ptr::null(),
+            sector_sizes_len: 0,
+        }
+    }
+}
+
+code_and_message_impl!(SupportedSectorSizesResponse);
+
+#[repr(C)]
+#[derive(Clone)]
+pub struct FFISealVerifyInfo {
+    pub sector_size: u64,
+    pub comm_r: [u8; 32],
+    pub comm_d: [u8; 32],
+    pub prover_id: [u8; 32],
+    pub ticket: [u8; 32],
+    pub seed: [u8; 32],
+    pub sector_id: u64,
+    pub proof_ptr: *const u8,
+    pub proof_len: libc::size_t,
+}
+
+#[repr(C)]
+#[derive(DropStructMacro)]
+pub struct VerifySealBatchResponse {
+    pub status_code: FCPResponseStatus,
+    pub error_msg: *const libc::c_char,
+    pub results_ptr: *const bool,
+    pub results_len: libc::size_t,
+}
+
+impl Default for VerifySealBatchResponse {
+    fn default() -> VerifySealBatchResponse {
+        VerifySealBatchResponse {
+            status_code: FCPResponseStatus::FCPNoError,
+            error_msg: ptr::null(),
+            results_ptr: ptr::null(),
+            results_len: 0,
+        }
+    }
+}
+
+code_and_message_impl!(VerifySealBatchResponse);
+
 #[repr(C)]
 #[derive(DropStructMacro)]
 pub struct VerifyPoStResponse {
@@ -333,6 +399,45 @@ impl Default for VerifyPoStResponse {
 
 code_and_message_impl!(VerifyPoStResponse);
 
+#[repr(C)]
+#[derive(Clone)]
+pub struct FFIPoStVerifyInfo {
+    pub sector_size: u64,
+    pub randomness: [u8; 32],
+    pub challenge_count: u64,
+    pub sector_ids_ptr: *const u64,
+    pub sector_ids_len: libc::size_t,
+    pub flattened_comm_rs_ptr: *const u8,
+    pub flattened_comm_rs_len: libc::size_t,
+    pub flattened_proofs_ptr: *const u8,
+    pub flattened_proofs_len: libc::size_t,
+    pub winners_ptr: *const FFICandidate,
+    pub winners_len: libc::size_t,
+    pub prover_id: [u8; 32],
+}
+
+#[repr(C)]
+#[derive(DropStructMacro)]
+pub struct VerifyPoStBatchResponse {
+    pub status_code: FCPResponseStatus,
+    pub error_msg: *const libc::c_char,
+    pub results_ptr: *const bool,
+    pub results_len: libc::size_t,
+}
+
+impl Default for VerifyPoStBatchResponse {
+    fn default() -> VerifyPoStBatchResponse {
+        VerifyPoStBatchResponse {
+            status_code: FCPResponseStatus::FCPNoError,
+            error_msg: ptr::null(),
+            results_ptr: ptr::null(),
+            results_len: 0,
+        }
+    }
+}
+
+code_and_message_impl!(VerifyPoStBatchResponse);
+
 #[repr(C)]
 #[derive(DropStructMacro)]
 pub struct FinalizeTicketResponse {
@@ -0,0 +1,237 @@
+use std::slice::from_raw_parts;
+
+use ::secp256k1::{
+    recover as recover_sig, sign as sign_sig, verify as verify_sig, Message, PublicKey, RecoveryId,
+    SecretKey, Signature,
+};
+use blake2b_simd::Params;
+use libc;
+use rand::rngs::OsRng;
+
+use crate::secp256k1::types;
+
+pub const SECP256K1_SIGNATURE_BYTES: usize = 65;
+pub const SECP256K1_PRIVATE_KEY_BYTES: usize = 32;
+pub const SECP256K1_PUBLIC_KEY_BYTES: usize = 65;
+
+pub type Secp256k1Signature = [u8; SECP256K1_SIGNATURE_BYTES];
+pub type Secp256k1PrivateKey = [u8; SECP256K1_PRIVATE_KEY_BYTES];
+pub type Secp256k1PublicKey = [u8; SECP256K1_PUBLIC_KEY_BYTES];
+
+/// Length of the blake2b digest which is signed in place of the message.
+const MESSAGE_DIGEST_BYTES: usize = 32;
+
+macro_rules! try_ffi {
+    ($res:expr, $val:expr) => {{
+        match $res {
+            Ok(res) => res,
+            Err(_) => return $val,
+        }
+    }};
+}
+
+/// Generate a new secp256k1 private key
+#[no_mangle]
+pub unsafe extern "C" fn secp256k1_private_key_generate(
+) -> *mut types::Secp256k1PrivateKeyGenerateResponse {
+    let private_key = SecretKey::random(&mut OsRng);
+
+    let response = types::Secp256k1PrivateKeyGenerateResponse {
+        private_key: private_key.serialize(),
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Generate the uncompressed public key for a secp256k1 private key
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+///
+/// Returns `NULL` when passed an invalid private key.
+#[no_mangle]
+pub unsafe extern "C" fn secp256k1_private_key_public_key(
+    raw_private_key_ptr: *const u8,
+) -> *mut types::Secp256k1PublicKeyResponse {
+    let private_key = try_ffi!(parse_private_key(raw_private_key_ptr), std::ptr::null_mut());
+
+    let response = types::Secp256k1PublicKeyResponse {
+        public_key: PublicKey::from_secret_key(&private_key).serialize(),
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Sign the blake2b-256 digest of a message, returning the signature followed
+/// by its recovery id as used by Filecoin
+///
+/// # Arguments
+///
+/// * `raw_private_key_ptr` - pointer to a private key byte array
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+///
+/// Returns `NULL` when passed an invalid private key.
+#[no_mangle]
+pub unsafe extern "C" fn secp256k1_private_key_sign(
+    raw_private_key_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+) -> *mut types::Secp256k1SignResponse {
+    let private_key = try_ffi!(parse_private_key(raw_private_key_ptr), std::ptr::null_mut());
+    let message = message_digest(from_raw_parts(message_ptr, message_len));
+
+    let (signature, recovery_id) = sign_sig(&message, &private_key);
+
+    let mut raw_signature: Secp256k1Signature = [0; SECP256K1_SIGNATURE_BYTES];
+    raw_signature[..64].copy_from_slice(&signature.serialize());
+    raw_signature[64] = recovery_id.serialize();
+
+    let response = types::Secp256k1SignResponse {
+        signature: raw_signature,
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+/// Verify that a signature was made over a message by the given public key
+///
+/// # Arguments
+///
+/// * `signature_ptr` - pointer to a signature byte array (SECP256K1_SIGNATURE_BYTES long)
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+/// * `raw_public_key_ptr` - pointer to an uncompressed public key byte array
+///
+/// Returns 1 when the signature is valid and 0 otherwise.
+#[no_mangle]
+pub unsafe extern "C" fn secp256k1_verify(
+    signature_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+    raw_public_key_ptr: *const u8,
+) -> libc::c_int {
+    let (signature, _) = try_ffi!(parse_signature(signature_ptr), 0);
+
+    let mut raw_public_key: Secp256k1PublicKey = [0; SECP256K1_PUBLIC_KEY_BYTES];
+    raw_public_key.copy_from_slice(from_raw_parts(
+        raw_public_key_ptr,
+        SECP256K1_PUBLIC_KEY_BYTES,
+    ));
+    let public_key = try_ffi!(PublicKey::parse(&raw_public_key), 0);
+
+    let message = message_digest(from_raw_parts(message_ptr, message_len));
+
+    verify_sig(&message, &signature, &public_key) as libc::c_int
+}
+
+/// Recover the uncompressed public key which made a signature over a message
+///
+/// # Arguments
+///
+/// * `signature_ptr` - pointer to a signature byte array (SECP256K1_SIGNATURE_BYTES long)
+/// * `message_ptr` - pointer to a message byte array
+/// * `message_len` - length of the byte array
+///
+/// Returns `NULL` when no public key can be recovered.
+#[no_mangle]
+pub unsafe extern "C" fn secp256k1_recover(
+    signature_ptr: *const u8,
+    message_ptr: *const u8,
+    message_len: libc::size_t,
+) -> *mut types::Secp256k1PublicKeyResponse {
+    let (signature, recovery_id) = try_ffi!(parse_signature(signature_ptr), std::ptr::null_mut());
+    let message = message_digest(from_raw_parts(message_ptr, message_len));
+
+    let public_key = try_ffi!(
+        recover_sig(&message, &signature, &recovery_id),
+        std::ptr::null_mut()
+    );
+
+    let response = types::Secp256k1PublicKeyResponse {
+        public_key: public_key.serialize(),
+    };
+
+    Box::into_raw(Box::new(response))
+}
+
+unsafe fn parse_private_key(raw_private_key_ptr: *const u8) -> Result<SecretKey, ()> {
+    let mut raw_private_key: Secp256k1PrivateKey = [0; SECP256K1_PRIVATE_KEY_BYTES];
+    raw_private_key.copy_from_slice(from_raw_parts(
+        raw_private_key_ptr,
+        SECP256K1_PRIVATE_KEY_BYTES,
+    ));
+
+    SecretKey::parse(&raw_private_key).map_err(|_| ())
+}
+
+unsafe fn parse_signature(signature_ptr: *const u8) -> Result<(Signature, RecoveryId), ()> {
+    let raw_signature = from_raw_parts(signature_ptr, SECP256K1_SIGNATURE_BYTES);
+
+    let mut compact = [0u8; 64];
+    compact.copy_from_slice(&raw_signature[..64]);
+
+    let signature = Signature::parse(&compact);
+    let recovery_id = RecoveryId::parse(raw_signature[64]).map_err(|_| ())?;
+
+    Ok((signature, recovery_id))
+}
+
+/// Filecoin signs the blake2b-256 digest of a message rather than the message.
+fn message_digest(message: &[u8]) -> Message {
+    let mut digest = [0u8; MESSAGE_DIGEST_BYTES];
+    digest.copy_from_slice(
+        Params::new()
+            .hash_length(MESSAGE_DIGEST_BYTES)
+            .hash(message)
+            .as_bytes(),
+    );
+
+    Message::parse(&digest)
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn sign_verify_recover() {
+        unsafe {
+            let private_key = (*secp256k1_private_key_generate()).private_key;
+            let public_key = (*secp256k1_private_key_public_key(&private_key[0])).public_key;
+            let message = "hello world".as_bytes();
+            let signature =
+                (*secp256k1_private_key_sign(&private_key[0], &message[0], message.len()))
+                    .signature;
+
+            assert_eq!(
+                1,
+                secp256k1_verify(&signature[0], &message[0], message.len(), &public_key[0])
+            );
+
+            let recovered =
+                (*secp256k1_recover(&signature[0], &message[0], message.len())).public_key;
+            assert_eq!(&public_key[..], &recovered[..]);
+
+            let different_message = "bye world".as_bytes();
+            assert_eq!(
+                0,
+                secp256k1_verify(
+                    &signature[0],
+                    &different_message[0],
+                    different_message.len(),
+                    &public_key[0]
+                )
+            );
+        }
+    }
+
+    #[test]
+    fn invalid_private_key() {
+        unsafe {
+            let zero: Secp256k1PrivateKey = [0; SECP256K1_PRIVATE_KEY_BYTES];
+            assert!(secp256k1_private_key_public_key(&zero[0]).is_null());
+        }
+    }
+}
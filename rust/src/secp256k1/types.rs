@@ -0,0 +1,41 @@
+use crate::secp256k1::api::{Secp256k1PrivateKey, Secp256k1PublicKey, Secp256k1Signature};
+
+/// Secp256k1PrivateKeyGenerateResponse
+
+#[repr(C)]
+pub struct Secp256k1PrivateKeyGenerateResponse {
+    pub private_key: Secp256k1PrivateKey,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_secp256k1_private_key_generate_response(
+    ptr: *mut Secp256k1PrivateKeyGenerateResponse,
+) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// Secp256k1PublicKeyResponse
+
+#[repr(C)]
+pub struct Secp256k1PublicKeyResponse {
+    pub public_key: Secp256k1PublicKey,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_secp256k1_public_key_response(
+    ptr: *mut Secp256k1PublicKeyResponse,
+) {
+    let _ = Box::from_raw(ptr);
+}
+
+/// Secp256k1SignResponse
+
+#[repr(C)]
+pub struct Secp256k1SignResponse {
+    pub signature: Secp256k1Signature,
+}
+
+#[no_mangle]
+pub unsafe extern "C" fn destroy_secp256k1_sign_response(ptr: *mut Secp256k1SignResponse) {
+    let _ = Box::from_raw(ptr);
+}
@@ -0,0 +1,54 @@
+package ffi
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SealRandomnessPolicy bounds the epochs a sector's seal ticket and seed may
+// be drawn from, as the chain it's sealed for requires. Zero fields aren't
+// checked.
+type SealRandomnessPolicy struct {
+	// MaxTicketAge is how many epochs old a ticket may be when sealing starts
+	MaxTicketAge uint64
+
+	// MinSeedDelay is how many epochs after the ticket the seed must be drawn
+	MinSeedDelay uint64
+}
+
+// ValidateSealTicket checks a ticket before SealPreCommit spends hours on
+// it: it must be set, not from the future and, at currentEpoch, not older
+// than the policy allows.
+func ValidateSealTicket(ticket SealTicket, currentEpoch uint64, policy SealRandomnessPolicy) error {
+	if ticket.TicketBytes == [32]byte{} {
+		return errors.New("seal ticket is all zeroes, it was probably never drawn")
+	}
+
+	if ticket.BlockHeight > currentEpoch {
+		return errors.Errorf("seal ticket is from epoch %d, after the current epoch %d", ticket.BlockHeight, currentEpoch)
+	}
+
+	if policy.MaxTicketAge > 0 && currentEpoch-ticket.BlockHeight > policy.MaxTicketAge {
+		return errors.Errorf("seal ticket from epoch %d is %d epochs old, more than the %d allowed: draw a new one", ticket.BlockHeight, currentEpoch-ticket.BlockHeight, policy.MaxTicketAge)
+	}
+
+	return nil
+}
+
+// ValidateSealSeed checks a seed against the ticket the sector was
+// pre-committed with, before SealCommit: it must be set, not from the future
+// and drawn at least the policy's delay after the ticket.
+func ValidateSealSeed(ticket SealTicket, seed SealSeed, currentEpoch uint64, policy SealRandomnessPolicy) error {
+	if seed.TicketBytes == [32]byte{} {
+		return errors.New("seal seed is all zeroes, it was probably never drawn")
+	}
+
+	if seed.BlockHeight > currentEpoch {
+		return errors.Errorf("seal seed is from epoch %d, after the current epoch %d", seed.BlockHeight, currentEpoch)
+	}
+
+	if seed.BlockHeight < ticket.BlockHeight+policy.MinSeedDelay {
+		return errors.Errorf("seal seed must be drawn at epoch %d or later, %d epochs after the ticket, got epoch %d: wait and draw it again", ticket.BlockHeight+policy.MinSeedDelay, policy.MinSeedDelay, seed.BlockHeight)
+	}
+
+	return nil
+}
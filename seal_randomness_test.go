@@ -0,0 +1,31 @@
+package ffi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSealRandomness(t *testing.T) {
+	policy := SealRandomnessPolicy{MaxTicketAge: 100, MinSeedDelay: 10}
+
+	ticket := SealTicket{BlockHeight: 50, TicketBytes: [32]byte{5, 4, 2}}
+	assert.NoError(t, ValidateSealTicket(ticket, 60, policy))
+	assert.NoError(t, ValidateSealTicket(ticket, 150, policy))
+
+	// too old, from the future, or never drawn
+	assert.Error(t, ValidateSealTicket(ticket, 151, policy))
+	assert.Error(t, ValidateSealTicket(ticket, 49, policy))
+	assert.Error(t, ValidateSealTicket(SealTicket{BlockHeight: 50}, 60, policy))
+
+	// without a maximum age any past ticket will do
+	assert.NoError(t, ValidateSealTicket(ticket, 1000, SealRandomnessPolicy{}))
+
+	seed := SealSeed{BlockHeight: 60, TicketBytes: [32]byte{7, 4, 2}}
+	assert.NoError(t, ValidateSealSeed(ticket, seed, 60, policy))
+
+	// too soon after the ticket, from the future, or never drawn
+	assert.Error(t, ValidateSealSeed(ticket, SealSeed{BlockHeight: 59, TicketBytes: seed.TicketBytes}, 60, policy))
+	assert.Error(t, ValidateSealSeed(ticket, seed, 59, policy))
+	assert.Error(t, ValidateSealSeed(ticket, SealSeed{BlockHeight: 60}, 60, policy))
+}
@@ -0,0 +1,160 @@
+// Package secp256k1 provides Filecoin flavoured secp256k1 signing, verification
+// and public key recovery, backed by libfilecoin.
+//
+// As in the rest of Filecoin, signatures are made over the blake2b-256 digest
+// of a message and are encoded as the 64 byte compact signature followed by
+// the one byte recovery id.
+package secp256k1
+
+import (
+	"unsafe"
+
+	"github.com/filecoin-project/filecoin-ffi/address"
+)
+
+// #cgo LDFLAGS: ${SRCDIR}/../libfilecoin.a
+// #cgo pkg-config: ${SRCDIR}/../filecoin.pc
+// #include "../filecoin.h"
+import "C"
+
+// SignatureBytes is the length of a secp256k1 signature with its recovery id
+const SignatureBytes = 65
+
+// PrivateKeyBytes is the length of a secp256k1 private key
+const PrivateKeyBytes = 32
+
+// PublicKeyBytes is the length of an uncompressed secp256k1 public key
+const PublicKeyBytes = 65
+
+// Signature is a compact signature followed by its recovery id
+type Signature [SignatureBytes]byte
+
+// PrivateKey is a big-endian scalar
+type PrivateKey [PrivateKeyBytes]byte
+
+// PublicKey is an uncompressed curve point
+type PublicKey [PublicKeyBytes]byte
+
+// Message is a byte slice
+type Message []byte
+
+// Address returns the string form of the SECP256K1 (f1 or t1) address of the
+// public key on the given network
+func (publicKey PublicKey) Address(network address.Network) string {
+	return address.String(network, address.SECP256K1, address.SECP256K1Payload(publicKey[:]))
+}
+
+// PrivateKeyGenerate generates a private key
+func PrivateKeyGenerate() PrivateKey {
+	// call method
+	resPtr := (*C.Secp256k1PrivateKeyGenerateResponse)(unsafe.Pointer(C.secp256k1_private_key_generate()))
+	defer C.destroy_secp256k1_private_key_generate_response(resPtr)
+
+	// prep response
+	var privateKey PrivateKey
+	privateKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.private_key), PrivateKeyBytes) // nolint: staticcheck
+	copy(privateKey[:], privateKeySlice)
+
+	return privateKey
+}
+
+// PrivateKeyPublicKey gets the public key for a private key, or nil if the
+// private key is out of range
+func PrivateKeyPublicKey(privateKey PrivateKey) *PublicKey {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	// call method
+	resPtr := (*C.Secp256k1PublicKeyResponse)(unsafe.Pointer(C.secp256k1_private_key_public_key(cPrivateKeyPtr)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_secp256k1_public_key_response(resPtr)
+
+	// prep response
+	var publicKey PublicKey
+	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
+	copy(publicKey[:], publicKeySlice)
+
+	return &publicKey
+}
+
+// PrivateKeySign signs a message, or returns nil if the private key is out of
+// range
+func PrivateKeySign(privateKey PrivateKey, message Message) *Signature {
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	// call method
+	resPtr := (*C.Secp256k1SignResponse)(unsafe.Pointer(C.secp256k1_private_key_sign(cPrivateKeyPtr, cMessagePtr, cMessageLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_secp256k1_sign_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+// Verify verifies that a signature was made over a message by a public key
+func Verify(signature *Signature, message Message, publicKey PublicKey) bool {
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	cPublicKey := C.CBytes(publicKey[:])
+	defer C.free(cPublicKey)
+	cPublicKeyPtr := (*C.uchar)(cPublicKey)
+
+	// call method
+	res := (C.int)(C.secp256k1_verify(cSignaturePtr, cMessagePtr, cMessageLen, cPublicKeyPtr))
+
+	return res > 0
+}
+
+// Recover returns the public key which made a signature over a message, or
+// nil if none can be recovered
+func Recover(signature *Signature, message Message) *PublicKey {
+	// prep request
+	cSignature := C.CBytes(signature[:])
+	defer C.free(cSignature)
+	cSignaturePtr := (*C.uchar)(cSignature)
+
+	cMessage := C.CBytes(message)
+	defer C.free(cMessage)
+	cMessagePtr := (*C.uchar)(cMessage)
+	cMessageLen := C.size_t(len(message))
+
+	// call method
+	resPtr := (*C.Secp256k1PublicKeyResponse)(unsafe.Pointer(C.secp256k1_recover(cSignaturePtr, cMessagePtr, cMessageLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_secp256k1_public_key_response(resPtr)
+
+	// prep response
+	var publicKey PublicKey
+	publicKeySlice := C.GoBytes(unsafe.Pointer(&resPtr.public_key), PublicKeyBytes) // nolint: staticcheck
+	copy(publicKey[:], publicKeySlice)
+
+	return &publicKey
+}
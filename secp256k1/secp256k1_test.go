@@ -0,0 +1,59 @@
+package secp256k1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/filecoin-ffi/address"
+)
+
+func TestSigningVerificationAndRecovery(t *testing.T) {
+	// generate private keys
+	fooPrivateKey := PrivateKeyGenerate()
+	barPrivateKey := PrivateKeyGenerate()
+
+	// get the public keys for the private keys
+	fooPublicKey := PrivateKeyPublicKey(fooPrivateKey)
+	barPublicKey := PrivateKeyPublicKey(barPrivateKey)
+	assert.NotNil(t, fooPublicKey)
+	assert.NotNil(t, barPublicKey)
+
+	// make messages to sign with the keys
+	fooMessage := Message("hello foo")
+	barMessage := Message("hello bar!")
+
+	// calculate the signatures
+	fooSignature := PrivateKeySign(fooPrivateKey, fooMessage)
+	barSignature := PrivateKeySign(barPrivateKey, barMessage)
+
+	// assert the signatures verify against their own keys only
+	assert.True(t, Verify(fooSignature, fooMessage, *fooPublicKey))
+	assert.True(t, Verify(barSignature, barMessage, *barPublicKey))
+	assert.False(t, Verify(fooSignature, barMessage, *fooPublicKey))
+	assert.False(t, Verify(fooSignature, fooMessage, *barPublicKey))
+
+	// assert the signers can be recovered
+	assert.Equal(t, fooPublicKey, Recover(fooSignature, fooMessage))
+	assert.Equal(t, barPublicKey, Recover(barSignature, barMessage))
+	assert.NotEqual(t, fooPublicKey, Recover(fooSignature, barMessage))
+}
+
+func TestInvalidPrivateKey(t *testing.T) {
+	var zero PrivateKey
+
+	assert.Nil(t, PrivateKeyPublicKey(zero))
+	assert.Nil(t, PrivateKeySign(zero, Message("hello")))
+}
+
+// Test vector from go-address
+func TestAddress(t *testing.T) {
+	publicKey := PublicKey{4, 148, 2, 250, 195, 126, 100, 50, 164, 22, 163, 160, 202, 84,
+		38, 181, 24, 90, 179, 178, 79, 97, 52, 239, 162, 92, 228, 135, 200,
+		45, 46, 78, 19, 191, 69, 37, 17, 224, 210, 36, 84, 33, 248, 97, 59,
+		193, 13, 114, 250, 33, 102, 102, 169, 108, 59, 193, 57, 32, 211,
+		255, 35, 63, 208, 188, 5}
+
+	assert.Equal(t, "f15ihq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq", publicKey.Address(address.Mainnet))
+	assert.Equal(t, "t15ihq5ibzwki2b4ep2f46avlkrqzhpqgtga7pdrq", publicKey.Address(address.Testnet))
+}
@@ -0,0 +1,138 @@
+package ffi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefixes of the sector cache files which have no fixed name
+const (
+	cacheLayerPrefix = "layer-"
+	cacheTreeQPrefix = "tree-q"
+)
+
+// removableCachePrefixes are the prefixes of the sector cache files which are
+// only needed while sealing: the layer labels and the trees other than
+// tree-r-last. PoSt reads a replica through PrivateReplicaInfo, which only
+// needs tree-r-last and the aux files.
+var removableCachePrefixes = []string{cacheLayerPrefix, cachePrefix(CacheTreeC), cachePrefix(CacheTreeD), cacheTreeQPrefix}
+
+// provingCachePrefixes are the prefixes of the sector cache files PoSt can't
+// do without, the same as RequiredCacheFiles
+var provingCachePrefixes = []string{cachePrefix(CachePAux), cachePrefix(CacheTAux), cachePrefix(CacheTreeRLast)}
+
+// cachePrefix is a cache file name without its extension, so that it also
+// matches the file when stored in parts
+func cachePrefix(name string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// SectorCacheFiles lists the files in a sector's cache directory, split into
+// those PoSt needs and those which can be deleted once the sector has been
+// committed. Files it doesn't recognise are counted as required.
+func SectorCacheFiles(cacheDirPath string) (required []string, removable []string, err error) {
+	entries, err := ioutil.ReadDir(cacheDirPath)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to read sector cache")
+	}
+
+	for _, entry := range entries {
+		if isRemovableCacheFile(entry.Name()) {
+			removable = append(removable, entry.Name())
+		} else {
+			required = append(required, entry.Name())
+		}
+	}
+
+	return required, removable, nil
+}
+
+// ClearCache deletes the files in a sector's cache directory which PoSt
+// doesn't need, reclaiming the space sealing used. It must only be called
+// after SealCommit has succeeded.
+func ClearCache(cacheDirPath string) error {
+	_, removable, err := SectorCacheFiles(cacheDirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range removable {
+		if err := os.RemoveAll(filepath.Join(cacheDirPath, name)); err != nil {
+			return errors.Wrapf(err, "failed to remove %s", name)
+		}
+	}
+
+	return nil
+}
+
+// CheckProvable cheaply checks that a sector's files look fit for PoSt: the
+// sealed replica must be sectorSize bytes, and its cache must hold non-empty
+// aux files and tree-r-last. Returns an error saying what's wrong otherwise,
+// so the sector can be declared faulty ahead of proving. The files' contents
+// aren't read.
+func CheckProvable(sectorSize uint64, sector PrivateSectorInfo) error {
+	sealed, err := os.Stat(sector.SealedSectorPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to stat sealed sector")
+	}
+
+	if uint64(sealed.Size()) != sectorSize {
+		return errors.Errorf("sealed sector is %d bytes, expected %d", sealed.Size(), sectorSize)
+	}
+
+	required, _, err := SectorCacheFiles(sector.CacheDirPath)
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range provingCachePrefixes {
+		found := false
+		for _, name := range required {
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+
+			info, err := os.Stat(filepath.Join(sector.CacheDirPath, name))
+			if err != nil {
+				return errors.Wrapf(err, "failed to stat %s", name)
+			}
+			if info.Size() == 0 {
+				return errors.Errorf("cache file %s is empty", name)
+			}
+			found = true
+		}
+
+		if !found {
+			return errors.Errorf("sector cache has no %s file", prefix)
+		}
+	}
+
+	return nil
+}
+
+// FaultySectors runs CheckProvable on each sector, returning the sectors
+// which failed by ID
+func FaultySectors(sectorSize uint64, sectors []PrivateSectorInfo) map[uint64]error {
+	faults := make(map[uint64]error)
+	for _, sector := range sectors {
+		if err := CheckProvable(sectorSize, sector); err != nil {
+			faults[sector.SectorID] = err
+		}
+	}
+
+	return faults
+}
+
+func isRemovableCacheFile(name string) bool {
+	for _, prefix := range removableCachePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
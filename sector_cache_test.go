@@ -0,0 +1,83 @@
+package ffi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClearCache(t *testing.T) {
+	cacheDirPath := requireTempDirPath(t, "sector-cache")
+	defer os.RemoveAll(cacheDirPath) // nolint: errcheck
+
+	for _, name := range []string{"p_aux", "t_aux", "tree-r-last.dat", "tree-c.dat", "tree-d.dat", "layer-1.dat", "layer-2.dat", "unknown"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDirPath, name), []byte(name), 0644))
+	}
+
+	required, removable, err := SectorCacheFiles(cacheDirPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p_aux", "t_aux", "tree-r-last.dat", "unknown"}, required)
+	assert.Equal(t, []string{"layer-1.dat", "layer-2.dat", "tree-c.dat", "tree-d.dat"}, removable)
+
+	require.NoError(t, ClearCache(cacheDirPath))
+
+	entries, err := ioutil.ReadDir(cacheDirPath)
+	require.NoError(t, err)
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	sort.Strings(remaining)
+	assert.Equal(t, required, remaining)
+
+	_, _, err = SectorCacheFiles(filepath.Join(cacheDirPath, "missing"))
+	assert.Error(t, err)
+}
+
+func TestCheckProvable(t *testing.T) {
+	sectorSize := uint64(1024)
+
+	cacheDirPath := requireTempDirPath(t, "sector-cache")
+	defer os.RemoveAll(cacheDirPath) // nolint: errcheck
+
+	for _, name := range []string{"p_aux", "t_aux", "tree-r-last.dat"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDirPath, name), []byte(name), 0644))
+	}
+
+	sealedSectorPath := filepath.Join(cacheDirPath, "sealed")
+	require.NoError(t, ioutil.WriteFile(sealedSectorPath, make([]byte, sectorSize), 0644))
+
+	sector := PrivateSectorInfo{
+		SectorID:         42,
+		CacheDirPath:     cacheDirPath,
+		SealedSectorPath: sealedSectorPath,
+	}
+	assert.NoError(t, CheckProvable(sectorSize, sector))
+	assert.Empty(t, FaultySectors(sectorSize, []PrivateSectorInfo{sector}))
+
+	// a truncated replica
+	assert.Error(t, CheckProvable(2*sectorSize, sector))
+
+	// an empty tree
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDirPath, "tree-r-last.dat"), nil, 0644))
+	assert.Error(t, CheckProvable(sectorSize, sector))
+
+	// a missing aux file
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDirPath, "tree-r-last.dat"), []byte("tree"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(cacheDirPath, "p_aux")))
+	assert.Error(t, CheckProvable(sectorSize, sector))
+
+	missing := sector
+	missing.SectorID = 43
+	missing.SealedSectorPath = filepath.Join(cacheDirPath, "missing")
+
+	faults := FaultySectors(sectorSize, []PrivateSectorInfo{sector, missing})
+	assert.Equal(t, 2, len(faults))
+	assert.Error(t, faults[42])
+	assert.Error(t, faults[43])
+}
@@ -0,0 +1,74 @@
+package ffi
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/filecoin-project/filecoin-ffi/fr32"
+	"github.com/filecoin-project/filecoin-ffi/piece"
+)
+
+// Sector cache file names. The trees are binary merkle trees over the
+// sector's nodes, stored whole.
+const (
+	CachePAux      = "p_aux"
+	CacheTAux      = "t_aux"
+	CacheTreeD     = "tree-d.dat"
+	CacheTreeC     = "tree-c.dat"
+	CacheTreeRLast = "tree-r-last.dat"
+)
+
+// SectorPaths are where a sector's files live under a storage root
+type SectorPaths struct {
+	Unsealed string
+	Sealed   string
+	Cache    string
+}
+
+// SectorName names a sector's files after its miner and number, the way
+// lotus does: s-t0<miner>-<number>
+func SectorName(minerID uint64, sectorNumber uint64) string {
+	return fmt.Sprintf("s-t0%d-%d", minerID, sectorNumber)
+}
+
+// NewSectorPaths lays a sector's files out under root, in unsealed, sealed
+// and cache directories with the file, or directory, named by SectorName
+func NewSectorPaths(root string, minerID uint64, sectorNumber uint64) SectorPaths {
+	name := SectorName(minerID, sectorNumber)
+
+	return SectorPaths{
+		Unsealed: filepath.Join(root, "unsealed", name),
+		Sealed:   filepath.Join(root, "sealed", name),
+		Cache:    filepath.Join(root, "cache", name),
+	}
+}
+
+// RequiredCacheFiles lists the cache files a sector needs once committed,
+// for PoSt
+func RequiredCacheFiles() []string {
+	return []string{CachePAux, CacheTAux, CacheTreeRLast}
+}
+
+// SealingCacheFiles lists the cache files a sector has while sealing, the
+// layer labels aside
+func SealingCacheFiles() []string {
+	return []string{CachePAux, CacheTAux, CacheTreeD, CacheTreeC, CacheTreeRLast}
+}
+
+// SealedSectorSize is the size of a sector's sealed replica
+func SealedSectorSize(sectorSize uint64) uint64 {
+	return sectorSize
+}
+
+// UnsealedSectorSize is the size of a sector's unsealed data, the sector's
+// size less Fr32 padding
+func UnsealedSectorSize(sectorSize uint64) uint64 {
+	return sectorSize / fr32.PaddedChunkBytes * fr32.UnpaddedChunkBytes
+}
+
+// TreeFileSize is the size of each tree in a sector's cache: every node of
+// a binary tree with one leaf per 32 bytes of sector
+func TreeFileSize(sectorSize uint64) uint64 {
+	leaves := sectorSize / piece.NodeBytes
+	return (2*leaves - 1) * piece.NodeBytes
+}
@@ -0,0 +1,29 @@
+package ffi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSectorLayout(t *testing.T) {
+	assert.Equal(t, "s-t01000-42", SectorName(1000, 42))
+	assert.Equal(t, SectorPaths{
+		Unsealed: "/storage/unsealed/s-t01000-42",
+		Sealed:   "/storage/sealed/s-t01000-42",
+		Cache:    "/storage/cache/s-t01000-42",
+	}, NewSectorPaths("/storage", 1000, 42))
+
+	assert.Equal(t, uint64(1024), SealedSectorSize(1024))
+	assert.Equal(t, uint64(1016), UnsealedSectorSize(1024))
+	assert.Equal(t, uint64(2016), TreeFileSize(1024))
+
+	// what's required is what ClearCache keeps
+	for _, name := range RequiredCacheFiles() {
+		assert.False(t, isRemovableCacheFile(name), name)
+		assert.Contains(t, SealingCacheFiles(), name)
+	}
+	for _, name := range []string{CacheTreeD, CacheTreeC} {
+		assert.True(t, isRemovableCacheFile(name), name)
+	}
+}
@@ -0,0 +1,159 @@
+package signer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// The signer service is equivalent to
+//
+//	service Signer {
+//	  rpc PublicKey(PublicKeyRequest) returns (PublicKeyResponse);
+//	  rpc Sign(SignRequest) returns (SignResponse);
+//	}
+//
+// with messages encoded as JSON rather than protobuf, so no generated code is
+// needed on either side.
+const (
+	serviceName     = "filecoin.ffi.Signer"
+	publicKeyMethod = "/" + serviceName + "/PublicKey"
+	signMethod      = "/" + serviceName + "/Sign"
+)
+
+// codecName is the gRPC content subtype of the signer service's messages
+const codecName = "filecoin-ffi-signer-json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+type publicKeyRequest struct{}
+
+type publicKeyResponse struct {
+	PublicKey ffi.PublicKey
+}
+
+type signRequest struct {
+	Domain  string
+	Epoch   uint64
+	Message ffi.Message
+}
+
+type signResponse struct {
+	Signature ffi.Signature
+}
+
+// RegisterSignerServer serves signer's public key and signatures from server.
+// Serve a GuardedSigner to refuse conflicting signatures on the key's host.
+func RegisterSignerServer(server *grpc.Server, signer Signer) {
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*Signer)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "PublicKey", Handler: publicKeyHandler},
+			{MethodName: "Sign", Handler: signHandler},
+		},
+		Streams: []grpc.StreamDesc{},
+	}, signer)
+}
+
+func publicKeyHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req publicKeyRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		publicKey, err := srv.(Signer).PublicKey(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &publicKeyResponse{PublicKey: publicKey}, nil
+	}
+
+	if interceptor == nil {
+		return handler(ctx, &req)
+	}
+
+	return interceptor(ctx, &req, &grpc.UnaryServerInfo{Server: srv, FullMethod: publicKeyMethod}, handler)
+}
+
+func signHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	var req signRequest
+	if err := dec(&req); err != nil {
+		return nil, err
+	}
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		r := req.(*signRequest)
+		signature, err := srv.(Signer).Sign(ctx, r.Domain, r.Epoch, r.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		return &signResponse{Signature: *signature}, nil
+	}
+
+	if interceptor == nil {
+		return handler(ctx, &req)
+	}
+
+	return interceptor(ctx, &req, &grpc.UnaryServerInfo{Server: srv, FullMethod: signMethod}, handler)
+}
+
+// RemoteSigner is a Signer whose private key is held by a server registered
+// with RegisterSignerServer
+type RemoteSigner struct {
+	conn *grpc.ClientConn
+}
+
+var _ Signer = (*RemoteSigner)(nil)
+
+// NewRemoteSigner creates a RemoteSigner talking to the server at the other
+// end of conn. Securing the connection is up to the caller.
+func NewRemoteSigner(conn *grpc.ClientConn) *RemoteSigner {
+	return &RemoteSigner{conn: conn}
+}
+
+// PublicKey implements Signer
+func (s *RemoteSigner) PublicKey(ctx context.Context) (ffi.PublicKey, error) {
+	var resp publicKeyResponse
+	if err := s.conn.Invoke(ctx, publicKeyMethod, &publicKeyRequest{}, &resp, grpc.CallContentSubtype(codecName)); err != nil {
+		return ffi.PublicKey{}, errors.Wrap(err, "remote signer failed to return public key")
+	}
+
+	return resp.PublicKey, nil
+}
+
+// Sign implements Signer
+func (s *RemoteSigner) Sign(ctx context.Context, domain string, epoch uint64, message ffi.Message) (*ffi.Signature, error) {
+	req := &signRequest{Domain: domain, Epoch: epoch, Message: message}
+
+	var resp signResponse
+	if err := s.conn.Invoke(ctx, signMethod, req, &resp, grpc.CallContentSubtype(codecName)); err != nil {
+		return nil, errors.Wrap(err, "remote signer failed to sign")
+	}
+
+	return &resp.Signature, nil
+}
@@ -0,0 +1,66 @@
+package signer
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+func serveSigner(t *testing.T, signer Signer) (*RemoteSigner, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	RegisterSignerServer(server, signer)
+	go server.Serve(listener) // nolint: errcheck
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+
+	return NewRemoteSigner(conn), func() {
+		conn.Close() // nolint: errcheck
+		server.Stop()
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	local := NewLocalSigner(ffi.PrivateKeyGenerate())
+	remote, stop := serveSigner(t, local)
+	defer stop()
+	ctx := context.Background()
+
+	expectedPublicKey, err := local.PublicKey(ctx)
+	require.NoError(t, err)
+
+	publicKey, err := remote.PublicKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, expectedPublicKey, publicKey)
+
+	message := ffi.Message("hello remote world")
+	signature, err := remote.Sign(ctx, "block", 10, message)
+	require.NoError(t, err)
+	assert.True(t, ffi.Verify(signature, []ffi.Digest{ffi.Hash(message)}, []ffi.PublicKey{publicKey}))
+}
+
+func TestRemoteGuardedSigner(t *testing.T) {
+	remote, stop := serveSigner(t, NewGuardedSigner(NewLocalSigner(ffi.PrivateKeyGenerate()), NewMemoryStore()))
+	defer stop()
+	ctx := context.Background()
+
+	_, err := remote.Sign(ctx, "block", 10, ffi.Message("block at height 10"))
+	require.NoError(t, err)
+
+	// the slot travels with the request, so the server refuses a conflict
+	signature, err := remote.Sign(ctx, "block", 10, ffi.Message("another block at height 10"))
+	assert.Error(t, err)
+	assert.Nil(t, signature)
+
+	_, err = remote.Sign(ctx, "block", 11, ffi.Message("another block at height 10"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,120 @@
+package signer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// ErrConflictingSignature is returned when asked to sign a message for a slot
+// in which a different message has already been signed
+var ErrConflictingSignature = errors.New("a different message was already signed for this slot")
+
+// Slot identifies an opportunity to sign, e.g. a block at some height. A key
+// must sign at most one message per slot.
+type Slot struct {
+	PublicKey ffi.PublicKey
+	Domain    string
+	Epoch     uint64
+}
+
+// Store records the digest of the message signed in each slot. Production
+// stores must persist records before returning from Put, so that a restarted
+// signer still knows what it signed.
+type Store interface {
+	// Get returns the digest recorded for slot, if any
+	Get(slot Slot) (ffi.Digest, bool, error)
+
+	// Put records the digest of the message signed for slot
+	Put(slot Slot, digest ffi.Digest) error
+}
+
+// MemoryStore is a Store which keeps its records in memory. Records are lost
+// on restart, so it's only suitable for tests and short-lived processes.
+type MemoryStore struct {
+	lk      sync.Mutex
+	digests map[Slot]ffi.Digest
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{digests: make(map[Slot]ffi.Digest)}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(slot Slot) (ffi.Digest, bool, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	digest, ok := s.digests[slot]
+	return digest, ok, nil
+}
+
+// Put implements Store
+func (s *MemoryStore) Put(slot Slot, digest ffi.Digest) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	s.digests[slot] = digest
+	return nil
+}
+
+// GuardedSigner is a Signer wrapping another, refusing to sign two different
+// messages for the same slot. Signing the same message again is allowed.
+type GuardedSigner struct {
+	lk     sync.Mutex
+	signer Signer
+	store  Store
+}
+
+var _ Signer = (*GuardedSigner)(nil)
+
+// NewGuardedSigner creates a GuardedSigner signing with signer and recording
+// what it signs in store. The store must not be shared with other signers for
+// the same key.
+func NewGuardedSigner(signer Signer, store Store) *GuardedSigner {
+	return &GuardedSigner{
+		signer: signer,
+		store:  store,
+	}
+}
+
+// PublicKey implements Signer
+func (s *GuardedSigner) PublicKey(ctx context.Context) (ffi.PublicKey, error) {
+	return s.signer.PublicKey(ctx)
+}
+
+// Sign implements Signer. The message is recorded in the store before it is
+// signed, so a failure to record it means nothing is signed.
+func (s *GuardedSigner) Sign(ctx context.Context, domain string, epoch uint64, message ffi.Message) (*ffi.Signature, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	publicKey, err := s.signer.PublicKey(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get public key")
+	}
+
+	slot := Slot{PublicKey: publicKey, Domain: domain, Epoch: epoch}
+	digest := ffi.Hash(message)
+
+	recorded, ok, err := s.store.Get(slot)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to look up slot")
+	}
+
+	if ok && recorded != digest {
+		return nil, ErrConflictingSignature
+	}
+
+	if !ok {
+		if err := s.store.Put(slot, digest); err != nil {
+			return nil, errors.Wrap(err, "failed to record slot")
+		}
+	}
+
+	return s.signer.Sign(ctx, domain, epoch, message)
+}
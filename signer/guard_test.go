@@ -0,0 +1,64 @@
+package signer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+func TestGuardedSigner(t *testing.T) {
+	privateKey := ffi.PrivateKeyGenerate()
+	store := NewMemoryStore()
+	signer := NewGuardedSigner(NewLocalSigner(privateKey), store)
+	ctx := context.Background()
+
+	publicKey, err := signer.PublicKey(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, ffi.PrivateKeyPublicKey(privateKey), publicKey)
+
+	block := ffi.Message("block at height 10")
+	signature, err := signer.Sign(ctx, "block", 10, block)
+	require.NoError(t, err)
+	assert.True(t, ffi.Verify(signature, []ffi.Digest{ffi.Hash(block)}, []ffi.PublicKey{publicKey}))
+
+	// signing the same message again is fine
+	again, err := signer.Sign(ctx, "block", 10, block)
+	require.NoError(t, err)
+	assert.Equal(t, signature, again)
+
+	// a different message for the same slot is refused
+	_, err = signer.Sign(ctx, "block", 10, ffi.Message("another block at height 10"))
+	assert.Equal(t, ErrConflictingSignature, err)
+
+	// other slots are unaffected
+	_, err = signer.Sign(ctx, "block", 11, ffi.Message("block at height 11"))
+	assert.NoError(t, err)
+	_, err = signer.Sign(ctx, "vote", 10, ffi.Message("vote at height 10"))
+	assert.NoError(t, err)
+
+	// a new signer using the same store remembers what was signed
+	restarted := NewGuardedSigner(NewLocalSigner(privateKey), store)
+	_, err = restarted.Sign(ctx, "block", 10, ffi.Message("another block at height 10"))
+	assert.Equal(t, ErrConflictingSignature, err)
+}
+
+type failingStore struct {
+	*MemoryStore
+}
+
+func (s *failingStore) Put(Slot, ffi.Digest) error {
+	return errors.New("disk full")
+}
+
+func TestGuardedSignerStoreFailure(t *testing.T) {
+	signer := NewGuardedSigner(NewLocalSigner(ffi.PrivateKeyGenerate()), &failingStore{MemoryStore: NewMemoryStore()})
+
+	signature, err := signer.Sign(context.Background(), "block", 10, ffi.Message("block at height 10"))
+	assert.Error(t, err)
+	assert.Nil(t, signature)
+}
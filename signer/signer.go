@@ -0,0 +1,52 @@
+// Package signer signs with BLS private keys held in process or by a remote
+// host over gRPC, and guards keys against producing conflicting signatures,
+// which is slashable for block-production keys.
+package signer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+)
+
+// Signer abstracts over where a BLS private key lives, so callers can sign
+// the same way whether the key is held in process or on a remote host
+type Signer interface {
+	// PublicKey returns the public key of the signer's private key
+	PublicKey(ctx context.Context) (ffi.PublicKey, error)
+
+	// Sign signs a message for the slot at epoch in domain with the signer's
+	// private key
+	Sign(ctx context.Context, domain string, epoch uint64, message ffi.Message) (*ffi.Signature, error)
+}
+
+// LocalSigner is a Signer holding its private key in process memory. It
+// ignores the slot it's asked to sign for; wrap it in a GuardedSigner to refuse
+// conflicting signatures.
+type LocalSigner struct {
+	privateKey ffi.PrivateKey
+}
+
+var _ Signer = (*LocalSigner)(nil)
+
+// NewLocalSigner creates a LocalSigner for privateKey
+func NewLocalSigner(privateKey ffi.PrivateKey) *LocalSigner {
+	return &LocalSigner{privateKey: privateKey}
+}
+
+// PublicKey implements Signer
+func (s *LocalSigner) PublicKey(context.Context) (ffi.PublicKey, error) {
+	return ffi.PrivateKeyPublicKey(s.privateKey), nil
+}
+
+// Sign implements Signer
+func (s *LocalSigner) Sign(_ context.Context, _ string, _ uint64, message ffi.Message) (*ffi.Signature, error) {
+	signature := ffi.PrivateKeySign(s.privateKey, message)
+	if signature == nil {
+		return nil, errors.New("failed to sign message")
+	}
+
+	return signature, nil
+}
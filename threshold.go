@@ -0,0 +1,122 @@
+package ffi
+
+import (
+	"unsafe"
+)
+
+// #cgo LDFLAGS: ${SRCDIR}/libfilecoin.a
+// #cgo pkg-config: ${SRCDIR}/filecoin.pc
+// #include "./filecoin.h"
+import "C"
+
+// PrivateKeyShare is one Shamir share of a BLS private key. Index starts at
+// 1 and must be carried along with partial signatures made with the share.
+type PrivateKeyShare struct {
+	Index      uint64
+	PrivateKey PrivateKey
+}
+
+// PartialSignature is a signature made with a private key share
+type PartialSignature struct {
+	Index     uint64
+	Signature Signature
+}
+
+// SplitPrivateKey splits a private key into n shares, any k of which can
+// produce signatures for the original key through CombinePartialSignatures.
+// It returns nil if the private key is invalid, or k is 0 or larger than n.
+func SplitPrivateKey(privateKey PrivateKey, k int, n int) []PrivateKeyShare {
+	if k <= 0 || n < k {
+		return nil
+	}
+
+	// prep request
+	cPrivateKey := C.CBytes(privateKey[:])
+	defer C.free(cPrivateKey)
+	cPrivateKeyPtr := (*C.uchar)(cPrivateKey)
+
+	// call method
+	resPtr := (*C.ThresholdSplitResponse)(unsafe.Pointer(C.threshold_split_private_key(cPrivateKeyPtr, C.size_t(k), C.size_t(n))))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_threshold_split_response(resPtr)
+
+	// prep response
+	flattenedShares := C.GoBytes(unsafe.Pointer(resPtr.flattened_shares_ptr), C.int(resPtr.flattened_shares_len)) // nolint: staticcheck
+
+	shares := make([]PrivateKeyShare, n)
+	for idx := range shares {
+		shares[idx].Index = uint64(idx + 1)
+		copy(shares[idx].PrivateKey[:], flattenedShares[(PrivateKeyBytes*idx):(PrivateKeyBytes*(1+idx))])
+	}
+
+	return shares
+}
+
+// PublicKey gets the public key for a private key share, which verifies the
+// share's partial signatures
+func (share PrivateKeyShare) PublicKey() PublicKey {
+	return PrivateKeyPublicKey(share.PrivateKey)
+}
+
+// PartialSign signs a message with a private key share. It returns nil if the
+// message starts with PopDomain.
+func PartialSign(share PrivateKeyShare, message Message) *PartialSignature {
+	signature := PrivateKeySign(share.PrivateKey, message)
+	if signature == nil {
+		return nil
+	}
+
+	return &PartialSignature{Index: share.Index, Signature: *signature}
+}
+
+// CombinePartialSignatures combines partial signatures of the same message
+// into the signature of the original private key. Combining fewer partial
+// signatures than the threshold yields a signature which won't verify. It
+// returns nil if any signature is malformed or if indices are zero or repeat.
+func CombinePartialSignatures(partials []PartialSignature) *Signature {
+	// prep data
+	flattenedSignatures := make([]byte, SignatureBytes*len(partials))
+	for idx, partial := range partials {
+		copy(flattenedSignatures[(SignatureBytes*idx):(SignatureBytes*(1+idx))], partial.Signature[:])
+	}
+
+	// prep request
+	cFlattenedSignatures := C.CBytes(flattenedSignatures)
+	defer C.free(cFlattenedSignatures)
+	cFlattenedSignaturesPtr := (*C.uint8_t)(cFlattenedSignatures)
+	cFlattenedSignaturesLen := C.size_t(len(flattenedSignatures))
+
+	cIndicesPtr, cIndicesLen := cShareIndices(partials)
+	defer C.free(unsafe.Pointer(cIndicesPtr))
+
+	// call method
+	resPtr := (*C.AggregateResponse)(unsafe.Pointer(C.threshold_combine_signatures(cFlattenedSignaturesPtr, cFlattenedSignaturesLen, cIndicesPtr, cIndicesLen)))
+	if resPtr == nil {
+		return nil
+	}
+	defer C.destroy_aggregate_response(resPtr)
+
+	// prep response
+	var signature Signature
+	signatureSlice := C.GoBytes(unsafe.Pointer(&resPtr.signature), SignatureBytes) // nolint: staticcheck
+	copy(signature[:], signatureSlice)
+
+	return &signature
+}
+
+func cShareIndices(partials []PartialSignature) (*C.uint64_t, C.size_t) {
+	srcCSizeT := C.size_t(len(partials))
+
+	// allocate array in C heap
+	cIndices := C.malloc(srcCSizeT * C.sizeof_uint64_t)
+
+	// create a Go slice backed by the C-array
+	pp := (*[1 << 30]C.uint64_t)(cIndices)
+	for i, partial := range partials {
+		pp[i] = C.uint64_t(partial.Index)
+	}
+
+	return (*C.uint64_t)(cIndices), srcCSizeT
+}
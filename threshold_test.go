@@ -0,0 +1,42 @@
+package ffi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThresholdSigning(t *testing.T) {
+	privateKey := PrivateKeyGenerate()
+	publicKey := PrivateKeyPublicKey(privateKey)
+	message := Message("hello threshold")
+	digest := Hash(message)
+
+	// assert invalid thresholds are rejected
+	assert.Nil(t, SplitPrivateKey(privateKey, 0, 5))
+	assert.Nil(t, SplitPrivateKey(privateKey, 6, 5))
+
+	shares := SplitPrivateKey(privateKey, 3, 5)
+	assert.Equal(t, 5, len(shares))
+
+	// sign with an arbitrary subset of the shares
+	var partials []PartialSignature
+	for _, share := range []PrivateKeyShare{shares[4], shares[1], shares[2]} {
+		partial := PartialSign(share, message)
+		assert.True(t, Verify(&partial.Signature, []Digest{digest}, []PublicKey{share.PublicKey()}))
+
+		partials = append(partials, *partial)
+	}
+
+	// assert the combined signature verifies against the original key
+	combined := CombinePartialSignatures(partials)
+	assert.True(t, Verify(combined, []Digest{digest}, []PublicKey{publicKey}))
+	assert.Equal(t, PrivateKeySign(privateKey, message), combined)
+
+	// assert fewer shares than the threshold don't produce a valid signature
+	tooFew := CombinePartialSignatures(partials[:2])
+	assert.False(t, Verify(tooFew, []Digest{digest}, []PublicKey{publicKey}))
+
+	// assert repeated indices are rejected
+	assert.Nil(t, CombinePartialSignatures([]PartialSignature{partials[0], partials[0], partials[1]}))
+}
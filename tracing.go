@@ -0,0 +1,31 @@
+package ffi
+
+import "context"
+
+// Tracer traces the proof operations made through the *WithContext functions,
+// as spans in the trace ctx carries, so they show up in the caller's view of
+// a sealing or proving pipeline. The Rust side reports no phases of its own,
+// so each operation is a single span. Methods are called concurrently.
+type Tracer interface {
+	// StartSpan starts a span for operation, named by an Op* constant, and
+	// returns the function ending it. That is called once with the
+	// operation's error, or ctx.Err() if the operation was abandoned.
+	StartSpan(ctx context.Context, operation string) (end func(err error))
+}
+
+var currentTracer hook
+
+// SetTracer makes t trace every *WithContext operation from now on. A nil t
+// stops tracing.
+func SetTracer(t Tracer) {
+	currentTracer.Store(t)
+}
+
+func startSpan(ctx context.Context, operation string) func(err error) {
+	t, _ := currentTracer.Load().(Tracer)
+	if t == nil {
+		return func(error) {}
+	}
+
+	return t.StartSpan(ctx, operation)
+}